@@ -42,16 +42,26 @@ const (
 	OtterizeSvcAccessLabelKey                            = "intents.otterize.com/svc-access-%s"
 	OtterizeClientLabelKey                               = "intents.otterize.com/client"
 	OtterizeServerLabelKey                               = "intents.otterize.com/server"
+	OtterizeOwnerKindLabelKey                            = "intents.otterize.com/owner-kind"
+	OtterizeClusterClientIntentsLabelKey                 = "intents.otterize.com/cluster-client-intents"
 	OtterizeKubernetesServiceLabelKeyPrefix              = "intents.otterize.com/k8s-svc"
 	OtterizeKubernetesServiceLabelKey                    = "intents.otterize.com/k8s-svc-%s"
 	KubernetesStandardNamespaceNameLabelKey              = "kubernetes.io/metadata.name"
 	AllIntentsRemovedAnnotation                          = "intents.otterize.com/all-intents-removed"
 	OtterizeCreatedForServiceAnnotation                  = "intents.otterize.com/created-for-service"
 	OtterizeCreatedForIngressAnnotation                  = "intents.otterize.com/created-for-ingress"
+	OtterizeCreatedForGatewayRoutesAnnotation            = "intents.otterize.com/created-for-gateway-routes"
+	OtterizeExternalTrafficSourceCIDRsAnnotation         = "intents.otterize.com/external-traffic-source-cidrs"
+	OtterizeExternalTrafficSourceNamespacesAnnotation    = "intents.otterize.com/external-traffic-source-namespace-selector"
+	OtterizeExternalTrafficSourcePodsAnnotation          = "intents.otterize.com/external-traffic-source-pod-selector"
+	OtterizeAllowExternalTrafficKey                      = "intents.otterize.com/allow-external-traffic"
 	OtterizeNetworkPolicyNameTemplate                    = "access-to-%s-from-%s"
 	OtterizeServiceNetworkPolicyNameTemplate             = "svc-access-to-%s-from-%s"
 	OtterizeNetworkPolicy                                = "intents.otterize.com/network-policy"
 	OtterizeSvcNetworkPolicy                             = "intents.otterize.com/svc-network-policy"
+	OtterizeDefaultIntentsNetworkPolicyNameTemplate      = "default-access-to-%s-from-%s"
+	OtterizeDefaultIntentsSvcNetworkPolicyNameTemplate   = "default-svc-access-to-%s-from-%s"
+	OtterizeDefaultIntentsNetworkPolicy                  = "intents.otterize.com/default-intents-network-policy"
 	OtterizeNetworkPolicyServiceDefaultDeny              = "intents.otterize.com/network-policy-service-default-deny"
 	OtterizeNetworkPolicyExternalTraffic                 = "intents.otterize.com/network-policy-external-traffic"
 	ClientIntentsFinalizerName                           = "intents.otterize.com/client-intents-finalizer"
@@ -68,6 +78,8 @@ const (
 	EndpointsPodNamesIndexField                          = "endpointsPodNames"
 	IngressServiceNamesIndexField                        = "ingressServiceNames"
 	NetworkPoliciesByIngressNameIndexField               = "networkPoliciesByIngressName"
+	GatewayRouteServicesIndexField                       = "gatewayRouteServices"
+	NetworkPoliciesByGatewayRouteIndexField              = "networkPoliciesByGatewayRoute"
 	MaxOtterizeNameLength                                = 20
 	MaxNamespaceLength                                   = 20
 	OtterizeSvcEgressNetworkPolicyNameTemplate           = "svc-egress-to-%s-from-%s"
@@ -140,17 +152,32 @@ type IntentsSpec struct {
 
 type Service struct {
 	Name string `json:"name" yaml:"name"`
+
+	// Kind of the workload the service is resolved to, such as Deployment or CronJob, to tell apart workloads of
+	// different kinds that share a name. When omitted, workloads of any kind with the name are matched.
+	//+optional
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
 }
 
 type Intent struct {
 	Name string `json:"name" yaml:"name"`
 
+	// Kind of the workload the target server is resolved to. When omitted, workloads of any kind with the name are
+	// matched. It is ignored for Kubernetes services.
+	//+optional
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
 	//+optional
 	Type IntentType `json:"type,omitempty" yaml:"type,omitempty"`
 
 	//+optional
 	Topics []KafkaTopic `json:"kafkaTopics,omitempty" yaml:"kafkaTopics,omitempty"`
 
+	// KafkaQuotas are applied as client quotas for the client's principal on the Kafka server. They replace any quotas
+	// set on the principal by other means.
+	//+optional
+	KafkaQuotas *KafkaQuotas `json:"kafkaQuotas,omitempty" yaml:"kafkaQuotas,omitempty"`
+
 	//+optional
 	HTTPResources []HTTPResource `json:"HTTPResources,omitempty" yaml:"HTTPResources,omitempty"`
 
@@ -177,11 +204,29 @@ type KafkaTopic struct {
 	Operations []KafkaOperation `json:"operations" yaml:"operations"`
 }
 
+type KafkaQuotas struct {
+	// ProducerByteRate is the number of bytes per second the client may produce, per broker.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	ProducerByteRate int64 `json:"producerByteRate,omitempty" yaml:"producerByteRate,omitempty"`
+	// ConsumerByteRate is the number of bytes per second the client may fetch, per broker.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	ConsumerByteRate int64 `json:"consumerByteRate,omitempty" yaml:"consumerByteRate,omitempty"`
+	// RequestPercentage is the percentage of broker request handler and network thread time the client may use.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	RequestPercentage int32 `json:"requestPercentage,omitempty" yaml:"requestPercentage,omitempty"`
+}
+
 // IntentsStatus defines the observed state of ClientIntents
 type IntentsStatus struct {
 	// upToDate field reflects whether the client intents have successfully been applied
 	// to the cluster to the state specified
 	UpToDate bool `json:"upToDate,omitempty"`
+	// openCircuitBreakers lists the reconcilers that repeatedly failed for these client intents, and are
+	// being retried with backoff
+	OpenCircuitBreakers []string `json:"openCircuitBreakers,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -195,6 +240,10 @@ type ClientIntents struct {
 
 	Spec   *IntentsSpec  `json:"spec,omitempty" yaml:"spec,omitempty"`
 	Status IntentsStatus `json:"status,omitempty" yaml:"status,omitempty"`
+
+	// defaultCalls are the calls of the DefaultClientIntents of the namespace. They are never stored, but set when
+	// the client intents are read, see SetDefaultCalls.
+	defaultCalls []Intent
 }
 
 //+kubebuilder:object:root=true
@@ -214,8 +263,26 @@ func (in *ClientIntents) GetServiceName() string {
 	return in.Spec.Service.Name
 }
 
+// GetCallsList returns the calls of the client intents, followed by the default calls of their namespace that aren't
+// already made with the same type.
 func (in *ClientIntents) GetCallsList() []Intent {
-	return in.Spec.Calls
+	if len(in.defaultCalls) == 0 {
+		return in.Spec.Calls
+	}
+	calls := make([]Intent, 0, len(in.Spec.Calls)+len(in.defaultCalls))
+	calls = append(calls, in.Spec.Calls...)
+	for _, defaultCall := range in.defaultCalls {
+		if !containsCallTo(in.Spec.Calls, defaultCall) {
+			calls = append(calls, defaultCall)
+		}
+	}
+	return calls
+}
+
+// SetDefaultCalls sets the default calls of the namespace of the client intents, as merged by MergeDefaultCalls.
+// Default calls are computed whenever the client intents are read, since DefaultClientIntents may change at any time.
+func (in *ClientIntents) SetDefaultCalls(defaultCalls []Intent) {
+	in.defaultCalls = defaultCalls
 }
 
 func (in *ClientIntents) GetFilteredCallsList(intentTypes ...IntentType) []Intent {
@@ -231,8 +298,7 @@ func (in *ClientIntents) GetIntentsLabelMapping(requestNamespace string) map[str
 		if intent.Type == IntentTypeAWS || intent.Type == IntentTypeDatabase {
 			continue
 		}
-		ns := intent.GetTargetServerNamespace(requestNamespace)
-		formattedOtterizeIdentity := GetFormattedOtterizeIdentity(intent.GetTargetServerName(), ns)
+		formattedOtterizeIdentity := intent.GetFormattedTargetServerIdentity(requestNamespace)
 		labelKey := fmt.Sprintf(OtterizeAccessLabelKey, formattedOtterizeIdentity)
 		if intent.IsTargetServerKubernetesService() {
 			labelKey = fmt.Sprintf(OtterizeSvcAccessLabelKey, formattedOtterizeIdentity)
@@ -290,6 +356,27 @@ func (in *Intent) GetServerFullyQualifiedName(intentsObjNamespace string) string
 	return fullyQualifiedName
 }
 
+// GetFormattedTargetServerIdentity returns the formatted identity of the target server, which also identifies its kind
+// when the intent specifies one. Policies and access labels are named after it.
+func (in *Intent) GetFormattedTargetServerIdentity(intentsObjNamespace string) string {
+	kind := in.Kind
+	if in.IsTargetServerKubernetesService() {
+		kind = ""
+	}
+	return GetFormattedOtterizeIdentityWithKind(in.GetTargetServerName(), in.GetTargetServerNamespace(intentsObjNamespace), kind)
+}
+
+// GetTargetServerPodLabels returns the labels that select the pods of the target server.
+func (in *Intent) GetTargetServerPodLabels(intentsObjNamespace string) map[string]string {
+	podLabels := map[string]string{
+		OtterizeServerLabelKey: GetFormattedOtterizeIdentity(in.GetTargetServerName(), in.GetTargetServerNamespace(intentsObjNamespace)),
+	}
+	if in.Kind != "" && !in.IsTargetServerKubernetesService() {
+		podLabels[OtterizeOwnerKindLabelKey] = in.Kind
+	}
+	return podLabels
+}
+
 func (in *Intent) GetK8sServiceFullyQualifiedName(intentsObjNamespace string) (string, bool) {
 	fullyQualifiedName := fmt.Sprintf("%s.%s", in.GetTargetServerName(), in.GetTargetServerNamespace(intentsObjNamespace))
 	if in.IsTargetServerKubernetesService() {
@@ -543,14 +630,30 @@ func GetFormattedOtterizeIdentity(name, ns string) string {
 
 }
 
+// GetFormattedOtterizeIdentityWithKind is GetFormattedOtterizeIdentity for workloads of a specific kind. The kind is
+// only part of the hash, to keep within the length limit, so identities of workloads that share a name and namespace
+// differ by their hash. Without a kind, the identity is the same as GetFormattedOtterizeIdentity, which identities
+// created before kinds were supported are.
+func GetFormattedOtterizeIdentityWithKind(name, ns, kind string) string {
+	if kind == "" {
+		return GetFormattedOtterizeIdentity(name, ns)
+	}
+	formattedIdentity := GetFormattedOtterizeIdentity(name, ns)
+	hash := md5.Sum([]byte(fmt.Sprintf("%s-%s-%s", name, ns, kind)))
+	return formattedIdentity[:len(formattedIdentity)-6] + hex.EncodeToString(hash[:])[:6]
+}
+
 // BuildPodLabelSelector returns a label selector to match the otterize server labels for an intents resource
 func (in *ClientIntents) BuildPodLabelSelector() (labels.Selector, error) {
-	labelSelector, err := labels.Parse(
-		fmt.Sprintf("%s=%s",
-			OtterizeServerLabelKey,
-			// Since all pods are also labeled with their server identity, we can use the Otterize server label
-			// To find all pods for this specific service
-			GetFormattedOtterizeIdentity(in.Spec.Service.Name, in.Namespace)))
+	selector := fmt.Sprintf("%s=%s",
+		OtterizeServerLabelKey,
+		// Since all pods are also labeled with their server identity, we can use the Otterize server label
+		// To find all pods for this specific service
+		GetFormattedOtterizeIdentity(in.Spec.Service.Name, in.Namespace))
+	if in.Spec.Service.Kind != "" {
+		selector = fmt.Sprintf("%s,%s=%s", selector, OtterizeOwnerKindLabelKey, in.Spec.Service.Kind)
+	}
+	labelSelector, err := labels.Parse(selector)
 	if err != nil {
 		return nil, nil
 	}
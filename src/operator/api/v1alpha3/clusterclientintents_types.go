@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterClientIntentsSpec defines the desired state of ClusterClientIntents
+type ClusterClientIntentsSpec struct {
+	// NamespaceSelector selects the namespaces the client runs in. The calls apply to the client in every selected
+	// namespace, and calls to servers without a namespace refer to servers in that namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector" yaml:"namespaceSelector"`
+
+	IntentsSpec `json:",inline" yaml:",inline"`
+}
+
+// ClusterClientIntentsStatus defines the observed state of ClusterClientIntents
+type ClusterClientIntentsStatus struct {
+	// namespaces lists the namespaces ClientIntents were created in for the client
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+
+// ClusterClientIntents is the Schema for the clusterclientintents API. It declares the intents of a client that runs
+// in many namespaces, and is applied as a ClientIntents in each of them.
+type ClusterClientIntents struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	Spec   ClusterClientIntentsSpec   `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Status ClusterClientIntentsStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterClientIntentsList contains a list of ClusterClientIntents
+type ClusterClientIntentsList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Items           []ClusterClientIntents `json:"items" yaml:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterClientIntents{}, &ClusterClientIntentsList{})
+}
+
+// GetClientIntentsName returns the name of the ClientIntents the cluster client intents are applied as.
+func (in *ClusterClientIntents) GetClientIntentsName() string {
+	return fmt.Sprintf("cluster-%s", in.Name)
+}
+
+// BuildClientIntents returns the ClientIntents the cluster client intents are applied as in a namespace.
+func (in *ClusterClientIntents) BuildClientIntents(namespace string) *ClientIntents {
+	return &ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      in.GetClientIntentsName(),
+			Namespace: namespace,
+			Labels: map[string]string{
+				OtterizeClusterClientIntentsLabelKey: in.Name,
+			},
+		},
+		Spec: in.Spec.IntentsSpec.DeepCopy(),
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+)
+
+// DefaultClientIntentsSpec defines the desired state of DefaultClientIntents
+type DefaultClientIntentsSpec struct {
+	Calls []Intent `json:"calls" yaml:"calls"`
+}
+
+// DefaultClientIntentsStatus defines the observed state of DefaultClientIntents
+type DefaultClientIntentsStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+
+// DefaultClientIntents is the Schema for the defaultclientintents API. Its calls are made by every client in the
+// namespace, and are merged into the calls of each ClientIntents in it.
+type DefaultClientIntents struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	Spec   DefaultClientIntentsSpec   `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Status DefaultClientIntentsStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DefaultClientIntentsList contains a list of DefaultClientIntents
+type DefaultClientIntentsList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Items           []DefaultClientIntents `json:"items" yaml:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DefaultClientIntents{}, &DefaultClientIntentsList{})
+}
+
+// MergeDefaultCalls returns the calls of all the DefaultClientIntents of a namespace, ordered by the name of the
+// DefaultClientIntents. DefaultClientIntents that are being deleted are skipped, as are repeated calls.
+func MergeDefaultCalls(defaultIntents []DefaultClientIntents) []Intent {
+	sortedDefaultIntents := lo.Filter(defaultIntents, func(item DefaultClientIntents, _ int) bool {
+		return item.DeletionTimestamp.IsZero()
+	})
+	sort.Slice(sortedDefaultIntents, func(i, j int) bool {
+		return sortedDefaultIntents[i].Name < sortedDefaultIntents[j].Name
+	})
+
+	calls := make([]Intent, 0)
+	for _, defaults := range sortedDefaultIntents {
+		for _, call := range defaults.Spec.Calls {
+			if !containsCallTo(calls, call) {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// containsCallTo returns whether calls already has a call of the same type to the same server.
+func containsCallTo(calls []Intent, call Intent) bool {
+	return lo.ContainsBy(calls, func(item Intent) bool {
+		return item.Name == call.Name && item.Type == call.Type
+	})
+}
@@ -22,13 +22,84 @@ import (
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// TLSSource locates the client certificate, private key and root CA used to connect to a Kafka server, either as files
+// mounted into the operator or as a Secret. Rotated certificates are picked up without restarting the operator.
 type TLSSource struct {
+	// +kubebuilder:validation:Optional
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	// +kubebuilder:validation:Optional
+	KeyFile string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	// +kubebuilder:validation:Optional
+	RootCAFile string `json:"rootCAFile,omitempty" yaml:"rootCAFile,omitempty"`
+	// SecretName is a Secret in the KafkaServerConfig's namespace holding tls.crt, tls.key and ca.crt, such as the
+	// Secrets issued by cert-manager. Takes precedence over the files.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512;OAUTHBEARER
+type SASLMechanism string
+
+const (
+	SASLMechanismPlain       SASLMechanism = "PLAIN"
+	SASLMechanismSCRAMSHA256 SASLMechanism = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 SASLMechanism = "SCRAM-SHA-512"
+	SASLMechanismOAuthBearer SASLMechanism = "OAUTHBEARER"
+)
+
+// SecretKeyRef selects a key of a Secret in the KafkaServerConfig's namespace.
+type SecretKeyRef struct {
 	// +kubebuilder:validation:Required
-	CertFile string `json:"certFile" yaml:"certFile"`
+	Name string `json:"name" yaml:"name"`
 	// +kubebuilder:validation:Required
-	KeyFile string `json:"keyFile" yaml:"keyFile"`
+	Key string `json:"key" yaml:"key"`
+}
+
+type SASLConfig struct {
 	// +kubebuilder:validation:Required
-	RootCAFile string `json:"rootCAFile" yaml:"rootCAFile"`
+	Mechanism SASLMechanism `json:"mechanism" yaml:"mechanism"`
+	// Username and password are used by the PLAIN and SCRAM mechanisms.
+	// +kubebuilder:validation:Optional
+	UsernameSecretRef *SecretKeyRef `json:"usernameSecretRef,omitempty" yaml:"usernameSecretRef,omitempty"`
+	// +kubebuilder:validation:Optional
+	PasswordSecretRef *SecretKeyRef `json:"passwordSecretRef,omitempty" yaml:"passwordSecretRef,omitempty"`
+	// TokenURL, client ID and client secret are used by the OAUTHBEARER mechanism to fetch tokens using the
+	// OAuth client credentials flow.
+	// +kubebuilder:validation:Optional
+	TokenURL string `json:"tokenURL,omitempty" yaml:"tokenURL,omitempty"`
+	// +kubebuilder:validation:Optional
+	ClientIDSecretRef *SecretKeyRef `json:"clientIDSecretRef,omitempty" yaml:"clientIDSecretRef,omitempty"`
+	// +kubebuilder:validation:Optional
+	ClientSecretSecretRef *SecretKeyRef `json:"clientSecretSecretRef,omitempty" yaml:"clientSecretSecretRef,omitempty"`
+	// +kubebuilder:validation:Optional
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// Connect without TLS (SASL_PLAINTEXT). By default SASL connections use TLS, with the client certificate from
+	// the tls field if it is set, and the system root CAs otherwise.
+	// +kubebuilder:validation:Optional
+	DisableTLS bool `json:"disableTLS,omitempty" yaml:"disableTLS,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=tls;tls-external;scram-sha-512
+type StrimziAuthenticationType string
+
+const (
+	StrimziAuthenticationTLS         StrimziAuthenticationType = "tls"
+	StrimziAuthenticationTLSExternal StrimziAuthenticationType = "tls-external"
+	StrimziAuthenticationSCRAMSHA512 StrimziAuthenticationType = "scram-sha-512"
+)
+
+// StrimziConfig makes the operator manage Strimzi KafkaUser resources instead of connecting to the Kafka brokers.
+type StrimziConfig struct {
+	// ClusterName is the name of the Strimzi Kafka resource.
+	// +kubebuilder:validation:Required
+	ClusterName string `json:"clusterName" yaml:"clusterName"`
+	// Namespace of the Kafka resource, where KafkaUsers are created. Defaults to the KafkaServerConfig's namespace.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Authentication is set on KafkaUsers created by the operator. When empty, KafkaUsers only carry ACLs, for clients
+	// that authenticate by other means.
+	// +kubebuilder:validation:Optional
+	Authentication StrimziAuthenticationType `json:"authentication,omitempty" yaml:"authentication,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=literal;prefix
@@ -46,6 +117,38 @@ type TopicConfig struct {
 	IntentsRequired        bool                `json:"intentsRequired" yaml:"intentsRequired"`
 }
 
+// +kubebuilder:validation:Enum=Retain;Delete
+type TopicDeletionPolicy string
+
+const (
+	TopicDeletionPolicyRetain TopicDeletionPolicy = "Retain"
+	TopicDeletionPolicyDelete TopicDeletionPolicy = "Delete"
+)
+
+// ManagedKafkaTopic is a topic the operator creates on the Kafka server and keeps in sync with its spec.
+type ManagedKafkaTopic struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name" yaml:"name"`
+	// Partitions is the number of partitions of the topic. Partitions can be added to an existing topic but not
+	// removed. Defaults to the broker's num.partitions.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Partitions int32 `json:"partitions,omitempty" yaml:"partitions,omitempty"`
+	// ReplicationFactor is only used when the topic is created. Defaults to the broker's default.replication.factor.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ReplicationFactor int16 `json:"replicationFactor,omitempty" yaml:"replicationFactor,omitempty"`
+	// Configs are topic-level configuration overrides, such as retention.ms or cleanup.policy. Configs not listed
+	// here are left as they are on the server.
+	// +kubebuilder:validation:Optional
+	Configs map[string]string `json:"configs,omitempty" yaml:"configs,omitempty"`
+	// DeletionPolicy controls what happens to the topic when it is removed from managedTopics or when the
+	// KafkaServerConfig is deleted. Topics are only deleted with the Delete policy.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Retain
+	DeletionPolicy TopicDeletionPolicy `json:"deletionPolicy,omitempty" yaml:"deletionPolicy,omitempty"`
+}
+
 // KafkaServerConfigSpec defines the desired state of KafkaServerConfig
 type KafkaServerConfigSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -57,16 +160,100 @@ type KafkaServerConfigSpec struct {
 	NoAutoCreateIntentsForOperator bool   `json:"noAutoCreateIntentsForOperator,omitempty" yaml:"noAutoCreateIntentsForOperator,omitempty"`
 	Addr                           string `json:"addr,omitempty" yaml:"addr,omitempty"`
 	// +kubebuilder:validation:Optional
-	TLS    TLSSource     `json:"tls,omitempty" yaml:"tls,omitempty"`
-	Topics []TopicConfig `json:"topics,omitempty" yaml:"topics,omitempty"`
+	TLS TLSSource `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// SASL authenticates the operator's admin connection with SASL instead of (or in addition to) a TLS client
+	// certificate.
+	// +kubebuilder:validation:Optional
+	SASL *SASLConfig `json:"sasl,omitempty" yaml:"sasl,omitempty"`
+	// Strimzi expresses Kafka intents as Strimzi KafkaUser resources, rather than ACLs created through the brokers.
+	// addr, tls and sasl are ignored when it is set.
+	// +kubebuilder:validation:Optional
+	Strimzi *StrimziConfig `json:"strimzi,omitempty" yaml:"strimzi,omitempty"`
+	// PrincipalTemplate is the Kafka principal name given to clients, without the "User:" prefix. $ServiceName and
+	// $Namespace are replaced with the client's name and namespace. Defaults to "$ServiceName.$Namespace" with SASL,
+	// and to a distinguished name derived from the operator's certificate with TLS.
+	// +kubebuilder:validation:Optional
+	PrincipalTemplate string        `json:"principalTemplate,omitempty" yaml:"principalTemplate,omitempty"`
+	Topics            []TopicConfig `json:"topics,omitempty" yaml:"topics,omitempty"`
+	// ManagedTopics are created on the server by the operator, which also adds partitions and applies configs
+	// when they change. Not supported with the Strimzi backend, use Strimzi KafkaTopic resources instead.
+	// +kubebuilder:validation:Optional
+	ManagedTopics []ManagedKafkaTopic `json:"managedTopics,omitempty" yaml:"managedTopics,omitempty"`
+}
+
+// TopicsWithDeletePolicy returns the names of the managed topics the operator may delete.
+func (s KafkaServerConfigSpec) TopicsWithDeletePolicy() []string {
+	names := make([]string, 0)
+	for _, topic := range s.ManagedTopics {
+		if topic.DeletionPolicy == TopicDeletionPolicyDelete {
+			names = append(names, topic.Name)
+		}
+	}
+	return names
+}
+
+// KafkaACLDriftStatus reports the result of the last periodic comparison between the ACLs on the server and the
+// ACLs required by the ClientIntents in the cluster.
+type KafkaACLDriftStatus struct {
+	// LastSyncTime is the time the ACLs were last compared and repaired.
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+	// MissingACLs is the number of required ACLs that were missing from the server and recreated.
+	MissingACLs int `json:"missingACLs,omitempty"`
+	// UnexpectedACLs is the number of ACLs that no ClientIntents required and were deleted from the server.
+	UnexpectedACLs int `json:"unexpectedACLs,omitempty"`
+	// ManagedPrincipals are the principals the operator maintained ACLs for as of the last sync. The next sync deletes
+	// unexpected ACLs of these principals and of the current clients of the server only.
+	// +optional
+	ManagedPrincipals []string `json:"managedPrincipals,omitempty"`
 }
 
 // KafkaServerConfigStatus defines the observed state of KafkaServerConfig
 type KafkaServerConfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Conditions report whether the operator is connected to the server and whether the ACLs and the topic
+	// configuration were applied.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// LastSuccessfulSyncTime is the last time ACLs or topic configuration were applied to the server successfully.
+	// +optional
+	LastSuccessfulSyncTime *metav1.Time `json:"lastSuccessfulSyncTime,omitempty"`
+	// BrokerVersion is the Kafka version reported by the broker the operator is connected to.
+	// +optional
+	BrokerVersion string `json:"brokerVersion,omitempty"`
+	// ManagedACLs is the number of ACLs the operator maintains on the server.
+	// +optional
+	ManagedACLs int `json:"managedACLs,omitempty"`
+	// Principals are the principals that have ACLs on the server because of ClientIntents.
+	// +optional
+	Principals []string `json:"principals,omitempty"`
+	// LastError is the most recent error returned when applying ACLs or topic configuration to the server.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// DeletableTopics are the managed topics with the Delete policy as of the last time topics were applied. A topic
+	// that is removed from managedTopics is deleted from the server if it is listed here.
+	// +optional
+	DeletableTopics []string `json:"deletableTopics,omitempty"`
+	// ManagedQuotas maps each Kafka user the operator set client quotas for to the quota keys it set. Only these quotas
+	// are removed once the user's intents no longer request them, quotas set by other means are left alone.
+	// +optional
+	ManagedQuotas map[string][]string `json:"managedQuotas,omitempty"`
+	// +optional
+	ACLDrift *KafkaACLDriftStatus `json:"aclDrift,omitempty"`
 }
 
+const (
+	// KafkaServerConditionConnected reports whether the operator has a working admin connection to the server.
+	KafkaServerConditionConnected = "Connected"
+	// KafkaServerConditionACLsApplied reports whether the ACLs of all clients were applied.
+	KafkaServerConditionACLsApplied = "ACLsApplied"
+	// KafkaServerConditionTopicConfigApplied reports whether the topic configuration was applied.
+	KafkaServerConditionTopicConfigApplied = "TopicConfigApplied"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:storageversion
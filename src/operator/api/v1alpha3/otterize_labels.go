@@ -35,6 +35,13 @@ func UpdateOtterizeAccessLabels(pod *v1.Pod, serviceName string, otterizeAccessL
 	return pod
 }
 
+// HasOtterizeClientLabel checks whether a pod is labeled as the client-side of its intents, which is how egress
+// network policies select it.
+func HasOtterizeClientLabel(pod *v1.Pod, serviceName string) bool {
+	value, exists := pod.Labels[OtterizeClientLabelKey]
+	return exists && value == GetFormattedOtterizeIdentity(serviceName, pod.Namespace)
+}
+
 func HasOtterizeServerLabel(pod *v1.Pod, labelValue string) bool {
 	value, exists := pod.Labels[OtterizeServerLabelKey]
 	return exists && value == labelValue
@@ -22,6 +22,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -35,7 +36,7 @@ func (in *ClientIntents) DeepCopyInto(out *ClientIntents) {
 		*out = new(IntentsSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientIntents.
@@ -88,6 +89,102 @@ func (in *ClientIntentsList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterClientIntents) DeepCopyInto(out *ClusterClientIntents) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterClientIntents.
+func (in *ClusterClientIntents) DeepCopy() *ClusterClientIntents {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterClientIntents)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterClientIntents) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterClientIntentsList) DeepCopyInto(out *ClusterClientIntentsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterClientIntents, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterClientIntentsList.
+func (in *ClusterClientIntentsList) DeepCopy() *ClusterClientIntentsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterClientIntentsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterClientIntentsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterClientIntentsSpec) DeepCopyInto(out *ClusterClientIntentsSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.IntentsSpec.DeepCopyInto(&out.IntentsSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterClientIntentsSpec.
+func (in *ClusterClientIntentsSpec) DeepCopy() *ClusterClientIntentsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterClientIntentsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterClientIntentsStatus) DeepCopyInto(out *ClusterClientIntentsStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterClientIntentsStatus.
+func (in *ClusterClientIntentsStatus) DeepCopy() *ClusterClientIntentsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterClientIntentsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseResource) DeepCopyInto(out *DatabaseResource) {
 	*out = *in
@@ -108,6 +205,102 @@ func (in *DatabaseResource) DeepCopy() *DatabaseResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultClientIntents) DeepCopyInto(out *DefaultClientIntents) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultClientIntents.
+func (in *DefaultClientIntents) DeepCopy() *DefaultClientIntents {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultClientIntents)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DefaultClientIntents) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultClientIntentsList) DeepCopyInto(out *DefaultClientIntentsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DefaultClientIntents, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultClientIntentsList.
+func (in *DefaultClientIntentsList) DeepCopy() *DefaultClientIntentsList {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultClientIntentsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DefaultClientIntentsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultClientIntentsSpec) DeepCopyInto(out *DefaultClientIntentsSpec) {
+	*out = *in
+	if in.Calls != nil {
+		in, out := &in.Calls, &out.Calls
+		*out = make([]Intent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultClientIntentsSpec.
+func (in *DefaultClientIntentsSpec) DeepCopy() *DefaultClientIntentsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultClientIntentsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultClientIntentsStatus) DeepCopyInto(out *DefaultClientIntentsStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultClientIntentsStatus.
+func (in *DefaultClientIntentsStatus) DeepCopy() *DefaultClientIntentsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultClientIntentsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPResource) DeepCopyInto(out *HTTPResource) {
 	*out = *in
@@ -138,6 +331,11 @@ func (in *Intent) DeepCopyInto(out *Intent) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.KafkaQuotas != nil {
+		in, out := &in.KafkaQuotas, &out.KafkaQuotas
+		*out = new(KafkaQuotas)
+		**out = **in
+	}
 	if in.HTTPResources != nil {
 		in, out := &in.HTTPResources, &out.HTTPResources
 		*out = make([]HTTPResource, len(*in))
@@ -195,6 +393,11 @@ func (in *IntentsSpec) DeepCopy() *IntentsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntentsStatus) DeepCopyInto(out *IntentsStatus) {
 	*out = *in
+	if in.OpenCircuitBreakers != nil {
+		in, out := &in.OpenCircuitBreakers, &out.OpenCircuitBreakers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntentsStatus.
@@ -207,13 +410,49 @@ func (in *IntentsStatus) DeepCopy() *IntentsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaACLDriftStatus) DeepCopyInto(out *KafkaACLDriftStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.ManagedPrincipals != nil {
+		in, out := &in.ManagedPrincipals, &out.ManagedPrincipals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaACLDriftStatus.
+func (in *KafkaACLDriftStatus) DeepCopy() *KafkaACLDriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaACLDriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaQuotas) DeepCopyInto(out *KafkaQuotas) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaQuotas.
+func (in *KafkaQuotas) DeepCopy() *KafkaQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaServerConfig) DeepCopyInto(out *KafkaServerConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaServerConfig.
@@ -271,11 +510,28 @@ func (in *KafkaServerConfigSpec) DeepCopyInto(out *KafkaServerConfigSpec) {
 	*out = *in
 	out.Service = in.Service
 	out.TLS = in.TLS
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(SASLConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Strimzi != nil {
+		in, out := &in.Strimzi, &out.Strimzi
+		*out = new(StrimziConfig)
+		**out = **in
+	}
 	if in.Topics != nil {
 		in, out := &in.Topics, &out.Topics
 		*out = make([]TopicConfig, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManagedTopics != nil {
+		in, out := &in.ManagedTopics, &out.ManagedTopics
+		*out = make([]ManagedKafkaTopic, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaServerConfigSpec.
@@ -291,6 +547,47 @@ func (in *KafkaServerConfigSpec) DeepCopy() *KafkaServerConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaServerConfigStatus) DeepCopyInto(out *KafkaServerConfigStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSuccessfulSyncTime != nil {
+		in, out := &in.LastSuccessfulSyncTime, &out.LastSuccessfulSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Principals != nil {
+		in, out := &in.Principals, &out.Principals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletableTopics != nil {
+		in, out := &in.DeletableTopics, &out.DeletableTopics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedQuotas != nil {
+		in, out := &in.ManagedQuotas, &out.ManagedQuotas
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ACLDrift != nil {
+		in, out := &in.ACLDrift, &out.ACLDrift
+		*out = new(KafkaACLDriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaServerConfigStatus.
@@ -323,6 +620,28 @@ func (in *KafkaTopic) DeepCopy() *KafkaTopic {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedKafkaTopic) DeepCopyInto(out *ManagedKafkaTopic) {
+	*out = *in
+	if in.Configs != nil {
+		in, out := &in.Configs, &out.Configs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedKafkaTopic.
+func (in *ManagedKafkaTopic) DeepCopy() *ManagedKafkaTopic {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedKafkaTopic)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProtectedService) DeepCopyInto(out *ProtectedService) {
 	*out = *in
@@ -412,6 +731,61 @@ func (in *ProtectedServiceStatus) DeepCopy() *ProtectedServiceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SASLConfig) DeepCopyInto(out *SASLConfig) {
+	*out = *in
+	if in.UsernameSecretRef != nil {
+		in, out := &in.UsernameSecretRef, &out.UsernameSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.ClientIDSecretRef != nil {
+		in, out := &in.ClientIDSecretRef, &out.ClientIDSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.ClientSecretSecretRef != nil {
+		in, out := &in.ClientSecretSecretRef, &out.ClientSecretSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SASLConfig.
+func (in *SASLConfig) DeepCopy() *SASLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SASLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -427,6 +801,21 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrimziConfig) DeepCopyInto(out *StrimziConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrimziConfig.
+func (in *StrimziConfig) DeepCopy() *StrimziConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StrimziConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSSource) DeepCopyInto(out *TLSSource) {
 	*out = *in
@@ -0,0 +1,225 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	ReasonClientIntentsNameConflict = "ClientIntentsNameConflict"
+	ReasonClientIntentsRejected     = "ClientIntentsRejected"
+)
+
+// ClusterClientIntentsReconciler applies ClusterClientIntents as a ClientIntents in every namespace selected by them,
+// so that they are enforced by the same reconcilers as ClientIntents. The ClientIntents are owned by the
+// ClusterClientIntents, and are garbage collected with them.
+type ClusterClientIntentsReconciler struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=clusterclientintents,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=clusterclientintents/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=clusterclientintents/finalizers,verbs=update
+
+func NewClusterClientIntentsReconciler(client client.Client, scheme *runtime.Scheme) *ClusterClientIntentsReconciler {
+	return &ClusterClientIntentsReconciler{
+		Client: client,
+		scheme: scheme,
+	}
+}
+
+func (r *ClusterClientIntentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	clusterIntents := &otterizev1alpha3.ClusterClientIntents{}
+	err := r.Get(ctx, req.NamespacedName, clusterIntents)
+	if k8serrors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting cluster client intents: %w", err)
+	}
+	if !clusterIntents.DeletionTimestamp.IsZero() {
+		// The ClientIntents are deleted by the garbage collector.
+		return ctrl.Result{}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&clusterIntents.Spec.NamespaceSelector)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	var namespaces corev1.NamespaceList
+	err = r.List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed listing namespaces: %w", err)
+	}
+
+	appliedNamespaces := sets.New[string]()
+	for _, namespace := range namespaces.Items {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		applied, err := r.applyClientIntents(ctx, clusterIntents, namespace.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if applied {
+			appliedNamespaces.Insert(namespace.Name)
+		}
+	}
+
+	err = r.removeClientIntentsOfUnselectedNamespaces(ctx, clusterIntents, appliedNamespaces)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !sets.New(clusterIntents.Status.Namespaces...).Equal(appliedNamespaces) {
+		updatedClusterIntents := clusterIntents.DeepCopy()
+		updatedClusterIntents.Status.Namespaces = sets.List(appliedNamespaces)
+		err = r.Status().Patch(ctx, updatedClusterIntents, client.MergeFrom(clusterIntents))
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed updating cluster client intents status: %w", err)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// applyClientIntents creates or updates the ClientIntents of the cluster client intents in a namespace. It returns
+// false if a ClientIntents with the same name, that isn't owned by the cluster client intents, already exists, or if
+// the ClientIntents are rejected, e.g. since another ClientIntents of the namespace is for the same client. Either is
+// recorded as a warning event, so that the other namespaces are still applied.
+func (r *ClusterClientIntentsReconciler) applyClientIntents(ctx context.Context, clusterIntents *otterizev1alpha3.ClusterClientIntents, namespace string) (bool, error) {
+	newClientIntents := clusterIntents.BuildClientIntents(namespace)
+	err := controllerutil.SetControllerReference(clusterIntents, newClientIntents, r.scheme)
+	if err != nil {
+		return false, fmt.Errorf("failed setting owner of client intents: %w", err)
+	}
+
+	existingClientIntents := &otterizev1alpha3.ClientIntents{}
+	err = r.Get(ctx, types.NamespacedName{Name: newClientIntents.Name, Namespace: namespace}, existingClientIntents)
+	if k8serrors.IsNotFound(err) {
+		logrus.WithFields(logrus.Fields{"clusterClientIntents": clusterIntents.Name, "namespace": namespace}).Info("Creating client intents")
+		err = r.Create(ctx, newClientIntents)
+		if k8serrors.IsInvalid(err) || k8serrors.IsForbidden(err) {
+			r.recordWarningEventf(clusterIntents, ReasonClientIntentsRejected,
+				"ClientIntents %s/%s were rejected: %s", namespace, newClientIntents.Name, err.Error())
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed creating client intents: %w", err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed getting client intents: %w", err)
+	}
+
+	if !metav1.IsControlledBy(existingClientIntents, clusterIntents) {
+		r.recordWarningEventf(clusterIntents, ReasonClientIntentsNameConflict,
+			"ClientIntents %s/%s already exists and is not managed by these ClusterClientIntents", namespace, newClientIntents.Name)
+		return false, nil
+	}
+
+	if reflect.DeepEqual(existingClientIntents.Spec, newClientIntents.Spec) {
+		return true, nil
+	}
+	updatedClientIntents := existingClientIntents.DeepCopy()
+	updatedClientIntents.Spec = newClientIntents.Spec
+	err = r.Patch(ctx, updatedClientIntents, client.MergeFrom(existingClientIntents))
+	if err != nil {
+		return false, fmt.Errorf("failed updating client intents: %w", err)
+	}
+	return true, nil
+}
+
+func (r *ClusterClientIntentsReconciler) removeClientIntentsOfUnselectedNamespaces(ctx context.Context, clusterIntents *otterizev1alpha3.ClusterClientIntents, appliedNamespaces sets.Set[string]) error {
+	var clientIntentsList otterizev1alpha3.ClientIntentsList
+	err := r.List(ctx, &clientIntentsList, client.MatchingLabels{otterizev1alpha3.OtterizeClusterClientIntentsLabelKey: clusterIntents.Name})
+	if err != nil {
+		return fmt.Errorf("failed listing client intents: %w", err)
+	}
+
+	for _, clientIntents := range clientIntentsList.Items {
+		if appliedNamespaces.Has(clientIntents.Namespace) || !metav1.IsControlledBy(&clientIntents, clusterIntents) {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"clusterClientIntents": clusterIntents.Name, "namespace": clientIntents.Namespace}).Info("Removing client intents of unselected namespace")
+		err = r.Delete(ctx, &clientIntents)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed removing client intents: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *ClusterClientIntentsReconciler) recordWarningEventf(obj runtime.Object, reason string, message string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	r.recorder.Eventf(obj, corev1.EventTypeWarning, reason, message, args...)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterClientIntentsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&otterizev1alpha3.ClusterClientIntents{}).
+		Owns(&otterizev1alpha3.ClientIntents{}).
+		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToClusterClientIntents)).
+		Complete(r)
+	if err != nil {
+		return err
+	}
+
+	r.recorder = mgr.GetEventRecorderFor("intents-operator")
+	return nil
+}
+
+// mapNamespaceToClusterClientIntents enqueues every ClusterClientIntents when a namespace changes, since the namespace
+// may have started or stopped matching their namespace selectors.
+func (r *ClusterClientIntentsReconciler) mapNamespaceToClusterClientIntents(ctx context.Context, obj client.Object) []reconcile.Request {
+	var clusterIntentsList otterizev1alpha3.ClusterClientIntentsList
+	err := r.List(ctx, &clusterIntentsList)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed listing cluster client intents for namespace %s", obj.GetName())
+		return nil
+	}
+
+	return lo.Map(clusterIntentsList.Items, func(clusterIntents otterizev1alpha3.ClusterClientIntents, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterIntents.Name}}
+	})
+}
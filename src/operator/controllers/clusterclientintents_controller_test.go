@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/testbase"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+)
+
+type ClusterClientIntentsControllerTestSuite struct {
+	testbase.MocksSuiteBase
+	scheme     *runtime.Scheme
+	reconciler *ClusterClientIntentsReconciler
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) SetupTest() {
+	s.MocksSuiteBase.SetupTest()
+	s.scheme = runtime.NewScheme()
+	s.Require().NoError(otterizev1alpha3.AddToScheme(s.scheme))
+	s.reconciler = NewClusterClientIntentsReconciler(s.Client, s.scheme)
+	s.reconciler.recorder = s.Recorder
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) TearDownTest() {
+	s.MocksSuiteBase.TearDownTest()
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) clusterClientIntents() *otterizev1alpha3.ClusterClientIntents {
+	return &otterizev1alpha3.ClusterClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus", UID: "prometheus-uid"},
+		Spec: otterizev1alpha3.ClusterClientIntentsSpec{
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "true"}},
+			IntentsSpec: otterizev1alpha3.IntentsSpec{
+				Service: otterizev1alpha3.Service{Name: "prometheus"},
+				Calls:   []otterizev1alpha3.Intent{{Name: "node-exporter"}, {Name: "kube-state-metrics.kube-system"}},
+			},
+		},
+	}
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) ownedClientIntents(clusterIntents *otterizev1alpha3.ClusterClientIntents, namespace string) *otterizev1alpha3.ClientIntents {
+	clientIntents := clusterIntents.BuildClientIntents(namespace)
+	s.Require().NoError(controllerutil.SetControllerReference(clusterIntents, clientIntents, s.scheme))
+	return clientIntents
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) expectGetClusterClientIntents(clusterIntents *otterizev1alpha3.ClusterClientIntents) {
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: clusterIntents.Name}, gomock.AssignableToTypeOf(&otterizev1alpha3.ClusterClientIntents{})).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, obj *otterizev1alpha3.ClusterClientIntents, _ ...client.GetOption) error {
+			clusterIntents.DeepCopyInto(obj)
+			return nil
+		})
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) expectListNamespaces(names ...string) {
+	s.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&corev1.NamespaceList{}), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list *corev1.NamespaceList, _ ...client.ListOption) error {
+			for _, name := range names {
+				list.Items = append(list.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			}
+			return nil
+		})
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) expectListClientIntents(clusterIntents *otterizev1alpha3.ClusterClientIntents, items ...otterizev1alpha3.ClientIntents) {
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntentsList{}),
+		client.MatchingLabels{otterizev1alpha3.OtterizeClusterClientIntentsLabelKey: clusterIntents.Name},
+	).DoAndReturn(func(_ context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+		list.Items = items
+		return nil
+	})
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) TestClientIntentsAppliedInSelectedNamespaces() {
+	clusterIntents := s.clusterClientIntents()
+	s.expectGetClusterClientIntents(clusterIntents)
+	s.expectListNamespaces("monitoring", "tenant-a")
+
+	// The client intents are created in a newly selected namespace
+	createdClientIntents := s.ownedClientIntents(clusterIntents, "monitoring")
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "cluster-prometheus", Namespace: "monitoring"}, gomock.Any()).Return(
+		apierrors.NewNotFound(otterizev1alpha3.GroupVersion.WithResource("clientintents").GroupResource(), "cluster-prometheus"))
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(createdClientIntents)).Return(nil)
+
+	// Outdated client intents are updated
+	outdatedClientIntents := s.ownedClientIntents(clusterIntents, "tenant-a")
+	outdatedClientIntents.Spec.Calls = outdatedClientIntents.Spec.Calls[:1]
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "cluster-prometheus", Namespace: "tenant-a"}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, obj *otterizev1alpha3.ClientIntents, _ ...client.GetOption) error {
+			outdatedClientIntents.DeepCopyInto(obj)
+			return nil
+		})
+	updatedClientIntents := outdatedClientIntents.DeepCopy()
+	updatedClientIntents.Spec = clusterIntents.Spec.IntentsSpec.DeepCopy()
+	s.Client.EXPECT().Patch(gomock.Any(), gomock.Eq(updatedClientIntents), gomock.Any()).Return(nil)
+
+	// Client intents of namespaces that are no longer selected are removed
+	staleClientIntents := s.ownedClientIntents(clusterIntents, "tenant-b")
+	s.expectListClientIntents(clusterIntents, *createdClientIntents, *outdatedClientIntents, *staleClientIntents)
+	s.Client.EXPECT().Delete(gomock.Any(), gomock.Eq(staleClientIntents)).Return(nil)
+
+	statusWriter := mocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *otterizev1alpha3.ClusterClientIntents, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			s.Require().Equal([]string{"monitoring", "tenant-a"}, obj.Status.Namespaces)
+			return nil
+		})
+
+	res, err := s.reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterIntents.Name}})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) TestClientIntentsNotManagedByClusterClientIntentsAreKept() {
+	clusterIntents := s.clusterClientIntents()
+	s.expectGetClusterClientIntents(clusterIntents)
+	s.expectListNamespaces("monitoring")
+
+	userClientIntents := clusterIntents.BuildClientIntents("monitoring")
+	userClientIntents.Spec.Calls = nil
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "cluster-prometheus", Namespace: "monitoring"}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, obj *otterizev1alpha3.ClientIntents, _ ...client.GetOption) error {
+			userClientIntents.DeepCopyInto(obj)
+			return nil
+		})
+	s.expectListClientIntents(clusterIntents, *userClientIntents)
+
+	res, err := s.reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterIntents.Name}})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+	s.ExpectEvent(ReasonClientIntentsNameConflict)
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) TestRejectedClientIntentsDoNotBlockOtherNamespaces() {
+	clusterIntents := s.clusterClientIntents()
+	s.expectGetClusterClientIntents(clusterIntents)
+	s.expectListNamespaces("monitoring", "tenant-a")
+
+	// The namespace already has ClientIntents for the same client, so the webhook rejects the new ones.
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "cluster-prometheus", Namespace: "monitoring"}, gomock.Any()).Return(
+		apierrors.NewNotFound(otterizev1alpha3.GroupVersion.WithResource("clientintents").GroupResource(), "cluster-prometheus"))
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(s.ownedClientIntents(clusterIntents, "monitoring"))).Return(
+		apierrors.NewInvalid(otterizev1alpha3.GroupVersion.WithKind("ClientIntents").GroupKind(), "cluster-prometheus",
+			field.ErrorList{field.Duplicate(field.NewPath("spec").Child("service").Child("name"), "prometheus")}))
+
+	createdClientIntents := s.ownedClientIntents(clusterIntents, "tenant-a")
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "cluster-prometheus", Namespace: "tenant-a"}, gomock.Any()).Return(
+		apierrors.NewNotFound(otterizev1alpha3.GroupVersion.WithResource("clientintents").GroupResource(), "cluster-prometheus"))
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(createdClientIntents)).Return(nil)
+
+	s.expectListClientIntents(clusterIntents, *createdClientIntents)
+	statusWriter := mocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *otterizev1alpha3.ClusterClientIntents, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			s.Require().Equal([]string{"tenant-a"}, obj.Status.Namespaces)
+			return nil
+		})
+
+	res, err := s.reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterIntents.Name}})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+	s.ExpectEvent(ReasonClientIntentsRejected)
+}
+
+func (s *ClusterClientIntentsControllerTestSuite) TestNamespaceChangeEnqueuesAllClusterClientIntents() {
+	s.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ClusterClientIntentsList{})).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.ClusterClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.ClusterClientIntents{*s.clusterClientIntents()}
+			return nil
+		})
+
+	requests := s.reconciler.mapNamespaceToClusterClientIntents(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-c"}})
+	s.Require().Equal([]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "prometheus"}}}, requests)
+}
+
+func TestClusterClientIntentsControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ClusterClientIntentsControllerTestSuite))
+}
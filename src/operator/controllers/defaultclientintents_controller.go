@@ -0,0 +1,308 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/defaultintents"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sort"
+	"strings"
+)
+
+type defaultIntentsExternalNetpolHandler interface {
+	HandlePodsByLabelSelector(ctx context.Context, namespace string, labelSelector labels.Selector) error
+	HandleBeforeAccessPolicyRemoval(ctx context.Context, accessPolicy *v1.NetworkPolicy) error
+}
+
+// DefaultClientIntentsReconciler maintains the network policies that let every workload of a namespace reach the
+// servers the DefaultClientIntents of the namespace call. Default calls are made by clients with and without
+// ClientIntents alike, so these policies allow the whole client namespace, and are kept apart from the policies of
+// ClientIntents, which only cover explicit calls. Clients with ClientIntents read the default calls along with their
+// intents, see defaultintents.Client. Requests are per namespace, and only carry the namespace.
+type DefaultClientIntentsReconciler struct {
+	client.Client
+	scheme                      *runtime.Scheme
+	extNetpolHandler            defaultIntentsExternalNetpolHandler
+	restrictToNamespaces        []string
+	enableNetworkPolicyCreation bool
+	enforcementDefaultState     bool
+}
+
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=defaultclientintents,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=defaultclientintents/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=k8s.otterize.com,resources=defaultclientintents/finalizers,verbs=update
+
+func NewDefaultClientIntentsReconciler(
+	client client.Client,
+	scheme *runtime.Scheme,
+	extNetpolHandler defaultIntentsExternalNetpolHandler,
+	restrictToNamespaces []string,
+	enableNetworkPolicyCreation bool,
+	enforcementDefaultState bool,
+) *DefaultClientIntentsReconciler {
+	return &DefaultClientIntentsReconciler{
+		Client:                      client,
+		scheme:                      scheme,
+		extNetpolHandler:            extNetpolHandler,
+		restrictToNamespaces:        restrictToNamespaces,
+		enableNetworkPolicyCreation: enableNetworkPolicyCreation,
+		enforcementDefaultState:     enforcementDefaultState,
+	}
+}
+
+func (r *DefaultClientIntentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defaultCalls, err := defaultintents.CallsOfNamespace(ctx, r.Client, req.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	desiredPolicies := make(map[types.NamespacedName]*v1.NetworkPolicy)
+	for _, call := range defaultCalls {
+		policy, err := r.buildNetworkPolicy(ctx, call, req.Namespace)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed building network policy for default call to %s: %w", call.Name, err)
+		}
+		if policy != nil {
+			desiredPolicies[types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}] = policy
+		}
+	}
+
+	var existingPolicies v1.NetworkPolicyList
+	err = r.List(ctx, &existingPolicies, client.MatchingLabels{otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy: req.Namespace})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed listing network policies of default client intents: %w", err)
+	}
+
+	for _, existingPolicy := range existingPolicies.Items {
+		key := types.NamespacedName{Name: existingPolicy.Name, Namespace: existingPolicy.Namespace}
+		desiredPolicy, ok := desiredPolicies[key]
+		if !ok {
+			if err := r.removeNetworkPolicy(ctx, existingPolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+		delete(desiredPolicies, key)
+		if err := r.updateNetworkPolicy(ctx, existingPolicy, desiredPolicy); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	keys := lo.Keys(desiredPolicies)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		if err := r.createNetworkPolicy(ctx, desiredPolicies[key]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// buildNetworkPolicy returns the policy that allows the client namespace to reach the server of a default call, or
+// nil if the call shouldn't be enforced by a network policy.
+func (r *DefaultClientIntentsReconciler) buildNetworkPolicy(ctx context.Context, call otterizev1alpha3.Intent, clientNamespace string) (*v1.NetworkPolicy, error) {
+	if call.Type != "" && call.Type != otterizev1alpha3.IntentTypeHTTP && call.Type != otterizev1alpha3.IntentTypeKafka {
+		return nil, nil
+	}
+	if !r.enableNetworkPolicyCreation {
+		return nil, nil
+	}
+
+	serverName := call.GetTargetServerName()
+	serverNamespace := call.GetTargetServerNamespace(clientNamespace)
+	if len(r.restrictToNamespaces) != 0 && !lo.Contains(r.restrictToNamespaces, serverNamespace) {
+		logrus.Infof("Skipping network policy for default call of namespace %s to %s, namespace %s is not allowed by configuration", clientNamespace, call.Name, serverNamespace)
+		return nil, nil
+	}
+	shouldCreatePolicy, err := protected_services.IsServerEnforcementEnabledDueToProtectionOrDefaultState(ctx, r.Client, serverName, serverNamespace, r.enforcementDefaultState)
+	if err != nil {
+		return nil, err
+	}
+	if !shouldCreatePolicy {
+		return nil, nil
+	}
+
+	policy := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: serverNamespace,
+			Labels: map[string]string{
+				otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy: clientNamespace,
+			},
+		},
+		Spec: v1.NetworkPolicySpec{
+			PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress},
+			Ingress: []v1.NetworkPolicyIngressRule{{
+				From: []v1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: clientNamespace},
+					},
+				}},
+			}},
+		},
+	}
+
+	if !call.IsTargetServerKubernetesService() {
+		policyServerName := serverName
+		if call.Kind != "" {
+			policyServerName = fmt.Sprintf("%s.%s", serverName, strings.ToLower(call.Kind))
+		}
+		policy.Name = fmt.Sprintf(otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicyNameTemplate, policyServerName, clientNamespace)
+		policy.Labels[otterizev1alpha3.OtterizeNetworkPolicy] = call.GetFormattedTargetServerIdentity(clientNamespace)
+		policy.Spec.PodSelector = metav1.LabelSelector{MatchLabels: call.GetTargetServerPodLabels(clientNamespace)}
+		return policy, nil
+	}
+
+	svc := &corev1.Service{}
+	err = r.Get(ctx, types.NamespacedName{Name: serverName, Namespace: serverNamespace}, svc)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.Name = fmt.Sprintf(otterizev1alpha3.OtterizeDefaultIntentsSvcNetworkPolicyNameTemplate, serverName, clientNamespace)
+	policy.Labels[otterizev1alpha3.OtterizeSvcNetworkPolicy] = otterizev1alpha3.GetFormattedOtterizeIdentity(serverName, serverNamespace)
+	policy.Spec.PodSelector = metav1.LabelSelector{MatchLabels: svc.Spec.Selector}
+	policy.Spec.Ingress[0].Ports = serviceTargetPorts(svc)
+	if err := controllerutil.SetOwnerReference(svc, policy, r.scheme); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// serviceTargetPorts returns the numeric ports the service proxies to, sorted so that policies are stable.
+func serviceTargetPorts(svc *corev1.Service) []v1.NetworkPolicyPort {
+	ports := make([]v1.NetworkPolicyPort, 0)
+	for _, port := range svc.Spec.Ports {
+		if port.TargetPort.StrVal != "" {
+			continue
+		}
+		targetPort := intstr.FromInt(port.TargetPort.IntValue())
+		if lo.ContainsBy(ports, func(item v1.NetworkPolicyPort) bool { return item.Port.IntVal == targetPort.IntVal }) {
+			continue
+		}
+		networkPolicyPort := v1.NetworkPolicyPort{Port: &targetPort}
+		if len(port.Protocol) != 0 {
+			networkPolicyPort.Protocol = lo.ToPtr(port.Protocol)
+		}
+		ports = append(ports, networkPolicyPort)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port.IntVal < ports[j].Port.IntVal })
+	return ports
+}
+
+func (r *DefaultClientIntentsReconciler) createNetworkPolicy(ctx context.Context, policy *v1.NetworkPolicy) error {
+	logrus.Infof("Creating network policy %s to allow default calls of namespace %s", policy.Name, policy.Labels[otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy])
+	err := r.Create(ctx, policy)
+	if err != nil {
+		return fmt.Errorf("failed creating network policy %s: %w", policy.Name, err)
+	}
+	auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, policy)
+
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil {
+		return err
+	}
+	return r.extNetpolHandler.HandlePodsByLabelSelector(ctx, policy.Namespace, selector)
+}
+
+func (r *DefaultClientIntentsReconciler) updateNetworkPolicy(ctx context.Context, existingPolicy v1.NetworkPolicy, desiredPolicy *v1.NetworkPolicy) error {
+	if reflect.DeepEqual(existingPolicy.Spec, desiredPolicy.Spec) && reflect.DeepEqual(existingPolicy.Labels, desiredPolicy.Labels) {
+		return nil
+	}
+
+	policyCopy := existingPolicy.DeepCopy()
+	policyCopy.Labels = desiredPolicy.Labels
+	policyCopy.OwnerReferences = desiredPolicy.OwnerReferences
+	policyCopy.Spec = desiredPolicy.Spec
+	err := r.Patch(ctx, policyCopy, client.MergeFrom(&existingPolicy))
+	if err != nil {
+		return fmt.Errorf("failed updating network policy %s: %w", existingPolicy.Name, err)
+	}
+	auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, &existingPolicy, policyCopy)
+	return nil
+}
+
+func (r *DefaultClientIntentsReconciler) removeNetworkPolicy(ctx context.Context, policy v1.NetworkPolicy) error {
+	logrus.Infof("Removing network policy %s, which allowed default calls that are no longer enforced", policy.Name)
+	err := r.extNetpolHandler.HandleBeforeAccessPolicyRemoval(ctx, &policy)
+	if err != nil {
+		return err
+	}
+	err = r.Delete(ctx, &policy)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed removing network policy %s: %w", policy.Name, err)
+	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &policy)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DefaultClientIntentsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("defaultclientintents").
+		Watches(&otterizev1alpha3.DefaultClientIntents{}, handler.EnqueueRequestsFromMapFunc(mapObjectToNamespace)).
+		Watches(&otterizev1alpha3.ProtectedService{}, handler.EnqueueRequestsFromMapFunc(r.mapToNamespacesWithDefaultIntents)).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.mapToNamespacesWithDefaultIntents)).
+		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
+		Complete(r)
+}
+
+// mapObjectToNamespace enqueues the namespace of an object, since default calls are merged per namespace.
+func mapObjectToNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace()}}}
+}
+
+// mapToNamespacesWithDefaultIntents enqueues every namespace with DefaultClientIntents, since the protection of a
+// server and the ports of a service decide the policies of the default calls to them.
+func (r *DefaultClientIntentsReconciler) mapToNamespacesWithDefaultIntents(ctx context.Context, _ client.Object) []reconcile.Request {
+	var defaultIntentsList otterizev1alpha3.DefaultClientIntentsList
+	err := r.List(ctx, &defaultIntentsList)
+	if err != nil {
+		logrus.WithError(err).Error("Failed listing default client intents")
+		return nil
+	}
+
+	namespaces := lo.Uniq(lo.Map(defaultIntentsList.Items, func(item otterizev1alpha3.DefaultClientIntents, _ int) string {
+		return item.Namespace
+	}))
+	return lo.Map(namespaces, func(namespace string, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace}}
+	})
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/testbase"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+)
+
+const defaultIntentsTestNamespace = "tenant-a"
+
+type DefaultClientIntentsControllerTestSuite struct {
+	testbase.MocksSuiteBase
+	reconciler       *DefaultClientIntentsReconciler
+	extNetpolHandler *mocks.MockexternalNetpolHandler
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) SetupTest() {
+	s.MocksSuiteBase.SetupTest()
+	s.extNetpolHandler = mocks.NewMockexternalNetpolHandler(s.Controller)
+	s.reconciler = NewDefaultClientIntentsReconciler(s.Client, runtime.NewScheme(), s.extNetpolHandler, nil, true, true)
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) TearDownTest() {
+	s.MocksSuiteBase.TearDownTest()
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) expectListDefaultClientIntents(items ...otterizev1alpha3.DefaultClientIntents) {
+	s.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.DefaultClientIntentsList{}), client.InNamespace(defaultIntentsTestNamespace)).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.DefaultClientIntentsList, _ ...client.ListOption) error {
+			list.Items = items
+			return nil
+		})
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) expectListExistingPolicies(items ...v1.NetworkPolicy) {
+	s.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&v1.NetworkPolicyList{}), client.MatchingLabels{otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy: defaultIntentsTestNamespace}).DoAndReturn(
+		func(_ context.Context, list *v1.NetworkPolicyList, _ ...client.ListOption) error {
+			list.Items = items
+			return nil
+		})
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) defaultClientIntents(name string, calls ...otterizev1alpha3.Intent) otterizev1alpha3.DefaultClientIntents {
+	return otterizev1alpha3.DefaultClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultIntentsTestNamespace},
+		Spec:       otterizev1alpha3.DefaultClientIntentsSpec{Calls: calls},
+	}
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) reconcile() {
+	res, err := s.reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: defaultIntentsTestNamespace}})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) TestNamespaceWithoutClientIntentsGetsPoliciesForDefaultCalls() {
+	dns := otterizev1alpha3.Intent{Name: "kube-dns.kube-system"}
+	collector := otterizev1alpha3.Intent{Name: "otel-collector"}
+	s.expectListDefaultClientIntents(
+		s.defaultClientIntents("observability", collector, dns),
+		s.defaultClientIntents("dns", dns),
+	)
+	s.expectListExistingPolicies()
+
+	namespaceOfClients := []v1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: defaultIntentsTestNamespace},
+		},
+	}}
+	created := make([]*v1.NetworkPolicy, 0)
+	s.Client.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&v1.NetworkPolicy{})).DoAndReturn(
+		func(_ context.Context, policy *v1.NetworkPolicy, _ ...client.CreateOption) error {
+			created = append(created, policy)
+			return nil
+		}).Times(2)
+	s.extNetpolHandler.EXPECT().HandlePodsByLabelSelector(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+	s.reconcile()
+
+	s.Require().Len(created, 2)
+	s.Require().Equal("default-access-to-kube-dns-from-tenant-a", created[0].Name)
+	s.Require().Equal("kube-system", created[0].Namespace)
+	s.Require().Equal(dns.GetTargetServerPodLabels(defaultIntentsTestNamespace), created[0].Spec.PodSelector.MatchLabels)
+	s.Require().Equal(namespaceOfClients, created[0].Spec.Ingress[0].From)
+	s.Require().Equal("default-access-to-otel-collector-from-tenant-a", created[1].Name)
+	s.Require().Equal(defaultIntentsTestNamespace, created[1].Namespace)
+	s.Require().Equal(namespaceOfClients, created[1].Spec.Ingress[0].From)
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) TestStalePoliciesAreRemoved() {
+	s.expectListDefaultClientIntents()
+	stale := v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default-access-to-otel-collector-from-tenant-a",
+			Namespace: defaultIntentsTestNamespace,
+			Labels:    map[string]string{otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy: defaultIntentsTestNamespace},
+		},
+	}
+	s.expectListExistingPolicies(stale)
+
+	s.extNetpolHandler.EXPECT().HandleBeforeAccessPolicyRemoval(gomock.Any(), &stale)
+	s.Client.EXPECT().Delete(gomock.Any(), &stale)
+
+	s.reconcile()
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) TestNoPoliciesForServersThatAreNotProtected() {
+	s.reconciler.enforcementDefaultState = false
+	s.expectListDefaultClientIntents(s.defaultClientIntents("observability", otterizev1alpha3.Intent{Name: "otel-collector"}))
+	s.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ProtectedServiceList{}), gomock.Any(), gomock.Any()).Return(nil)
+	s.expectListExistingPolicies()
+
+	s.reconcile()
+}
+
+func (s *DefaultClientIntentsControllerTestSuite) TestNoPoliciesWhenNetworkPolicyCreationIsDisabled() {
+	s.reconciler.enableNetworkPolicyCreation = false
+	s.expectListDefaultClientIntents(s.defaultClientIntents("observability", otterizev1alpha3.Intent{Name: "otel-collector"}))
+	s.expectListExistingPolicies()
+
+	s.reconcile()
+}
+
+func TestDefaultClientIntentsControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(DefaultClientIntentsControllerTestSuite))
+}
@@ -0,0 +1,73 @@
+package defaultintents
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CallsOfNamespace returns the default calls of a namespace, merged from its DefaultClientIntents.
+func CallsOfNamespace(ctx context.Context, reader client.Reader, namespace string) ([]otterizev1alpha3.Intent, error) {
+	var defaultIntentsList otterizev1alpha3.DefaultClientIntentsList
+	err := reader.List(ctx, &defaultIntentsList, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed listing default client intents: %w", err)
+	}
+	return otterizev1alpha3.MergeDefaultCalls(defaultIntentsList.Items), nil
+}
+
+// Client sets the default calls of their namespace on the ClientIntents it reads, so that GetCallsList returns the
+// calls each client makes in effect. Default calls are taken from the DefaultClientIntents whenever ClientIntents are
+// read rather than stored with them, so they can't go stale or be lost.
+type Client struct {
+	client.Client
+}
+
+func NewClient(c client.Client) *Client {
+	return &Client{Client: c}
+}
+
+func (c *Client) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	err := c.Client.Get(ctx, key, obj, opts...)
+	if err != nil {
+		return err
+	}
+
+	clientIntents, ok := obj.(*otterizev1alpha3.ClientIntents)
+	if !ok {
+		return nil
+	}
+	defaultCalls, err := CallsOfNamespace(ctx, c.Client, clientIntents.Namespace)
+	if err != nil {
+		return err
+	}
+	clientIntents.SetDefaultCalls(defaultCalls)
+	return nil
+}
+
+func (c *Client) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	err := c.Client.List(ctx, list, opts...)
+	if err != nil {
+		return err
+	}
+
+	clientIntentsList, ok := list.(*otterizev1alpha3.ClientIntentsList)
+	if !ok {
+		return nil
+	}
+	defaultCallsByNamespace := make(map[string][]otterizev1alpha3.Intent)
+	for i := range clientIntentsList.Items {
+		clientIntents := &clientIntentsList.Items[i]
+		defaultCalls, ok := defaultCallsByNamespace[clientIntents.Namespace]
+		if !ok {
+			defaultCalls, err = CallsOfNamespace(ctx, c.Client, clientIntents.Namespace)
+			if err != nil {
+				return err
+			}
+			defaultCallsByNamespace[clientIntents.Namespace] = defaultCalls
+		}
+		clientIntents.SetDefaultCalls(defaultCalls)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package defaultintents
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+type DefaultIntentsClientTestSuite struct {
+	suite.Suite
+	mockClient *mocks.MockClient
+	client     *Client
+}
+
+func (s *DefaultIntentsClientTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.mockClient = mocks.NewMockClient(controller)
+	s.client = NewClient(s.mockClient)
+}
+
+func (s *DefaultIntentsClientTestSuite) expectListDefaultClientIntents(namespace string, calls ...otterizev1alpha3.Intent) {
+	s.mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.DefaultClientIntentsList{}), client.InNamespace(namespace)).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.DefaultClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.DefaultClientIntents{{
+				ObjectMeta: metav1.ObjectMeta{Name: "baseline", Namespace: namespace},
+				Spec:       otterizev1alpha3.DefaultClientIntentsSpec{Calls: calls},
+			}}
+			return nil
+		})
+}
+
+func clientIntents(name string, namespace string, calls ...otterizev1alpha3.Intent) otterizev1alpha3.ClientIntents {
+	return otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       &otterizev1alpha3.IntentsSpec{Service: otterizev1alpha3.Service{Name: name}, Calls: calls},
+	}
+}
+
+func (s *DefaultIntentsClientTestSuite) TestGetSetsDefaultCalls() {
+	dns := otterizev1alpha3.Intent{Name: "kube-dns.kube-system"}
+	collector := otterizev1alpha3.Intent{Name: "otel-collector"}
+	key := types.NamespacedName{Name: "checkout", Namespace: "tenant-a"}
+	s.mockClient.EXPECT().Get(gomock.Any(), key, gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, obj *otterizev1alpha3.ClientIntents, _ ...client.GetOption) error {
+			*obj = clientIntents("checkout", "tenant-a", dns)
+			return nil
+		})
+	s.expectListDefaultClientIntents("tenant-a", collector, dns)
+
+	intents := &otterizev1alpha3.ClientIntents{}
+	s.Require().NoError(s.client.Get(context.Background(), key, intents))
+	// The explicit call to the same server isn't repeated.
+	s.Require().Equal([]otterizev1alpha3.Intent{dns, collector}, intents.GetCallsList())
+	s.Require().Equal([]otterizev1alpha3.Intent{dns}, intents.Spec.Calls)
+}
+
+func (s *DefaultIntentsClientTestSuite) TestListSetsDefaultCallsOfEachNamespace() {
+	dns := otterizev1alpha3.Intent{Name: "kube-dns.kube-system"}
+	collector := otterizev1alpha3.Intent{Name: "otel-collector"}
+	s.mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntentsList{})).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.ClientIntents{clientIntents("checkout", "tenant-a"), clientIntents("payments", "tenant-a"), clientIntents("reports", "tenant-b")}
+			return nil
+		})
+	// Each namespace is looked up once.
+	s.expectListDefaultClientIntents("tenant-a", dns)
+	s.expectListDefaultClientIntents("tenant-b", collector)
+
+	var intentsList otterizev1alpha3.ClientIntentsList
+	s.Require().NoError(s.client.List(context.Background(), &intentsList))
+	s.Require().Equal([]otterizev1alpha3.Intent{dns}, intentsList.Items[0].GetCallsList())
+	s.Require().Equal([]otterizev1alpha3.Intent{dns}, intentsList.Items[1].GetCallsList())
+	s.Require().Equal([]otterizev1alpha3.Intent{collector}, intentsList.Items[2].GetCallsList())
+}
+
+func (s *DefaultIntentsClientTestSuite) TestOtherObjectsAreReadAsIs() {
+	s.mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Pod{})).Return(nil)
+	s.mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&corev1.PodList{})).Return(nil)
+
+	s.Require().NoError(s.client.Get(context.Background(), types.NamespacedName{Name: "pod", Namespace: "tenant-a"}, &corev1.Pod{}))
+	s.Require().NoError(s.client.List(context.Background(), &corev1.PodList{}))
+}
+
+func TestDefaultIntentsClientTestSuite(t *testing.T) {
+	suite.Run(t, new(DefaultIntentsClientTestSuite))
+}
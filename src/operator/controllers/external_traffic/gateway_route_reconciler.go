@@ -0,0 +1,123 @@
+package external_traffic
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"strings"
+)
+
+//+kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=httproutes;grpcroutes;tlsroutes,verbs=get;list;watch
+
+// GatewayRouteReconciler is the counterpart of the IngressReconciler for a single Gateway API route kind.
+type GatewayRouteReconciler struct {
+	client.Client
+	extNetpolHandler *NetworkPolicyHandler
+	kind             GatewayRouteKind
+	injectablerecorder.InjectableRecorder
+}
+
+func NewGatewayRouteReconciler(client client.Client, extNetpolHandler *NetworkPolicyHandler, kind GatewayRouteKind) *GatewayRouteReconciler {
+	return &GatewayRouteReconciler{Client: client, extNetpolHandler: extNetpolHandler, kind: kind}
+}
+
+func (r *GatewayRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	recorder := mgr.GetEventRecorderFor("intents-operator")
+	r.InjectRecorder(recorder)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.kind.newObject()).
+		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
+		Complete(r)
+}
+
+// Reconcile handles route creation, update and delete, by handling the services the route refers to, as well as the
+// services whose network policies were created for a previous version of the route.
+func (r *GatewayRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	services := sets.Set[string]{}
+
+	object := r.kind.newObject()
+	err := r.Get(ctx, req.NamespacedName, object)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	route := gatewayRoute{Kind: r.kind, Name: req.NamespacedName}
+	if err == nil {
+		route = gatewayRouteFromObject(object)
+		services = services.Union(route.services())
+	}
+
+	netpolReferencedServices, err := r.getServicesReferencedByNetworkPoliciesCreatedForRoute(ctx, route)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	services = services.Union(netpolReferencedServices)
+
+	for service := range services {
+		serviceName, ok := parseServiceKey(service)
+		if !ok {
+			continue
+		}
+		err := r.extNetpolHandler.HandleEndpointsByName(ctx, serviceName.Name, serviceName.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *GatewayRouteReconciler) getServicesReferencedByNetworkPoliciesCreatedForRoute(ctx context.Context, route gatewayRoute) (sets.Set[string], error) {
+	services := sets.Set[string]{}
+
+	// Routes may refer to services in other namespaces, so the policies created for them may be in any namespace.
+	netpolList := &v1.NetworkPolicyList{}
+	err := r.List(ctx, netpolList, &client.MatchingFields{otterizev1alpha3.NetworkPoliciesByGatewayRouteIndexField: route.key()})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, netpol := range netpolList.Items {
+		serviceName, ok := netpol.Annotations[otterizev1alpha3.OtterizeCreatedForServiceAnnotation]
+		if !ok {
+			continue
+		}
+		services.Insert(serviceKey(netpol.Namespace, serviceName))
+	}
+
+	return services, nil
+}
+
+func (r *GatewayRouteReconciler) InitGatewayRouteServicesIndex(mgr ctrl.Manager) error {
+	return mgr.GetCache().IndexField(
+		context.Background(),
+		r.kind.newObject(),
+		otterizev1alpha3.GatewayRouteServicesIndexField,
+		func(object client.Object) []string {
+			return sets.List(gatewayRouteFromObject(object).services())
+		})
+}
+
+// InitNetworkPoliciesByGatewayRouteIndex indexes network policies by the routes of all kinds they were created for,
+// so it is registered once rather than by each reconciler.
+func InitNetworkPoliciesByGatewayRouteIndex(mgr ctrl.Manager) error {
+	return mgr.GetCache().IndexField(
+		context.Background(),
+		&v1.NetworkPolicy{},
+		otterizev1alpha3.NetworkPoliciesByGatewayRouteIndexField,
+		func(object client.Object) []string {
+			netpol := object.(*v1.NetworkPolicy)
+			value, ok := netpol.Annotations[otterizev1alpha3.OtterizeCreatedForGatewayRoutesAnnotation]
+			if !ok {
+				return nil
+			}
+
+			return strings.Split(value, ",")
+		})
+}
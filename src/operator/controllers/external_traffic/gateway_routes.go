@@ -0,0 +1,198 @@
+package external_traffic
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sort"
+	"strings"
+)
+
+// GatewayNameLabelKey is set by Gateway API implementations on the data-plane pods they deploy for a Gateway.
+const GatewayNameLabelKey = "gateway.networking.k8s.io/gateway-name"
+
+// GatewayRouteKind is a Gateway API route kind whose backendRefs may expose services outside the cluster.
+type GatewayRouteKind string
+
+const (
+	GatewayRouteKindHTTPRoute GatewayRouteKind = "HTTPRoute"
+	GatewayRouteKindGRPCRoute GatewayRouteKind = "GRPCRoute"
+	GatewayRouteKindTLSRoute  GatewayRouteKind = "TLSRoute"
+)
+
+var gatewayRouteKindVersions = map[GatewayRouteKind]string{
+	GatewayRouteKindHTTPRoute: gatewayv1beta1.GroupVersion.Version,
+	GatewayRouteKindGRPCRoute: gatewayv1alpha2.GroupVersion.Version,
+	GatewayRouteKindTLSRoute:  gatewayv1alpha2.GroupVersion.Version,
+}
+
+// DetectGatewayRouteKinds returns the route kinds whose CRDs are installed in the cluster, so clusters without the
+// Gateway API (or with only some of its channels) are left unaffected.
+func DetectGatewayRouteKinds(mapper meta.RESTMapper) ([]GatewayRouteKind, error) {
+	kinds := make([]GatewayRouteKind, 0)
+	for _, kind := range []GatewayRouteKind{GatewayRouteKindHTTPRoute, GatewayRouteKindGRPCRoute, GatewayRouteKindTLSRoute} {
+		_, err := mapper.RESTMapping(schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: string(kind)}, gatewayRouteKindVersions[kind])
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %w", kind, err)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+func (k GatewayRouteKind) newObject() client.Object {
+	switch k {
+	case GatewayRouteKindHTTPRoute:
+		return &gatewayv1beta1.HTTPRoute{}
+	case GatewayRouteKindGRPCRoute:
+		return &gatewayv1alpha2.GRPCRoute{}
+	case GatewayRouteKindTLSRoute:
+		return &gatewayv1alpha2.TLSRoute{}
+	}
+	panic(fmt.Sprintf("unknown gateway route kind %s", k))
+}
+
+func (k GatewayRouteKind) newList() client.ObjectList {
+	switch k {
+	case GatewayRouteKindHTTPRoute:
+		return &gatewayv1beta1.HTTPRouteList{}
+	case GatewayRouteKindGRPCRoute:
+		return &gatewayv1alpha2.GRPCRouteList{}
+	case GatewayRouteKindTLSRoute:
+		return &gatewayv1alpha2.TLSRouteList{}
+	}
+	panic(fmt.Sprintf("unknown gateway route kind %s", k))
+}
+
+// gatewayRoute is the part of a route that matters for external traffic, independent of its kind.
+type gatewayRoute struct {
+	Kind        GatewayRouteKind
+	Name        types.NamespacedName
+	ParentRefs  []gatewayv1beta1.ParentReference
+	BackendRefs []gatewayv1beta1.BackendObjectReference
+}
+
+func gatewayRoutesFromList(list client.ObjectList) []gatewayRoute {
+	routes := make([]gatewayRoute, 0)
+	switch list := list.(type) {
+	case *gatewayv1beta1.HTTPRouteList:
+		for i := range list.Items {
+			routes = append(routes, gatewayRouteFromObject(&list.Items[i]))
+		}
+	case *gatewayv1alpha2.GRPCRouteList:
+		for i := range list.Items {
+			routes = append(routes, gatewayRouteFromObject(&list.Items[i]))
+		}
+	case *gatewayv1alpha2.TLSRouteList:
+		for i := range list.Items {
+			routes = append(routes, gatewayRouteFromObject(&list.Items[i]))
+		}
+	}
+	return routes
+}
+
+func gatewayRouteFromObject(object client.Object) gatewayRoute {
+	route := gatewayRoute{Name: types.NamespacedName{Name: object.GetName(), Namespace: object.GetNamespace()}}
+	switch object := object.(type) {
+	case *gatewayv1beta1.HTTPRoute:
+		route.Kind = GatewayRouteKindHTTPRoute
+		route.ParentRefs = object.Spec.ParentRefs
+		for _, rule := range object.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				route.BackendRefs = append(route.BackendRefs, backendRef.BackendObjectReference)
+			}
+		}
+	case *gatewayv1alpha2.GRPCRoute:
+		route.Kind = GatewayRouteKindGRPCRoute
+		route.ParentRefs = object.Spec.ParentRefs
+		for _, rule := range object.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				route.BackendRefs = append(route.BackendRefs, backendRef.BackendObjectReference)
+			}
+		}
+	case *gatewayv1alpha2.TLSRoute:
+		route.Kind = GatewayRouteKindTLSRoute
+		route.ParentRefs = object.Spec.ParentRefs
+		for _, rule := range object.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				route.BackendRefs = append(route.BackendRefs, backendRef.BackendObjectReference)
+			}
+		}
+	}
+	return route
+}
+
+// key identifies the route in the annotation of the network policies created for it.
+func (r gatewayRoute) key() string {
+	return fmt.Sprintf("%s/%s/%s", r.Kind, r.Name.Namespace, r.Name.Name)
+}
+
+// services returns the services the route sends traffic to, as "namespace/name". Backends default to the namespace
+// of the route; backends that aren't services are ignored.
+func (r gatewayRoute) services() sets.Set[string] {
+	services := sets.Set[string]{}
+	for _, backendRef := range r.BackendRefs {
+		if backendRef.Group != nil && *backendRef.Group != "" {
+			continue
+		}
+		if backendRef.Kind != nil && *backendRef.Kind != "Service" {
+			continue
+		}
+		namespace := r.Name.Namespace
+		if backendRef.Namespace != nil {
+			namespace = string(*backendRef.Namespace)
+		}
+		services.Insert(serviceKey(namespace, string(backendRef.Name)))
+	}
+	return services
+}
+
+// gateways returns the Gateways the route is attached to. Other parents, such as services of a mesh, don't expose
+// the route outside the cluster.
+func (r gatewayRoute) gateways() []types.NamespacedName {
+	gateways := make([]types.NamespacedName, 0)
+	for _, parentRef := range r.ParentRefs {
+		if parentRef.Group != nil && *parentRef.Group != gatewayv1beta1.GroupName {
+			continue
+		}
+		if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+			continue
+		}
+		namespace := r.Name.Namespace
+		if parentRef.Namespace != nil {
+			namespace = string(*parentRef.Namespace)
+		}
+		gateways = append(gateways, types.NamespacedName{Name: string(parentRef.Name), Namespace: namespace})
+	}
+	return gateways
+}
+
+func serviceKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
+
+func parseServiceKey(key string) (types.NamespacedName, bool) {
+	namespace, name, ok := strings.Cut(key, "/")
+	return types.NamespacedName{Name: name, Namespace: namespace}, ok
+}
+
+// gatewaysOfRoutes returns the Gateways the routes are attached to, sorted so that policies built from them are stable.
+func gatewaysOfRoutes(routes []gatewayRoute) []types.NamespacedName {
+	gateways := sets.Set[types.NamespacedName]{}
+	for _, route := range routes {
+		gateways.Insert(route.gateways()...)
+	}
+	sorted := gateways.UnsortedList()
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
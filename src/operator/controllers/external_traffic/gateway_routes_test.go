@@ -0,0 +1,111 @@
+package external_traffic
+
+import (
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"testing"
+)
+
+type GatewayRoutesTestSuite struct {
+	suite.Suite
+}
+
+func backendRef(name string, namespace *string) gatewayv1beta1.BackendObjectReference {
+	return gatewayv1beta1.BackendObjectReference{
+		Name:      gatewayv1beta1.ObjectName(name),
+		Namespace: (*gatewayv1beta1.Namespace)(namespace),
+	}
+}
+
+func (s *GatewayRoutesTestSuite) TestHTTPRouteServices() {
+	route := &gatewayv1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: gatewayv1beta1.HTTPRouteSpec{
+			Rules: []gatewayv1beta1.HTTPRouteRule{
+				{BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+					{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: backendRef("frontend", nil)}},
+					{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: backendRef("checkout", lo.ToPtr("payments"))}},
+				}},
+				{BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+					{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+						Group: lo.ToPtr(gatewayv1beta1.Group("example.com")),
+						Kind:  lo.ToPtr(gatewayv1beta1.Kind("Bucket")),
+						Name:  "assets",
+					}}},
+				}},
+			},
+		},
+	}
+
+	gatewayRoute := gatewayRouteFromObject(route)
+	s.Require().Equal("HTTPRoute/shop/web", gatewayRoute.key())
+	s.Require().Equal(sets.New("shop/frontend", "payments/checkout"), gatewayRoute.services())
+}
+
+func (s *GatewayRoutesTestSuite) TestTLSRouteGateways() {
+	route := &gatewayv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+		Spec: gatewayv1alpha2.TLSRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{
+				{Name: "public"},
+				{Name: "shared", Namespace: lo.ToPtr(gatewayv1beta1.Namespace("gateways"))},
+				// Routes attached to a service are handled by a mesh, and aren't exposed outside the cluster.
+				{Name: "frontend", Group: lo.ToPtr(gatewayv1beta1.Group("")), Kind: lo.ToPtr(gatewayv1beta1.Kind("Service"))},
+			}},
+			Rules: []gatewayv1alpha2.TLSRouteRule{{BackendRefs: []gatewayv1alpha2.BackendRef{{BackendObjectReference: backendRef("db", nil)}}}},
+		},
+	}
+
+	gatewayRoute := gatewayRouteFromObject(route)
+	s.Require().Equal(GatewayRouteKindTLSRoute, gatewayRoute.Kind)
+	s.Require().Equal(sets.New("shop/db"), gatewayRoute.services())
+	s.Require().Equal([]types.NamespacedName{{Name: "public", Namespace: "shop"}, {Name: "shared", Namespace: "gateways"}}, gatewayRoute.gateways())
+}
+
+func (s *GatewayRoutesTestSuite) TestGatewayRouteSources() {
+	handler := NewNetworkPolicyHandler(nil, nil, "", IngressControllerSelector{}, nil, GatewayDataPlaneSelector{})
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+	routes := []gatewayRoute{
+		{Kind: GatewayRouteKindHTTPRoute, Name: types.NamespacedName{Name: "web", Namespace: "shop"}, ParentRefs: []gatewayv1beta1.ParentReference{{Name: "public"}}},
+		{Kind: GatewayRouteKindGRPCRoute, Name: types.NamespacedName{Name: "api", Namespace: "shop"}, ParentRefs: []gatewayv1beta1.ParentReference{{Name: "public"}}},
+	}
+
+	sources, err := handler.allowedSources(svc, ingressList(), routes)
+	s.Require().NoError(err)
+	s.Require().Equal([]v1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "shop"}},
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{GatewayNameLabelKey: "public"}},
+	}}, sources)
+
+	endpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	netpol := buildNetworkPolicyObjectForEndpoints(endpoints, "web-shop", metav1.LabelSelector{}, ingressList(), routes, sources, "external-access-to-web")
+	s.Require().Equal("HTTPRoute/shop/web,GRPCRoute/shop/api", netpol.Annotations[otterizev1alpha3.OtterizeCreatedForGatewayRoutesAnnotation])
+	s.Require().NotContains(netpol.Annotations, otterizev1alpha3.OtterizeCreatedForIngressAnnotation)
+}
+
+func (s *GatewayRoutesTestSuite) TestGatewayRouteSourcesWithSharedDataPlane() {
+	handler := NewNetworkPolicyHandler(nil, nil, "", IngressControllerSelector{}, nil, GatewayDataPlaneSelector{Namespace: "istio-ingress", NameLabelKey: "istio.io/gateway-name"})
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+	routes := []gatewayRoute{
+		{Kind: GatewayRouteKindHTTPRoute, Name: types.NamespacedName{Name: "web", Namespace: "shop"}, ParentRefs: []gatewayv1beta1.ParentReference{{Name: "public"}}},
+	}
+
+	sources, err := handler.allowedSources(svc, ingressList(), routes)
+	s.Require().NoError(err)
+	s.Require().Equal([]v1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "istio-ingress"}},
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"istio.io/gateway-name": "public"}},
+	}}, sources)
+}
+
+func TestGatewayRoutesTestSuite(t *testing.T) {
+	suite.Run(t, new(GatewayRoutesTestSuite))
+}
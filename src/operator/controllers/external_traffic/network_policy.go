@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/otterize/intents-operator/src/operator/api/v1alpha2"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
 	"github.com/samber/lo"
@@ -20,6 +22,7 @@ import (
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
 	"strings"
 )
 
@@ -31,6 +34,8 @@ const (
 	ReasonRemovingExternalTrafficPolicy       = "RemovingExternalTrafficPolicy"
 	ReasonRemovingExternalTrafficPolicyFailed = "RemovingExternalTrafficPolicyFailed"
 	ReasonRemovedExternalTrafficPolicy        = "RemovedExternalTrafficPolicy"
+	ReasonInvalidExternalTrafficSources       = "InvalidExternalTrafficSources"
+	ReasonInvalidAllowExternalTraffic         = "InvalidAllowExternalTraffic"
 	OtterizeExternalNetworkPolicyNameTemplate = "external-access-to-%s"
 	successMsgNetpolCreate                    = "created external traffic network policy. service '%s' refers to pods protected by network policy '%s'"
 )
@@ -39,22 +44,40 @@ type NetworkPolicyHandler struct {
 	client client.Client
 	scheme *runtime.Scheme
 	injectablerecorder.InjectableRecorder
-	allowExternalTraffic allowexternaltraffic.Enum
+	allowExternalTraffic      allowexternaltraffic.Enum
+	ingressControllerSelector IngressControllerSelector
+	gatewayRouteKinds         []GatewayRouteKind
+	gatewayDataPlaneSelector  GatewayDataPlaneSelector
 }
 
 func NewNetworkPolicyHandler(
 	client client.Client,
 	scheme *runtime.Scheme,
 	allowExternalTraffic allowexternaltraffic.Enum,
+	ingressControllerSelector IngressControllerSelector,
+	gatewayRouteKinds []GatewayRouteKind,
+	gatewayDataPlaneSelector GatewayDataPlaneSelector,
 ) *NetworkPolicyHandler {
-	return &NetworkPolicyHandler{client: client, scheme: scheme, allowExternalTraffic: allowExternalTraffic}
+	return &NetworkPolicyHandler{
+		client:                    client,
+		scheme:                    scheme,
+		allowExternalTraffic:      allowExternalTraffic,
+		ingressControllerSelector: ingressControllerSelector,
+		gatewayRouteKinds:         gatewayRouteKinds,
+		gatewayDataPlaneSelector:  gatewayDataPlaneSelector,
+	}
 }
 
 func (r *NetworkPolicyHandler) createOrUpdateNetworkPolicy(
-	ctx context.Context, endpoints *corev1.Endpoints, owner *corev1.Service, otterizeServiceName string, selector metav1.LabelSelector, ingressList *v1.IngressList, successMsg string) error {
+	ctx context.Context, endpoints *corev1.Endpoints, owner *corev1.Service, otterizeServiceName string, selector metav1.LabelSelector, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute, successMsg string) error {
 	policyName := r.formatPolicyName(endpoints.Name)
-	newPolicy := buildNetworkPolicyObjectForEndpoints(endpoints, otterizeServiceName, selector, ingressList, policyName)
-	err := controllerutil.SetOwnerReference(owner, newPolicy, r.scheme)
+	sources, err := r.allowedSources(owner, ingressList, gatewayRoutes)
+	if err != nil {
+		r.RecordWarningEventf(owner, ReasonInvalidExternalTrafficSources, "failed to determine the allowed sources of external traffic: %s", err.Error())
+		return err
+	}
+	newPolicy := buildNetworkPolicyObjectForEndpoints(endpoints, otterizeServiceName, selector, ingressList, gatewayRoutes, sources, policyName)
+	err = controllerutil.SetOwnerReference(owner, newPolicy, r.scheme)
 	if err != nil {
 		return err
 	}
@@ -69,6 +92,7 @@ func (r *NetworkPolicyHandler) createOrUpdateNetworkPolicy(
 			r.RecordWarningEventf(owner, ReasonCreatingExternalTrafficPolicyFailed, "failed to create external traffic network policy: %s", err.Error())
 			return err
 		}
+		auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, newPolicy)
 		r.RecordNormalEvent(owner, ReasonCreatedExternalTrafficPolicy, successMsg)
 		return nil
 	} else if errGetExistingPolicy != nil {
@@ -99,6 +123,7 @@ func (r *NetworkPolicyHandler) updatePolicy(ctx context.Context, existingPolicy
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, existingPolicy, policyCopy)
 	return nil
 }
 
@@ -110,7 +135,7 @@ func (r *NetworkPolicyHandler) arePoliciesEqual(existingPolicy *v1.NetworkPolicy
 }
 
 func buildNetworkPolicyObjectForEndpoints(
-	endpoints *corev1.Endpoints, otterizeServiceName string, selector metav1.LabelSelector, ingressList *v1.IngressList, policyName string) *v1.NetworkPolicy {
+	endpoints *corev1.Endpoints, otterizeServiceName string, selector metav1.LabelSelector, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute, sources []v1.NetworkPolicyPeer, policyName string) *v1.NetworkPolicy {
 	serviceSpecCopy := endpoints.Subsets
 
 	annotations := map[string]string{
@@ -123,6 +148,12 @@ func buildNetworkPolicyObjectForEndpoints(
 		}), ",")
 	}
 
+	if len(gatewayRoutes) != 0 {
+		annotations[otterizev1alpha3.OtterizeCreatedForGatewayRoutesAnnotation] = strings.Join(lo.Map(gatewayRoutes, func(route gatewayRoute, _ int) string {
+			return route.key()
+		}), ",")
+	}
+
 	netpol := &v1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      policyName,
@@ -136,7 +167,7 @@ func buildNetworkPolicyObjectForEndpoints(
 			PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress},
 			PodSelector: selector,
 			Ingress: []v1.NetworkPolicyIngressRule{
-				{},
+				{From: sources},
 			},
 		},
 	}
@@ -161,11 +192,6 @@ func buildNetworkPolicyObjectForEndpoints(
 //
 //	that related external policies will be removed as well (if needed)
 func (r *NetworkPolicyHandler) HandleBeforeAccessPolicyRemoval(ctx context.Context, accessPolicy *v1.NetworkPolicy) error {
-	// if allowExternalTraffic is Always - external policies are not dependent on access policies
-	if r.allowExternalTraffic == allowexternaltraffic.Always {
-		return nil
-	}
-
 	nonExternalPolicyList := &v1.NetworkPolicyList{}
 	serviceNameLabel := accessPolicy.Labels[v1alpha2.OtterizeNetworkPolicy]
 
@@ -188,10 +214,19 @@ func (r *NetworkPolicyHandler) HandleBeforeAccessPolicyRemoval(ctx context.Conte
 	}
 
 	for _, externalPolicy := range externalPolicyList.Items {
-		err := r.client.Delete(ctx, externalPolicy.DeepCopy())
+		allowExternalTraffic, err := r.allowExternalTrafficForPolicy(ctx, &externalPolicy)
 		if err != nil {
 			return err
 		}
+		// if allowExternalTraffic is Always - external policies are not dependent on access policies
+		if allowExternalTraffic == allowexternaltraffic.Always {
+			continue
+		}
+		err = r.client.Delete(ctx, externalPolicy.DeepCopy())
+		if err != nil {
+			return err
+		}
+		auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &externalPolicy)
 	}
 	return nil
 }
@@ -287,14 +322,15 @@ func (r *NetworkPolicyHandler) handlePod(ctx context.Context, pod *corev1.Pod) e
 // HandleEndpoints
 // Every HandleX function goes through this function, and it handles this cases:
 // (1) Endpoints reconciler watch endpoints and call HandleEndpoints, which means it gets updates when Services are updated, or the pods backing them are updated.
-// (2) It receives handle requests from the IngressReconciler, when Ingresses are created, updated or deleted.
+// (2) It receives handle requests from the IngressReconciler and the GatewayRouteReconcilers, when Ingresses or Gateway API routes are created, updated or deleted.
 // (3) It receives handle requests from the Intents NetworkPolicyReconciler, when Network Policies that apply intents
 //
 //	are created, updated or deleted. This means that if you create, update or delete intents, the corresponding
 //	external traffic policy will be created (if there were no other intents affecting the service before then) or
 //	deleted (if no intents network policies refer to the pods backing the service any longer).
 //
-//	 When HandleEndpoints is called, and the Service is of type LoadBalancer, NodePort, or is referenced by an Ingress,
+//	 When HandleEndpoints is called, and the Service is of type LoadBalancer, NodePort, or is referenced by an Ingress
+//	 or by a route attached to a Gateway,
 //		   it checks if the backing pods are affected by Otterize Intents Network Policies.
 //		   If so, and the reconciler is enabled, it will create network policies to allow external traffic to those pods.
 //		   If the Endpoints (= Services) update port, it will update the port specified in the corresponding network policy.
@@ -317,16 +353,25 @@ func (r *NetworkPolicyHandler) HandleEndpoints(ctx context.Context, endpoints *c
 	if err != nil {
 		return err
 	}
-	// If it's not a load balancer or a node port service, and the service is not referenced by any Ingress,
-	// then there's nothing we need to do.
-	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort && len(ingressList.Items) == 0 {
+	gatewayRoutes, err := r.getGatewayRoutesReferringToService(ctx, svc)
+	if err != nil {
+		return err
+	}
+	// If it's not a load balancer or a node port service, and the service is not referenced by any Ingress or Gateway
+	// route, then there's nothing we need to do.
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort && len(ingressList.Items) == 0 && len(gatewayRoutes) == 0 {
 		return r.handlePolicyDelete(ctx, r.formatPolicyName(svc.Name), svc.Namespace)
 	}
 
-	return r.handleEndpointsWithIngressList(ctx, endpoints, ingressList)
+	allowExternalTraffic, err := r.allowExternalTrafficForService(ctx, svc.Namespace, svc)
+	if err != nil {
+		return err
+	}
+
+	return r.handleEndpointsWithIngressList(ctx, endpoints, ingressList, gatewayRoutes, allowExternalTraffic)
 }
 
-func (r *NetworkPolicyHandler) handleEndpointsWithIngressList(ctx context.Context, endpoints *corev1.Endpoints, ingressList *v1.IngressList) error {
+func (r *NetworkPolicyHandler) handleEndpointsWithIngressList(ctx context.Context, endpoints *corev1.Endpoints, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute, allowExternalTraffic allowexternaltraffic.Enum) error {
 	addresses := r.getAddressesFromEndpoints(endpoints)
 	foundOtterizeNetpolsAffectingPods := false
 	for _, address := range addresses {
@@ -374,8 +419,8 @@ func (r *NetworkPolicyHandler) handleEndpointsWithIngressList(ctx context.Contex
 		}
 
 		if len(netpolList.Items) == 0 && len(svcNetpolList.Items) == 0 {
-			if r.allowExternalTraffic == allowexternaltraffic.Always {
-				err := r.handleNetpolsForOtterizeServiceWithoutIntents(ctx, endpoints, serverLabel, ingressList)
+			if allowExternalTraffic == allowexternaltraffic.Always {
+				err := r.handleNetpolsForOtterizeServiceWithoutIntents(ctx, endpoints, serverLabel, ingressList, gatewayRoutes, allowExternalTraffic)
 				if err != nil {
 					return err
 				}
@@ -388,14 +433,14 @@ func (r *NetworkPolicyHandler) handleEndpointsWithIngressList(ctx context.Contex
 		netpolSlice = append(netpolSlice, svcNetpolList.Items...)
 
 		foundOtterizeNetpolsAffectingPods = true
-		err = r.handleNetpolsForOtterizeService(ctx, endpoints, serverLabel, ingressList, netpolSlice)
+		err = r.handleNetpolsForOtterizeService(ctx, endpoints, serverLabel, ingressList, gatewayRoutes, allowExternalTraffic, netpolSlice)
 		if err != nil {
 			return err
 		}
 
 	}
 
-	if !foundOtterizeNetpolsAffectingPods && r.allowExternalTraffic != allowexternaltraffic.Always {
+	if !foundOtterizeNetpolsAffectingPods && allowExternalTraffic != allowexternaltraffic.Always {
 		policyName := r.formatPolicyName(endpoints.Name)
 		err := r.handlePolicyDelete(ctx, policyName, endpoints.Namespace)
 		if err != nil {
@@ -445,6 +490,26 @@ func (r *NetworkPolicyHandler) getIngressRefersToService(ctx context.Context, sv
 
 }
 
+// getGatewayRoutesReferringToService returns the routes that send traffic to the service and are attached to a Gateway,
+// sorted so that policies built from them are stable.
+func (r *NetworkPolicyHandler) getGatewayRoutesReferringToService(ctx context.Context, svc *corev1.Service) ([]gatewayRoute, error) {
+	routes := make([]gatewayRoute, 0)
+	for _, kind := range r.gatewayRouteKinds {
+		routeList := kind.newList()
+		err := r.client.List(ctx, routeList, &client.MatchingFields{otterizev1alpha3.GatewayRouteServicesIndexField: serviceKey(svc.Namespace, svc.Name)})
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, lo.Filter(gatewayRoutesFromList(routeList), func(route gatewayRoute, _ int) bool {
+			return len(route.gateways()) != 0
+		})...)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].key() < routes[j].key()
+	})
+	return routes, nil
+}
+
 func (r *NetworkPolicyHandler) handlePolicyDelete(ctx context.Context, policyName string, policyNamespace string) error {
 	policy := &v1.NetworkPolicy{}
 	err := r.client.Get(ctx, types.NamespacedName{Name: policyName, Namespace: policyNamespace}, policy)
@@ -478,12 +543,13 @@ func (r *NetworkPolicyHandler) handlePolicyDelete(ctx context.Context, policyNam
 		r.RecordWarningEventf(owner, ReasonRemovingExternalTrafficPolicyFailed, "failed removing external traffic network policy: %s", err.Error())
 		return err
 	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, policy)
 	r.RecordNormalEvent(owner, ReasonRemovedExternalTrafficPolicy, "removed external traffic network policy, success")
 
 	return nil
 }
 
-func (r *NetworkPolicyHandler) handleNetpolsForOtterizeService(ctx context.Context, endpoints *corev1.Endpoints, otterizeServiceName string, ingressList *v1.IngressList, netpolList []v1.NetworkPolicy) error {
+func (r *NetworkPolicyHandler) handleNetpolsForOtterizeService(ctx context.Context, endpoints *corev1.Endpoints, otterizeServiceName string, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute, allowExternalTraffic allowexternaltraffic.Enum, netpolList []v1.NetworkPolicy) error {
 	svc := &corev1.Service{}
 	err := r.client.Get(ctx, types.NamespacedName{Name: endpoints.Name, Namespace: endpoints.Namespace}, svc)
 	if err != nil {
@@ -491,7 +557,7 @@ func (r *NetworkPolicyHandler) handleNetpolsForOtterizeService(ctx context.Conte
 	}
 
 	// delete policy if disabled
-	if r.allowExternalTraffic == allowexternaltraffic.Off {
+	if allowExternalTraffic == allowexternaltraffic.Off {
 		r.RecordNormalEventf(svc, ReasonEnforcementGloballyDisabled, "Skipping created external traffic network policy for service '%s' because enforcement is globally disabled", endpoints.GetName())
 		err = r.handlePolicyDelete(ctx, r.formatPolicyName(endpoints.Name), endpoints.Namespace)
 		if err != nil {
@@ -502,7 +568,7 @@ func (r *NetworkPolicyHandler) handleNetpolsForOtterizeService(ctx context.Conte
 
 	for _, netpol := range netpolList {
 		successMsg := fmt.Sprintf(successMsgNetpolCreate, endpoints.GetName(), netpol.GetName())
-		err = r.createOrUpdateNetworkPolicy(ctx, endpoints, svc, otterizeServiceName, netpol.Spec.PodSelector, ingressList, successMsg)
+		err = r.createOrUpdateNetworkPolicy(ctx, endpoints, svc, otterizeServiceName, netpol.Spec.PodSelector, ingressList, gatewayRoutes, successMsg)
 
 		if err != nil {
 			return err
@@ -511,7 +577,7 @@ func (r *NetworkPolicyHandler) handleNetpolsForOtterizeService(ctx context.Conte
 	return nil
 }
 
-func (r *NetworkPolicyHandler) handleNetpolsForOtterizeServiceWithoutIntents(ctx context.Context, endpoints *corev1.Endpoints, otterizeServiceName string, ingressList *v1.IngressList) error {
+func (r *NetworkPolicyHandler) handleNetpolsForOtterizeServiceWithoutIntents(ctx context.Context, endpoints *corev1.Endpoints, otterizeServiceName string, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute, allowExternalTraffic allowexternaltraffic.Enum) error {
 	svc := &corev1.Service{}
 	err := r.client.Get(ctx, types.NamespacedName{Name: endpoints.Name, Namespace: endpoints.Namespace}, svc)
 	if err != nil {
@@ -519,7 +585,7 @@ func (r *NetworkPolicyHandler) handleNetpolsForOtterizeServiceWithoutIntents(ctx
 	}
 
 	// delete policy if disabled
-	if r.allowExternalTraffic == allowexternaltraffic.Off {
+	if allowExternalTraffic == allowexternaltraffic.Off {
 		r.RecordNormalEventf(svc, ReasonEnforcementGloballyDisabled, "Skipping created external traffic network policy for service '%s' because enforcement is globally disabled", endpoints.GetName())
 		err = r.handlePolicyDelete(ctx, r.formatPolicyName(endpoints.Name), endpoints.Namespace)
 		if err != nil {
@@ -528,13 +594,59 @@ func (r *NetworkPolicyHandler) handleNetpolsForOtterizeServiceWithoutIntents(ctx
 		return nil
 	}
 
-	err = r.createOrUpdateNetworkPolicy(ctx, endpoints, svc, otterizeServiceName, metav1.LabelSelector{MatchLabels: svc.Spec.Selector}, ingressList, fmt.Sprintf("created external traffic network policy for service '%s'", endpoints.GetName()))
+	err = r.createOrUpdateNetworkPolicy(ctx, endpoints, svc, otterizeServiceName, metav1.LabelSelector{MatchLabels: svc.Spec.Selector}, ingressList, gatewayRoutes, fmt.Sprintf("created external traffic network policy for service '%s'", endpoints.GetName()))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// allowExternalTrafficForService resolves the allow-external-traffic setting of a service. An annotation on the service
+// takes precedence over a label on its namespace, which takes precedence over the global setting. Invalid values are
+// reported and ignored. The service may be nil if it no longer exists.
+func (r *NetworkPolicyHandler) allowExternalTrafficForService(ctx context.Context, namespace string, svc *corev1.Service) (allowexternaltraffic.Enum, error) {
+	if svc != nil {
+		if value, ok := svc.Annotations[otterizev1alpha3.OtterizeAllowExternalTrafficKey]; ok {
+			var allowExternalTraffic allowexternaltraffic.Enum
+			if err := allowExternalTraffic.Set(value); err == nil {
+				return allowExternalTraffic, nil
+			}
+			r.RecordWarningEventf(svc, ReasonInvalidAllowExternalTraffic, "ignoring invalid value '%s' of annotation %s", value, otterizev1alpha3.OtterizeAllowExternalTrafficKey)
+		}
+	}
+
+	ns := &corev1.Namespace{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return "", err
+	}
+	if value, ok := ns.Labels[otterizev1alpha3.OtterizeAllowExternalTrafficKey]; ok {
+		var allowExternalTraffic allowexternaltraffic.Enum
+		if err := allowExternalTraffic.Set(value); err == nil {
+			return allowExternalTraffic, nil
+		}
+		r.RecordWarningEventf(ns, ReasonInvalidAllowExternalTraffic, "ignoring invalid value '%s' of label %s", value, otterizev1alpha3.OtterizeAllowExternalTrafficKey)
+	}
+
+	return r.allowExternalTraffic, nil
+}
+
+// allowExternalTrafficForPolicy resolves the allow-external-traffic setting of the service an external traffic policy
+// was created for.
+func (r *NetworkPolicyHandler) allowExternalTrafficForPolicy(ctx context.Context, policy *v1.NetworkPolicy) (allowexternaltraffic.Enum, error) {
+	var svc *corev1.Service
+	if serviceName, ok := policy.Annotations[v1alpha2.OtterizeCreatedForServiceAnnotation]; ok {
+		svc = &corev1.Service{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: policy.Namespace}, svc)
+		if k8serrors.IsNotFound(err) {
+			svc = nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return r.allowExternalTrafficForService(ctx, policy.Namespace, svc)
+}
+
 func (r *NetworkPolicyHandler) formatPolicyName(serviceName string) string {
 	return fmt.Sprintf(OtterizeExternalNetworkPolicyNameTemplate, serviceName)
 }
@@ -3,13 +3,16 @@ package external_traffic
 import (
 	"context"
 	otterizev1alpha2 "github.com/otterize/intents-operator/src/operator/api/v1alpha2"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
 	"github.com/otterize/intents-operator/src/shared/testbase"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
 )
@@ -21,7 +24,101 @@ type NetworkPolicyHandlerTestSuite struct {
 
 func (s *NetworkPolicyHandlerTestSuite) SetupTest() {
 	s.MocksSuiteBase.SetupTest()
-	s.handler = NewNetworkPolicyHandler(s.Client, &runtime.Scheme{}, allowexternaltraffic.IfBlockedByOtterize)
+	s.handler = NewNetworkPolicyHandler(s.Client, &runtime.Scheme{}, allowexternaltraffic.IfBlockedByOtterize, IngressControllerSelector{}, nil, GatewayDataPlaneSelector{})
+	s.handler.InjectRecorder(s.Recorder)
+}
+
+func (s *NetworkPolicyHandlerTestSuite) expectGetNamespace(namespace string, labels map[string]string) {
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: namespace}, gomock.AssignableToTypeOf(&corev1.Namespace{})).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, ns *corev1.Namespace, _ ...client.GetOption) error {
+			ns.Name = namespace
+			ns.Labels = labels
+			return nil
+		},
+	)
+}
+
+func (s *NetworkPolicyHandlerTestSuite) expectAccessPolicyRemoval(toBeRemovedPolicy *v1.NetworkPolicy, externalPolicy *v1.NetworkPolicy) {
+	serviceName := toBeRemovedPolicy.Labels[otterizev1alpha2.OtterizeNetworkPolicy]
+	firstList := s.Client.EXPECT().List(
+		gomock.Any(), gomock.Eq(&v1.NetworkPolicyList{}), client.MatchingLabels{otterizev1alpha2.OtterizeNetworkPolicy: serviceName}, &client.ListOptions{Namespace: toBeRemovedPolicy.Namespace},
+	).DoAndReturn(
+		func(_ any, list *v1.NetworkPolicyList, _ ...any) error {
+			list.Items = []v1.NetworkPolicy{*toBeRemovedPolicy}
+			return nil
+		},
+	)
+	secondList := s.Client.EXPECT().List(
+		gomock.Any(), gomock.Eq(&v1.NetworkPolicyList{}),
+		client.MatchingLabels{otterizev1alpha2.OtterizeNetworkPolicyExternalTraffic: serviceName},
+		&client.ListOptions{Namespace: toBeRemovedPolicy.Namespace},
+	).DoAndReturn(
+		func(_ any, list *v1.NetworkPolicyList, _ ...any) error {
+			list.Items = []v1.NetworkPolicy{*externalPolicy}
+			return nil
+		},
+	)
+	gomock.InOrder(firstList, secondList)
+}
+
+func accessAndExternalPolicies(serviceName string, serviceNamespace string) (*v1.NetworkPolicy, *v1.NetworkPolicy) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{otterizev1alpha2.OtterizeServerLabelKey: serviceName}}
+	accessPolicy := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "coolPolicy",
+			Namespace: serviceNamespace,
+			Labels:    map[string]string{otterizev1alpha2.OtterizeNetworkPolicy: serviceName},
+		},
+		Spec: v1.NetworkPolicySpec{PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress}, PodSelector: selector},
+	}
+	externalPolicy := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "externalPolicy",
+			Namespace:   serviceNamespace,
+			Labels:      map[string]string{otterizev1alpha2.OtterizeNetworkPolicyExternalTraffic: serviceName},
+			Annotations: map[string]string{otterizev1alpha2.OtterizeCreatedForServiceAnnotation: serviceName},
+		},
+		Spec: v1.NetworkPolicySpec{PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress}, PodSelector: selector},
+	}
+	return accessPolicy, externalPolicy
+}
+
+func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_HandleBeforeAccessPolicyRemoval_serviceAnnotationAlways_doNothing() {
+	accessPolicy, externalPolicy := accessAndExternalPolicies("testservice", "testnamespace")
+	s.expectAccessPolicyRemoval(accessPolicy, externalPolicy)
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "testservice", Namespace: "testnamespace"}, gomock.AssignableToTypeOf(&corev1.Service{})).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, svc *corev1.Service, _ ...client.GetOption) error {
+			svc.Annotations = map[string]string{otterizev1alpha3.OtterizeAllowExternalTrafficKey: string(allowexternaltraffic.Always)}
+			return nil
+		},
+	)
+
+	s.Require().NoError(s.handler.HandleBeforeAccessPolicyRemoval(context.Background(), accessPolicy))
+}
+
+func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_HandleBeforeAccessPolicyRemoval_namespaceLabelOverridesGlobalAlways_shouldDelete() {
+	s.handler.allowExternalTraffic = allowexternaltraffic.Always
+	accessPolicy, externalPolicy := accessAndExternalPolicies("testservice", "testnamespace")
+	s.expectAccessPolicyRemoval(accessPolicy, externalPolicy)
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "testservice", Namespace: "testnamespace"}, gomock.AssignableToTypeOf(&corev1.Service{})).Return(nil)
+	s.expectGetNamespace("testnamespace", map[string]string{otterizev1alpha3.OtterizeAllowExternalTrafficKey: string(allowexternaltraffic.IfBlockedByOtterize)})
+	s.Client.EXPECT().Delete(gomock.Any(), externalPolicy, gomock.Any())
+
+	s.Require().NoError(s.handler.HandleBeforeAccessPolicyRemoval(context.Background(), accessPolicy))
+}
+
+func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_InvalidOverrideIsIgnored() {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        "testservice",
+		Namespace:   "testnamespace",
+		Annotations: map[string]string{otterizev1alpha3.OtterizeAllowExternalTrafficKey: "sometimes"},
+	}}
+	s.expectGetNamespace("testnamespace", map[string]string{otterizev1alpha3.OtterizeAllowExternalTrafficKey: string(allowexternaltraffic.Off)})
+
+	allowExternalTraffic, err := s.handler.allowExternalTrafficForService(context.Background(), svc.Namespace, svc)
+	s.Require().NoError(err)
+	s.Require().Equal(allowexternaltraffic.Off, allowExternalTraffic)
+	s.ExpectEvent(ReasonInvalidAllowExternalTraffic)
 }
 
 func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_HandleBeforeAccessPolicyRemoval_createWhenNoIntentsEnabled_doNothing() {
@@ -46,6 +143,10 @@ func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_HandleBeforeAcc
 			},
 		},
 	}
+	externalPolicy := toBeRemovedPolicy.DeepCopy()
+	externalPolicy.Labels = map[string]string{otterizev1alpha2.OtterizeNetworkPolicyExternalTraffic: serviceName}
+	s.expectAccessPolicyRemoval(toBeRemovedPolicy, externalPolicy)
+	s.expectGetNamespace(serviceNamespace, nil)
 
 	s.Require().NoError(s.handler.HandleBeforeAccessPolicyRemoval(context.Background(), toBeRemovedPolicy))
 }
@@ -108,6 +209,7 @@ func (s *NetworkPolicyHandlerTestSuite) TestNetworkPolicyHandler_HandleBeforeAcc
 		},
 	)
 	gomock.InOrder(firstList, secondList)
+	s.expectGetNamespace(serviceNamespace, nil)
 
 	s.Client.EXPECT().Delete(gomock.Any(), externalPolicy, gomock.Any())
 	err := s.handler.HandleBeforeAccessPolicyRemoval(context.Background(), toBeRemovedPolicy)
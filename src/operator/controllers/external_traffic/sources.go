@@ -0,0 +1,167 @@
+package external_traffic
+
+import (
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"strings"
+)
+
+// IngressControllerSelector selects the ingress controller pods that forward traffic to services referenced by an
+// Ingress. A selector left unset matches everything; when both are unset, such services may be reached from anywhere.
+type IngressControllerSelector struct {
+	NamespaceSelector *metav1.LabelSelector
+	PodSelector       *metav1.LabelSelector
+}
+
+// NewIngressControllerSelector parses label selectors in the kubectl syntax, such as "app.kubernetes.io/name=ingress-nginx".
+func NewIngressControllerSelector(namespaceSelector string, podSelector string) (IngressControllerSelector, error) {
+	namespaceLabelSelector, err := parseLabelSelector(namespaceSelector)
+	if err != nil {
+		return IngressControllerSelector{}, fmt.Errorf("invalid ingress controller namespace selector: %w", err)
+	}
+	podLabelSelector, err := parseLabelSelector(podSelector)
+	if err != nil {
+		return IngressControllerSelector{}, fmt.Errorf("invalid ingress controller pod selector: %w", err)
+	}
+	return IngressControllerSelector{NamespaceSelector: namespaceLabelSelector, PodSelector: podLabelSelector}, nil
+}
+
+func (s IngressControllerSelector) isEmpty() bool {
+	return s.NamespaceSelector == nil && s.PodSelector == nil
+}
+
+func parseLabelSelector(selector string) (*metav1.LabelSelector, error) {
+	if selector == "" {
+		return nil, nil
+	}
+	labelSelector, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	// Empty fields are dropped by the API server, and would make the policy differ from the one read back.
+	if len(labelSelector.MatchLabels) == 0 {
+		labelSelector.MatchLabels = nil
+	}
+	if len(labelSelector.MatchExpressions) == 0 {
+		labelSelector.MatchExpressions = nil
+	}
+	return labelSelector, nil
+}
+
+// selectorPeer returns a peer matching pods in any namespace, unless a namespace selector narrows it down.
+func selectorPeer(namespaceSelector *metav1.LabelSelector, podSelector *metav1.LabelSelector) v1.NetworkPolicyPeer {
+	if namespaceSelector == nil {
+		namespaceSelector = &metav1.LabelSelector{}
+	}
+	return v1.NetworkPolicyPeer{NamespaceSelector: namespaceSelector, PodSelector: podSelector}
+}
+
+// GatewayDataPlaneSelector locates the data-plane pods of a Gateway. By default, they are looked up in the namespace
+// of the Gateway by the GatewayNameLabelKey label, which is where most Gateway API implementations deploy them.
+// Implementations that run a shared data plane elsewhere, or label it differently, need Namespace or NameLabelKey set.
+type GatewayDataPlaneSelector struct {
+	// Namespace of the data-plane pods. Empty means the namespace of the Gateway.
+	Namespace string
+	// NameLabelKey is the key of the label holding the name of the Gateway. Empty means GatewayNameLabelKey.
+	NameLabelKey string
+}
+
+// gatewayPeer returns a peer matching the data-plane pods of a Gateway.
+func (s GatewayDataPlaneSelector) gatewayPeer(gateway types.NamespacedName) v1.NetworkPolicyPeer {
+	namespace := s.Namespace
+	if namespace == "" {
+		namespace = gateway.Namespace
+	}
+	nameLabelKey := s.NameLabelKey
+	if nameLabelKey == "" {
+		nameLabelKey = GatewayNameLabelKey
+	}
+	return v1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: namespace}},
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{nameLabelKey: gateway.Name}},
+	}
+}
+
+// allowedSources returns the peers that may reach the pods of a service through its external traffic policy, or nil
+// if any source may, which is the case unless every way the service is exposed has known sources:
+//   - Services referenced by an Ingress are reached from the ingress controller pods, if a selector for them is set.
+//   - Services referenced by Gateway API routes are reached from the data-plane pods of the Gateways the routes are
+//     attached to, as located by the GatewayDataPlaneSelector.
+//   - LoadBalancer services are reached from their loadBalancerSourceRanges. The ranges are only used with the Local
+//     external traffic policy, since client addresses are replaced by node addresses otherwise.
+//   - NodePort services may be reached from anywhere.
+//
+// Sources set by annotations on the service take precedence over all of the above.
+func (r *NetworkPolicyHandler) allowedSources(svc *corev1.Service, ingressList *v1.IngressList, gatewayRoutes []gatewayRoute) ([]v1.NetworkPolicyPeer, error) {
+	peers, ok, err := sourcesFromAnnotations(svc)
+	if err != nil || ok {
+		return peers, err
+	}
+
+	peers = make([]v1.NetworkPolicyPeer, 0)
+	if len(ingressList.Items) != 0 {
+		if r.ingressControllerSelector.isEmpty() {
+			return nil, nil
+		}
+		peers = append(peers, selectorPeer(r.ingressControllerSelector.NamespaceSelector, r.ingressControllerSelector.PodSelector))
+	}
+
+	for _, gateway := range gatewaysOfRoutes(gatewayRoutes) {
+		peers = append(peers, r.gatewayDataPlaneSelector.gatewayPeer(gateway))
+	}
+
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		if len(svc.Spec.LoadBalancerSourceRanges) == 0 || svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal {
+			return nil, nil
+		}
+		for _, sourceRange := range svc.Spec.LoadBalancerSourceRanges {
+			peers = append(peers, v1.NetworkPolicyPeer{IPBlock: &v1.IPBlock{CIDR: strings.TrimSpace(sourceRange)}})
+		}
+	case corev1.ServiceTypeNodePort:
+		return nil, nil
+	}
+
+	return peers, nil
+}
+
+// sourcesFromAnnotations reads the sources allowed by the external traffic annotations of a service, if it has any.
+func sourcesFromAnnotations(svc *corev1.Service) ([]v1.NetworkPolicyPeer, bool, error) {
+	cidrs, hasCIDRs := svc.Annotations[otterizev1alpha3.OtterizeExternalTrafficSourceCIDRsAnnotation]
+	namespaceSelector, hasNamespaceSelector := svc.Annotations[otterizev1alpha3.OtterizeExternalTrafficSourceNamespacesAnnotation]
+	podSelector, hasPodSelector := svc.Annotations[otterizev1alpha3.OtterizeExternalTrafficSourcePodsAnnotation]
+	if !hasCIDRs && !hasNamespaceSelector && !hasPodSelector {
+		return nil, false, nil
+	}
+
+	peers := make([]v1.NetworkPolicyPeer, 0)
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, false, fmt.Errorf("invalid CIDR %q in annotation %s: %w", cidr, otterizev1alpha3.OtterizeExternalTrafficSourceCIDRsAnnotation, err)
+		}
+		peers = append(peers, v1.NetworkPolicyPeer{IPBlock: &v1.IPBlock{CIDR: cidr}})
+	}
+
+	if hasNamespaceSelector || hasPodSelector {
+		namespaceLabelSelector, err := parseLabelSelector(namespaceSelector)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid label selector in annotation %s: %w", otterizev1alpha3.OtterizeExternalTrafficSourceNamespacesAnnotation, err)
+		}
+		podLabelSelector, err := parseLabelSelector(podSelector)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid label selector in annotation %s: %w", otterizev1alpha3.OtterizeExternalTrafficSourcePodsAnnotation, err)
+		}
+		peers = append(peers, selectorPeer(namespaceLabelSelector, podLabelSelector))
+	}
+
+	return peers, true, nil
+}
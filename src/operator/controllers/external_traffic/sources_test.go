@@ -0,0 +1,127 @@
+package external_traffic
+
+import (
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+type ExternalTrafficSourcesTestSuite struct {
+	suite.Suite
+	handler *NetworkPolicyHandler
+}
+
+func (s *ExternalTrafficSourcesTestSuite) SetupTest() {
+	ingressControllerSelector, err := NewIngressControllerSelector("kubernetes.io/metadata.name=ingress-nginx", "app.kubernetes.io/name=ingress-nginx")
+	s.Require().NoError(err)
+	s.handler = NewNetworkPolicyHandler(nil, &runtime.Scheme{}, allowexternaltraffic.IfBlockedByOtterize, ingressControllerSelector, nil, GatewayDataPlaneSelector{})
+}
+
+func ingressList(names ...string) *v1.IngressList {
+	list := &v1.IngressList{}
+	for _, name := range names {
+		list.Items = append(list.Items, v1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return list
+}
+
+func (s *ExternalTrafficSourcesTestSuite) ingressControllerPeer() v1.NetworkPolicyPeer {
+	return v1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}},
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}},
+	}
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestIngressBackedServiceAllowsIngressControllers() {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+
+	sources, err := s.handler.allowedSources(svc, ingressList("web"), nil)
+	s.Require().NoError(err)
+	s.Require().Equal([]v1.NetworkPolicyPeer{s.ingressControllerPeer()}, sources)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestIngressBackedServiceWithoutSelectorIsUnrestricted() {
+	s.handler.ingressControllerSelector = IngressControllerSelector{}
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+
+	sources, err := s.handler.allowedSources(svc, ingressList("web"), nil)
+	s.Require().NoError(err)
+	s.Require().Nil(sources)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestLoadBalancerSourceRanges() {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{
+		Type:                     corev1.ServiceTypeLoadBalancer,
+		ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+		LoadBalancerSourceRanges: []string{"203.0.113.0/24"},
+	}}
+
+	sources, err := s.handler.allowedSources(svc, ingressList("web"), nil)
+	s.Require().NoError(err)
+	s.Require().Equal([]v1.NetworkPolicyPeer{
+		s.ingressControllerPeer(),
+		{IPBlock: &v1.IPBlock{CIDR: "203.0.113.0/24"}},
+	}, sources)
+
+	// Client addresses are not preserved with the Cluster policy, so the ranges can't be enforced by the policy.
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+	sources, err = s.handler.allowedSources(svc, ingressList(), nil)
+	s.Require().NoError(err)
+	s.Require().Nil(sources)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestNodePortIsUnrestricted() {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort}}
+
+	sources, err := s.handler.allowedSources(svc, ingressList("web"), nil)
+	s.Require().NoError(err)
+	s.Require().Nil(sources)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestAnnotationsTakePrecedence() {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			otterizev1alpha3.OtterizeExternalTrafficSourceCIDRsAnnotation: "10.0.0.0/8, 192.168.1.1/32",
+			otterizev1alpha3.OtterizeExternalTrafficSourcePodsAnnotation:  "app=gateway",
+		}},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+	}
+
+	sources, err := s.handler.allowedSources(svc, ingressList("web"), nil)
+	s.Require().NoError(err)
+	s.Require().Equal([]v1.NetworkPolicyPeer{
+		{IPBlock: &v1.IPBlock{CIDR: "10.0.0.0/8"}},
+		{IPBlock: &v1.IPBlock{CIDR: "192.168.1.1/32"}},
+		{NamespaceSelector: &metav1.LabelSelector{}, PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gateway"}}},
+	}, sources)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestInvalidAnnotation() {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			otterizev1alpha3.OtterizeExternalTrafficSourceCIDRsAnnotation: "10.0.0.0/33",
+		}},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+
+	_, err := s.handler.allowedSources(svc, ingressList(), nil)
+	s.Require().Error(err)
+}
+
+func (s *ExternalTrafficSourcesTestSuite) TestPolicyRestrictsSources() {
+	endpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	sources := []v1.NetworkPolicyPeer{s.ingressControllerPeer()}
+
+	netpol := buildNetworkPolicyObjectForEndpoints(endpoints, "web-default", metav1.LabelSelector{}, ingressList("web"), nil, sources, "external-access-to-web")
+	s.Require().Len(netpol.Spec.Ingress, 1)
+	s.Require().Equal(sources, netpol.Spec.Ingress[0].From)
+}
+
+func TestExternalTrafficSourcesTestSuite(t *testing.T) {
+	suite.Run(t, new(ExternalTrafficSourcesTestSuite))
+}
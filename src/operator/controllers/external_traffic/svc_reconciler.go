@@ -4,15 +4,22 @@ import (
 	"context"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups="networking.k8s.io",resources=networkpolicies,verbs=get;update;patch;list;watch;delete;create
 
 type ServiceReconciler struct {
@@ -34,10 +41,28 @@ func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Service{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToServices), builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
 		Complete(r)
 }
 
+// mapNamespaceToServices reconciles the services of a namespace when its labels change, since they may override
+// whether external traffic is allowed to them.
+func (r *ServiceReconciler) mapNamespaceToServices(ctx context.Context, obj client.Object) []reconcile.Request {
+	serviceList := &corev1.ServiceList{}
+	err := r.List(ctx, serviceList, &client.ListOptions{Namespace: obj.GetName()})
+	if err != nil {
+		logrus.WithError(err).Errorf("failed listing services in namespace %s", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, svc := range serviceList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}})
+	}
+	return requests
+}
+
 func (r *ServiceReconciler) InjectRecorder(recorder record.EventRecorder) {
 	r.Recorder = recorder
 	r.extNetpolHandler.InjectRecorder(recorder)
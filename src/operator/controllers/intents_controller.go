@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
@@ -31,6 +32,7 @@ import (
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
 	"github.com/otterize/intents-operator/src/shared/initonce"
 	"github.com/otterize/intents-operator/src/shared/operator_cloud_client"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
@@ -65,6 +67,7 @@ type EnforcementConfig struct {
 	EnableDatabaseReconciler             bool
 	EnableEgressNetworkPolicyReconcilers bool
 	EnableAWSPolicy                      bool
+	AccessLabels                         accesslabels.Enum
 }
 
 // IntentsReconciler reconciles a Intents object
@@ -79,6 +82,7 @@ func NewIntentsReconciler(
 	client client.Client,
 	scheme *runtime.Scheme,
 	kafkaServerStore kafkaacls.ServersStore,
+	kafkaIntentsAdminFactory kafkaacls.IntentsAdminFactoryFunction,
 	networkPolicyReconciler *ingress_network_policy.NetworkPolicyReconciler,
 	portNetpolReconciler *port_network_policy.PortNetworkPolicyReconciler,
 	egressNetpolReconciler *egress_network_policy.EgressNetworkPolicyReconciler,
@@ -94,8 +98,8 @@ func NewIntentsReconciler(
 	serviceIdResolver := serviceidresolver.NewResolver(client)
 	reconcilers := []reconcilergroup.ReconcilerWithEvents{
 		intents_reconcilers.NewCRDValidatorReconciler(client, scheme),
-		intents_reconcilers.NewPodLabelReconciler(client, scheme),
-		intents_reconcilers.NewKafkaACLReconciler(client, scheme, kafkaServerStore, enforcementConfig.EnableKafkaACL, kafkaacls.NewKafkaIntentsAdmin, enforcementConfig.EnforcementDefaultState, operatorPodName, operatorPodNamespace, serviceIdResolver),
+		intents_reconcilers.NewPodLabelReconciler(client, scheme, enforcementConfig.AccessLabels),
+		intents_reconcilers.NewKafkaACLReconciler(client, scheme, kafkaServerStore, enforcementConfig.EnableKafkaACL, kafkaIntentsAdminFactory, enforcementConfig.EnforcementDefaultState, operatorPodName, operatorPodNamespace, serviceIdResolver),
 		intents_reconcilers.NewIstioPolicyReconciler(client, scheme, restrictToNamespaces, enforcementConfig.EnableIstioPolicy, enforcementConfig.EnforcementDefaultState),
 		networkPolicyReconciler,
 	}
@@ -170,15 +174,19 @@ func (r *IntentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	result, err := r.group.Reconcile(ctx, req)
-	if err != nil {
+	// Terminal errors come from open circuit breakers, whose reconcilers are retried by the group on their own, so the
+	// status is still updated to report them.
+	if err != nil && !errors.Is(err, reconcile.TerminalError(nil)) {
 		return ctrl.Result{}, err
 	}
 
-	intents.Status.UpToDate = true
+	openCircuitBreakers := r.group.OpenCircuitBreakers(req)
+	intents.Status.UpToDate = len(openCircuitBreakers) == 0
+	intents.Status.OpenCircuitBreakers = openCircuitBreakers
 	if err := r.client.Status().Update(ctx, intents); err != nil {
 		return ctrl.Result{}, err
 	}
-	return result, nil
+	return result, err
 }
 
 func (r *IntentsReconciler) intentsReconcilerInit(ctx context.Context) error {
@@ -191,6 +199,8 @@ func (r *IntentsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&otterizev1alpha3.ClientIntents{}).
 		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
 		Watches(&otterizev1alpha3.ProtectedService{}, handler.EnqueueRequestsFromMapFunc(r.mapProtectedServiceToClientIntents)).
+		Watches(&otterizev1alpha3.DefaultClientIntents{}, handler.EnqueueRequestsFromMapFunc(r.mapDefaultClientIntentsToClientIntents)).
+		WatchesRawSource(r.group.RecoveredSource(), &handler.EnqueueRequestForObject{}).
 		Complete(r)
 	if err != nil {
 		return err
@@ -209,6 +219,18 @@ func (r *IntentsReconciler) mapProtectedServiceToClientIntents(_ context.Context
 	return r.mapIntentsToRequests(intentsToReconcile)
 }
 
+// mapDefaultClientIntentsToClientIntents enqueues the client intents of the namespace of the default intents, since
+// their default calls are merged into the calls of each of them.
+func (r *IntentsReconciler) mapDefaultClientIntentsToClientIntents(ctx context.Context, obj client.Object) []reconcile.Request {
+	var intentsList otterizev1alpha3.ClientIntentsList
+	err := r.client.List(ctx, &intentsList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed listing client intents for default intents %s/%s", obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+	return r.mapIntentsToRequests(intentsList.Items)
+}
+
 func (r *IntentsReconciler) mapIntentsToRequests(intentsToReconcile []otterizev1alpha3.ClientIntents) []reconcile.Request {
 	requests := make([]reconcile.Request, 0)
 	for _, clientIntents := range intentsToReconcile {
@@ -253,7 +275,8 @@ func (r *IntentsReconciler) InitIntentsServerIndices(mgr ctrl.Manager) error {
 				return nil
 			}
 
-			for _, intent := range intents.GetCallsList() {
+			// Default calls aren't indexed, their servers are reached through the policies of the namespace defaults.
+			for _, intent := range intents.Spec.Calls {
 				if !intent.IsTargetServerKubernetesService() {
 					res = append(res, intent.GetServerFullyQualifiedName(intents.Namespace))
 				}
@@ -280,10 +303,8 @@ func (r *IntentsReconciler) InitIntentsServerIndices(mgr ctrl.Manager) error {
 				return nil
 			}
 
-			for _, intent := range intents.GetCallsList() {
-				serverName := intent.GetTargetServerName()
-				serverNamespace := intent.GetTargetServerNamespace(intents.Namespace)
-				formattedServerName := otterizev1alpha3.GetFormattedOtterizeIdentity(serverName, serverNamespace)
+			for _, intent := range intents.Spec.Calls {
+				formattedServerName := intent.GetFormattedTargetServerIdentity(intents.Namespace)
 				if !intent.IsTargetServerKubernetesService() {
 					res = append(res, formattedServerName)
 				} else {
@@ -32,6 +32,7 @@ func (s *IntentsControllerTestSuite) SetupTest() {
 		nil,
 		nil,
 		nil,
+		nil,
 		EnforcementConfig{},
 		nil,
 		"",
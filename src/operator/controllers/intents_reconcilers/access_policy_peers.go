@@ -0,0 +1,173 @@
+package intents_reconcilers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// ClientIdentity identifies the pods of a client, by the formatted identity the pod labelers set on them and, when the
+// intents of the client set it, by the kind of their workload. Without a kind, workloads of every kind that share the
+// name of the client are selected.
+type ClientIdentity struct {
+	FormattedIdentity string
+	Kind              string
+}
+
+// ClientIdentityOf returns the identity of the client of intents in clientNamespace.
+func ClientIdentityOf(intents *otterizev1alpha3.ClientIntents, clientNamespace string) ClientIdentity {
+	return ClientIdentity{
+		FormattedIdentity: otterizev1alpha3.GetFormattedOtterizeIdentity(intents.GetServiceName(), clientNamespace),
+		Kind:              intents.Spec.Service.Kind,
+	}
+}
+
+// ClientIdentitiesOfServer returns the identities of the clients in clientNamespace whose intents refer to the server,
+// as indexed by OtterizeFormattedTargetServerIndexField. Intents that are being deleted are skipped.
+func ClientIdentitiesOfServer(ctx context.Context, reader client.Reader, formattedTargetServer string, clientNamespace string) (sets.Set[ClientIdentity], error) {
+	var intentsList otterizev1alpha3.ClientIntentsList
+	err := reader.List(
+		ctx,
+		&intentsList,
+		&client.MatchingFields{otterizev1alpha3.OtterizeFormattedTargetServerIndexField: formattedTargetServer},
+		&client.ListOptions{Namespace: clientNamespace},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := sets.Set[ClientIdentity]{}
+	for _, intents := range intentsList.Items {
+		if !intents.DeletionTimestamp.IsZero() {
+			continue
+		}
+		identities.Insert(ClientIdentityOf(&intents, clientNamespace))
+	}
+	return identities, nil
+}
+
+// AccessPolicyPeers returns the peers allowed by an access network policy. Clients are selected by the access label
+// the pod labelers set on them, by their identity, or by both while migrating between the two schemes. Every peer
+// selects the client namespace, which is how orphaned policies are traced back to their clients. Clients of a specific
+// kind get a peer of their own, which also matches the owner kind label of their pods.
+func AccessPolicyPeers(scheme accesslabels.Enum, accessLabel string, clientNamespace string, clientIdentities sets.Set[ClientIdentity]) []v1.NetworkPolicyPeer {
+	namespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: clientNamespace,
+		},
+	}
+
+	peers := make([]v1.NetworkPolicyPeer, 0)
+	if scheme.SelectsByAccessLabel() {
+		peers = append(peers, v1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{accessLabel: "true"},
+			},
+			NamespaceSelector: namespaceSelector,
+		})
+	}
+	if !scheme.SelectsByClientIdentity() {
+		return peers
+	}
+
+	identities := clientIdentities.UnsortedList()
+	sort.Slice(identities, func(i, j int) bool {
+		if identities[i].Kind != identities[j].Kind {
+			return identities[i].Kind < identities[j].Kind
+		}
+		return identities[i].FormattedIdentity < identities[j].FormattedIdentity
+	})
+	anyKindIdentities := make([]string, 0)
+	for _, identity := range identities {
+		if identity.Kind == "" {
+			anyKindIdentities = append(anyKindIdentities, identity.FormattedIdentity)
+		}
+	}
+	if len(anyKindIdentities) != 0 {
+		peers = append(peers, v1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      otterizev1alpha3.OtterizeServerLabelKey,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   anyKindIdentities,
+				}},
+			},
+			NamespaceSelector: namespaceSelector,
+		})
+	}
+	for _, identity := range identities {
+		if identity.Kind == "" {
+			continue
+		}
+		peers = append(peers, v1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					otterizev1alpha3.OtterizeServerLabelKey:    identity.FormattedIdentity,
+					otterizev1alpha3.OtterizeOwnerKindLabelKey: identity.Kind,
+				},
+			},
+			NamespaceSelector: namespaceSelector,
+		})
+	}
+	return peers
+}
+
+// accessPolicyClientNamespace returns the client namespace selected by the peers of an access network policy, and false
+// if the policy doesn't have the ingress rule and peers access policies are created with.
+func accessPolicyClientNamespace(networkPolicy v1.NetworkPolicy) (string, bool) {
+	if len(networkPolicy.Spec.Ingress) == 0 || len(networkPolicy.Spec.Ingress[0].From) == 0 {
+		return "", false
+	}
+	namespaceSelector := networkPolicy.Spec.Ingress[0].From[0].NamespaceSelector
+	if namespaceSelector == nil {
+		return "", false
+	}
+	clientNamespace, ok := namespaceSelector.MatchLabels[otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey]
+	return clientNamespace, ok && clientNamespace != ""
+}
+
+// RefreshAccessPolicyClients updates the clients allowed by an access network policy that selects clients by identity,
+// after clients in its namespace stopped calling the server while others still do. formattedTargetServer is the server
+// as indexed by OtterizeFormattedTargetServerIndexField, and accessLabel the label clients of the server get. Policies
+// that weren't created as access policies are skipped.
+func RefreshAccessPolicyClients(ctx context.Context, k8sClient client.Client, scheme accesslabels.Enum, networkPolicy v1.NetworkPolicy, formattedTargetServer string, accessLabel string) error {
+	if !scheme.SelectsByClientIdentity() {
+		return nil
+	}
+
+	clientNamespace, ok := accessPolicyClientNamespace(networkPolicy)
+	if !ok {
+		logrus.WithField("policy", client.ObjectKeyFromObject(&networkPolicy).String()).Warning("Network policy does not select a client namespace, skipping refresh of its clients")
+		return nil
+	}
+	clientIdentities, err := ClientIdentitiesOfServer(ctx, k8sClient, formattedTargetServer, clientNamespace)
+	if err != nil {
+		return err
+	}
+	if clientIdentities.Len() == 0 {
+		// The remaining intents are being deleted, and the policy will be removed along with them.
+		return nil
+	}
+
+	peers := AccessPolicyPeers(scheme, accessLabel, clientNamespace, clientIdentities)
+	if reflect.DeepEqual(networkPolicy.Spec.Ingress[0].From, peers) {
+		return nil
+	}
+
+	policyCopy := networkPolicy.DeepCopy()
+	policyCopy.Spec.Ingress[0].From = peers
+	err = k8sClient.Patch(ctx, policyCopy, client.MergeFrom(&networkPolicy))
+	if err != nil {
+		return err
+	}
+	auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, &networkPolicy, policyCopy)
+	return nil
+}
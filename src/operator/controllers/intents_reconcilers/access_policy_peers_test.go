@@ -0,0 +1,93 @@
+package intents_reconcilers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
+	"github.com/otterize/intents-operator/src/shared/testbase"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+const (
+	refreshServerName      = "server-test-namespace"
+	refreshClientNamespace = "client-namespace"
+)
+
+type RefreshAccessPolicyClientsTestSuite struct {
+	testbase.MocksSuiteBase
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) accessPolicy(peers []v1.NetworkPolicyPeer) v1.NetworkPolicy {
+	return v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "access-to-server-from-client-namespace", Namespace: "test-namespace"},
+		Spec: v1.NetworkPolicySpec{
+			Ingress: []v1.NetworkPolicyIngressRule{{From: peers}},
+		},
+	}
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) refresh(networkPolicy v1.NetworkPolicy) error {
+	return RefreshAccessPolicyClients(context.Background(), s.Client, accesslabels.ClientIdentity, networkPolicy, refreshServerName, "access-label")
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) expectClients(clientNames ...string) {
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.Any(),
+		&client.MatchingFields{otterizev1alpha3.OtterizeFormattedTargetServerIndexField: refreshServerName},
+		&client.ListOptions{Namespace: refreshClientNamespace},
+	).DoAndReturn(func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+		for _, clientName := range clientNames {
+			list.Items = append(list.Items, otterizev1alpha3.ClientIntents{
+				ObjectMeta: metav1.ObjectMeta{Name: clientName, Namespace: refreshClientNamespace},
+				Spec:       &otterizev1alpha3.IntentsSpec{Service: otterizev1alpha3.Service{Name: clientName}},
+			})
+		}
+		return nil
+	})
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) peersOf(clientNames ...string) []v1.NetworkPolicyPeer {
+	identities := sets.New[ClientIdentity]()
+	for _, clientName := range clientNames {
+		identities.Insert(ClientIdentity{FormattedIdentity: otterizev1alpha3.GetFormattedOtterizeIdentity(clientName, refreshClientNamespace)})
+	}
+	return AccessPolicyPeers(accesslabels.ClientIdentity, "access-label", refreshClientNamespace, identities)
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) TestRemovedClientIsNoLongerAllowed() {
+	networkPolicy := s.accessPolicy(s.peersOf("client-a", "client-b"))
+	s.expectClients("client-a")
+
+	s.Client.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, patched *v1.NetworkPolicy, _ client.Patch, _ ...client.PatchOption) error {
+			s.Require().Equal(s.peersOf("client-a"), patched.Spec.Ingress[0].From)
+			return nil
+		})
+
+	s.Require().NoError(s.refresh(networkPolicy))
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) TestUnchangedClientsAreNotPatched() {
+	networkPolicy := s.accessPolicy(s.peersOf("client-a"))
+	s.expectClients("client-a")
+
+	s.Require().NoError(s.refresh(networkPolicy))
+}
+
+func (s *RefreshAccessPolicyClientsTestSuite) TestMalformedPoliciesAreSkipped() {
+	withoutNamespaceSelector := s.accessPolicy([]v1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}})
+	for _, networkPolicy := range []v1.NetworkPolicy{{}, s.accessPolicy(nil), withoutNamespaceSelector} {
+		s.Require().NoError(s.refresh(networkPolicy))
+	}
+}
+
+func TestRefreshAccessPolicyClientsTestSuite(t *testing.T) {
+	suite.Run(t, new(RefreshAccessPolicyClientsTestSuite))
+}
@@ -7,6 +7,7 @@ import (
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/shared/awsagent"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/samber/lo"
@@ -18,12 +19,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const awsBreakerComponent = "aws"
+
 type AWSIntentsReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	injectablerecorder.InjectableRecorder
 	serviceIdResolver serviceidresolver.ServiceResolver
 	awsAgent          *awsagent.Agent
+	breakers          *circuitbreaker.Registry
 }
 
 func NewAWSIntentsReconciler(
@@ -37,9 +41,16 @@ func NewAWSIntentsReconciler(
 		Client:            client,
 		Scheme:            scheme,
 		serviceIdResolver: serviceIdResolver,
+		breakers:          circuitbreaker.GlobalRegistry(),
 	}
 }
 
+// callAWS guards calls to AWS with a circuit breaker per AWS account, so that while AWS is unavailable calls fail fast
+// with a *circuitbreaker.OpenError, and the reconciler is only retried once the breaker allows it.
+func (r *AWSIntentsReconciler) callAWS(call func() error) (ctrl.Result, error) {
+	return ctrl.Result{}, r.breakers.Call(circuitbreaker.Key{Component: awsBreakerComponent, Target: r.awsAgent.AccountID()}, call)
+}
+
 func (r *AWSIntentsReconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
 	logger := logrus.WithField("namespace", req.Namespace).WithField("name", req.Name)
 
@@ -56,12 +67,9 @@ func (r *AWSIntentsReconciler) Reconcile(ctx context.Context, req reconcile.Requ
 	if intents.DeletionTimestamp != nil {
 		logger.Debug("Intents deleted, deleting IAM role policy for this service")
 
-		err := r.awsAgent.DeleteRolePolicyFromIntents(ctx, intents)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{}, nil
+		return r.callAWS(func() error {
+			return r.awsAgent.DeleteRolePolicyFromIntents(ctx, intents)
+		})
 	}
 
 	if intents.Spec == nil {
@@ -128,8 +136,9 @@ func (r *AWSIntentsReconciler) Reconcile(ctx context.Context, req reconcile.Requ
 		})
 	}
 
-	err = r.awsAgent.AddRolePolicy(ctx, req.Namespace, serviceAccountName, intents.Spec.Service.Name, policy.Statement)
-	return ctrl.Result{}, err
+	return r.callAWS(func() error {
+		return r.awsAgent.AddRolePolicy(ctx, req.Namespace, serviceAccountName, intents.Spec.Service.Name, policy.Statement)
+	})
 }
 
 func (r *AWSIntentsReconciler) hasMultipleClientsForServiceAccount(ctx context.Context, serviceAccountName string, namespace string) (bool, error) {
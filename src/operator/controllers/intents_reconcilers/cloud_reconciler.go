@@ -81,7 +81,8 @@ func (r *OtterizeCloudReconciler) convertK8sServicesToOtterizeIdentities(
 	clientIntentsList *otterizev1alpha3.ClientIntentsList) (*otterizev1alpha3.ClientIntentsList, error) {
 
 	// TODO: Remove when access graph supports Kubernetes services
-	for _, clientIntent := range clientIntentsList.Items {
+	for i := range clientIntentsList.Items {
+		clientIntent := &clientIntentsList.Items[i]
 		callList := make([]otterizev1alpha3.Intent, 0)
 		for _, intent := range clientIntent.GetCallsList() {
 			if !intent.IsTargetServerKubernetesService() {
@@ -114,8 +115,10 @@ func (r *OtterizeCloudReconciler) convertK8sServicesToOtterizeIdentities(
 				intent.Name = otterizeIdentity.Name
 				callList = append(callList, intent)
 			}
-			clientIntent.Spec.Calls = callList
 		}
+		// The default calls of the namespace are part of callList, so they aren't merged again when reported.
+		clientIntent.Spec.Calls = callList
+		clientIntent.SetDefaultCalls(nil)
 	}
 
 	return clientIntentsList, nil
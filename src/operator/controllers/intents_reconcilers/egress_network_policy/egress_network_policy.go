@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetriesgql"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
 	"github.com/samber/lo"
@@ -47,6 +50,11 @@ func NewEgressNetworkPolicyReconciler(
 	}
 }
 
+// DependsOn waits for the client pods to be labeled before creating their egress policies.
+func (r *EgressNetworkPolicyReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*intents_reconcilers.PodLabelReconciler)(nil)}
+}
+
 func (r *EgressNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	intents := &otterizev1alpha3.ClientIntents{}
 	err := r.Get(ctx, req.NamespacedName, intents)
@@ -160,6 +168,7 @@ func (r *EgressNetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Context
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, existingPolicy, policyCopy)
 	}
 
 	return nil
@@ -168,7 +177,12 @@ func (r *EgressNetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Context
 func (r *EgressNetworkPolicyReconciler) CreateNetworkPolicy(ctx context.Context, intentsObjNamespace string, intent otterizev1alpha3.Intent, newPolicy *v1.NetworkPolicy) error {
 	logrus.Infof(
 		"Creating network policy to enable access from namespace %s to %s", intentsObjNamespace, intent.Name)
-	return r.Create(ctx, newPolicy)
+	err := r.Create(ctx, newPolicy)
+	if err != nil {
+		return err
+	}
+	auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, newPolicy)
+	return nil
 }
 
 func (r *EgressNetworkPolicyReconciler) cleanPolicies(
@@ -244,7 +258,12 @@ func (r *EgressNetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx context.
 }
 
 func (r *EgressNetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
-	return r.Delete(ctx, &networkPolicy)
+	err := r.Delete(ctx, &networkPolicy)
+	if err != nil {
+		return err
+	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &networkPolicy)
+	return nil
 }
 
 func matchAccessNetworkPolicy() (labels.Selector, error) {
@@ -280,7 +299,7 @@ func (r *EgressNetworkPolicyReconciler) buildNetworkPolicyObjectForIntents(
 	intentsObj *otterizev1alpha3.ClientIntents, intent otterizev1alpha3.Intent, policyName string) *v1.NetworkPolicy {
 	// The intent's target server made of name + namespace + hash
 	formattedClient := otterizev1alpha3.GetFormattedOtterizeIdentity(intentsObj.GetServiceName(), intentsObj.Namespace)
-	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentity(intent.GetTargetServerName(), intent.GetTargetServerNamespace(intentsObj.Namespace))
+	formattedTargetServer := intent.GetFormattedTargetServerIdentity(intentsObj.Namespace)
 	podSelector := r.buildPodLabelSelectorFromIntents(intentsObj)
 	return &v1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -299,9 +318,7 @@ func (r *EgressNetworkPolicyReconciler) buildNetworkPolicyObjectForIntents(
 					To: []v1.NetworkPolicyPeer{
 						{
 							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									otterizev1alpha3.OtterizeServerLabelKey: formattedTargetServer,
-								},
+								MatchLabels: intent.GetTargetServerPodLabels(intentsObj.Namespace),
 							},
 							NamespaceSelector: &metav1.LabelSelector{
 								MatchLabels: map[string]string{
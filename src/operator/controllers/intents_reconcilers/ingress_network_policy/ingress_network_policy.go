@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
 	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetriesgql"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
 	"github.com/samber/lo"
@@ -24,6 +28,7 @@ import (
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 type externalNetpolHandler interface {
@@ -39,6 +44,7 @@ type NetworkPolicyReconciler struct {
 	enableNetworkPolicyCreation bool
 	enforcementDefaultState     bool
 	allowExternalTraffic        allowexternaltraffic.Enum
+	accessLabels                accesslabels.Enum
 	injectablerecorder.InjectableRecorder
 }
 
@@ -50,6 +56,7 @@ func NewNetworkPolicyReconciler(
 	enableNetworkPolicyCreation bool,
 	enforcementDefaultState bool,
 	allowExternalTraffic allowexternaltraffic.Enum,
+	accessLabels accesslabels.Enum,
 ) *NetworkPolicyReconciler {
 	return &NetworkPolicyReconciler{
 		Client:                      c,
@@ -59,9 +66,16 @@ func NewNetworkPolicyReconciler(
 		enableNetworkPolicyCreation: enableNetworkPolicyCreation,
 		enforcementDefaultState:     enforcementDefaultState,
 		allowExternalTraffic:        allowExternalTraffic,
+		accessLabels:                accessLabels,
 	}
 }
 
+// DependsOn makes the reconciler run only after client pods were labeled, so that policies take effect for
+// them as soon as they are created.
+func (r *NetworkPolicyReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*intents_reconcilers.PodLabelReconciler)(nil)}
+}
+
 func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	intents := &otterizev1alpha3.ClientIntents{}
 	err := r.Get(ctx, req.NamespacedName, intents)
@@ -94,7 +108,9 @@ func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	createdNetpols := 0
-	for _, intent := range intents.GetCallsList() {
+	// Only explicit calls get policies of their own, as only they are indexed by server. The DefaultClientIntents
+	// controller allows the default calls of the namespace for all of its clients.
+	for _, intent := range intents.Spec.Calls {
 		if intent.Type != "" && intent.Type != otterizev1alpha3.IntentTypeHTTP && intent.Type != otterizev1alpha3.IntentTypeKafka {
 			continue
 		}
@@ -124,7 +140,7 @@ func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	if createdNetpols != 0 {
-		callsCount := len(intents.GetCallsList())
+		callsCount := len(intents.Spec.Calls)
 		r.RecordNormalEventf(intents, consts.ReasonCreatedNetworkPolicies, "NetworkPolicy reconcile complete, reconciled %d servers", callsCount)
 		telemetrysender.SendIntentOperator(telemetriesgql.EventTypeNetworkPoliciesCreated, createdNetpols)
 		prometheus.IncrementNetpolCreated(createdNetpols)
@@ -153,9 +169,13 @@ func (r *NetworkPolicyReconciler) handleNetworkPolicyCreation(
 
 	logrus.Debugf("Server %s in namespace %s is in protected list: %t", intent.GetTargetServerName(), intent.GetTargetServerNamespace(intentsObjNamespace), shouldCreatePolicy)
 
-	policyName := fmt.Sprintf(otterizev1alpha3.OtterizeNetworkPolicyNameTemplate, intent.GetTargetServerName(), intentsObjNamespace)
+	policyName := networkPolicyName(intent, intentsObjNamespace)
 	existingPolicy := &v1.NetworkPolicy{}
-	newPolicy := r.buildNetworkPolicyObjectForIntent(intent, policyName, intentsObjNamespace)
+	clientIdentities, err := r.clientIdentitiesOfServer(ctx, intentsObj, intent, intentsObjNamespace)
+	if err != nil {
+		return false, err
+	}
+	newPolicy := r.buildNetworkPolicyObjectForIntent(intent, policyName, intentsObjNamespace, clientIdentities)
 	err = r.Get(ctx, types.NamespacedName{
 		Name:      policyName,
 		Namespace: intent.GetTargetServerNamespace(intentsObjNamespace)},
@@ -183,6 +203,7 @@ func (r *NetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Context, exis
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, existingPolicy, policyCopy)
 	}
 
 	return nil
@@ -195,6 +216,7 @@ func (r *NetworkPolicyReconciler) CreateNetworkPolicy(ctx context.Context, inten
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, newPolicy)
 
 	return r.reconcileEndpointsForPolicy(ctx, newPolicy)
 }
@@ -211,7 +233,7 @@ func (r *NetworkPolicyReconciler) reconcileEndpointsForPolicy(ctx context.Contex
 func (r *NetworkPolicyReconciler) cleanPolicies(
 	ctx context.Context, intents *otterizev1alpha3.ClientIntents) error {
 	logrus.Infof("Removing network policies for deleted intents for service: %s", intents.Spec.Service.Name)
-	for _, intent := range intents.GetCallsList() {
+	for _, intent := range intents.Spec.Calls {
 		if intent.Type != "" && intent.Type != otterizev1alpha3.IntentTypeHTTP && intent.Type != otterizev1alpha3.IntentTypeKafka {
 			continue
 		}
@@ -221,8 +243,8 @@ func (r *NetworkPolicyReconciler) cleanPolicies(
 		}
 	}
 
-	telemetrysender.SendIntentOperator(telemetriesgql.EventTypeNetworkPoliciesDeleted, len(intents.GetCallsList()))
-	prometheus.IncrementNetpolCreated(len(intents.GetCallsList()))
+	telemetrysender.SendIntentOperator(telemetriesgql.EventTypeNetworkPoliciesDeleted, len(intents.Spec.Calls))
+	prometheus.IncrementNetpolCreated(len(intents.Spec.Calls))
 
 	return nil
 }
@@ -260,9 +282,23 @@ func (r *NetworkPolicyReconciler) handleIntentRemoval(
 		if err = r.extNetpolHandler.HandlePodsByLabelSelector(ctx, intent.GetTargetServerNamespace(intentsObjNamespace), selector); err != nil {
 			return err
 		}
+		return nil
+	}
 
+	// Other clients in the namespace still call the server, so only the removed client should stop being allowed.
+	if !r.accessLabels.SelectsByClientIdentity() {
+		return nil
 	}
-	return nil
+	policyName := networkPolicyName(intent, intentsObjNamespace)
+	policy := &v1.NetworkPolicy{}
+	err = r.Get(ctx, types.NamespacedName{Name: policyName, Namespace: intent.GetTargetServerNamespace(intentsObjNamespace)}, policy)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return r.refreshPolicyClients(ctx, *policy)
 }
 
 func (r *NetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx context.Context) error {
@@ -301,12 +337,42 @@ func (r *NetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx context.Contex
 			if err != nil {
 				return err
 			}
+			continue
+		}
+
+		err = r.refreshPolicyClients(ctx, networkPolicy)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// clientIdentitiesOfServer returns the identities of the clients that call the intent's server from the namespace of
+// the intents, including the client of intentsObj, for policies that select clients by identity.
+func (r *NetworkPolicyReconciler) clientIdentitiesOfServer(
+	ctx context.Context, intentsObj *otterizev1alpha3.ClientIntents, intent otterizev1alpha3.Intent, intentsObjNamespace string) (sets.Set[intents_reconcilers.ClientIdentity], error) {
+	clientIdentities := sets.New(intents_reconcilers.ClientIdentityOf(intentsObj, intentsObjNamespace))
+	if !r.accessLabels.SelectsByClientIdentity() {
+		return clientIdentities, nil
+	}
+
+	formattedTargetServer := intent.GetFormattedTargetServerIdentity(intentsObjNamespace)
+	otherClientIdentities, err := intents_reconcilers.ClientIdentitiesOfServer(ctx, r.Client, formattedTargetServer, intentsObjNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return clientIdentities.Union(otherClientIdentities), nil
+}
+
+// refreshPolicyClients updates the clients allowed by a policy that selects clients by identity, after clients in
+// its namespace stopped calling the server while others still do.
+func (r *NetworkPolicyReconciler) refreshPolicyClients(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
+	serverName := networkPolicy.Labels[otterizev1alpha3.OtterizeNetworkPolicy]
+	return intents_reconcilers.RefreshAccessPolicyClients(ctx, r.Client, r.accessLabels, networkPolicy, serverName, fmt.Sprintf(otterizev1alpha3.OtterizeAccessLabelKey, serverName))
+}
+
 func (r *NetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
 	err := r.extNetpolHandler.HandleBeforeAccessPolicyRemoval(ctx, &networkPolicy)
 	if err != nil {
@@ -316,6 +382,7 @@ func (r *NetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, netwo
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &networkPolicy)
 	return nil
 }
 
@@ -332,10 +399,16 @@ func matchAccessNetworkPolicy() (labels.Selector, error) {
 		Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 		Operator: metav1.LabelSelectorOpDoesNotExist,
 	}
+	// Policies for the default calls of a namespace are managed by the DefaultClientIntents controller.
+	isNotDefaultIntentsPolicy := metav1.LabelSelectorRequirement{
+		Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+		Operator: metav1.LabelSelectorOpDoesNotExist,
+	}
 	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
 		isOtterizeNetworkPolicy,
 		isNotExternalTrafficPolicy,
 		isNotDefaultDenyPolicy,
+		isNotDefaultIntentsPolicy,
 	}})
 }
 
@@ -344,7 +417,7 @@ func (r *NetworkPolicyReconciler) deleteNetworkPolicy(
 	intent otterizev1alpha3.Intent,
 	intentsObjNamespace string) error {
 
-	policyName := fmt.Sprintf(otterizev1alpha3.OtterizeNetworkPolicyNameTemplate, intent.GetTargetServerName(), intentsObjNamespace)
+	policyName := networkPolicyName(intent, intentsObjNamespace)
 	policy := &v1.NetworkPolicy{}
 	err := r.Get(ctx, types.NamespacedName{Name: policyName, Namespace: intent.GetTargetServerNamespace(intentsObjNamespace)}, policy)
 	if err != nil {
@@ -421,10 +494,10 @@ func (r *NetworkPolicyReconciler) CleanAllNamespaces(ctx context.Context) error
 
 // buildNetworkPolicyObjectForIntent builds the network policy that represents the intent from the parameter
 func (r *NetworkPolicyReconciler) buildNetworkPolicyObjectForIntent(
-	intent otterizev1alpha3.Intent, policyName, intentsObjNamespace string) *v1.NetworkPolicy {
+	intent otterizev1alpha3.Intent, policyName, intentsObjNamespace string, clientIdentities sets.Set[intents_reconcilers.ClientIdentity]) *v1.NetworkPolicy {
 	targetNamespace := intent.GetTargetServerNamespace(intentsObjNamespace)
 	// The intent's target server made of name + namespace + hash
-	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentity(intent.GetTargetServerName(), targetNamespace)
+	formattedTargetServer := intent.GetFormattedTargetServerIdentity(intentsObjNamespace)
 	podSelector := r.buildPodLabelSelectorFromIntent(intent, intentsObjNamespace)
 	return &v1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -439,21 +512,12 @@ func (r *NetworkPolicyReconciler) buildNetworkPolicyObjectForIntent(
 			PodSelector: podSelector,
 			Ingress: []v1.NetworkPolicyIngressRule{
 				{
-					From: []v1.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									fmt.Sprintf(
-										otterizev1alpha3.OtterizeAccessLabelKey, formattedTargetServer): "true",
-								},
-							},
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: intentsObjNamespace,
-								},
-							},
-						},
-					},
+					From: intents_reconcilers.AccessPolicyPeers(
+						r.accessLabels,
+						fmt.Sprintf(otterizev1alpha3.OtterizeAccessLabelKey, formattedTargetServer),
+						intentsObjNamespace,
+						clientIdentities,
+					),
 				},
 			},
 		},
@@ -461,13 +525,17 @@ func (r *NetworkPolicyReconciler) buildNetworkPolicyObjectForIntent(
 }
 
 func (r *NetworkPolicyReconciler) buildPodLabelSelectorFromIntent(intent otterizev1alpha3.Intent, intentsObjNamespace string) metav1.LabelSelector {
-	targetNamespace := intent.GetTargetServerNamespace(intentsObjNamespace)
-	// The intent's target server made of name + namespace + hash
-	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentity(intent.GetTargetServerName(), targetNamespace)
-
 	return metav1.LabelSelector{
-		MatchLabels: map[string]string{
-			otterizev1alpha3.OtterizeServerLabelKey: formattedTargetServer,
-		},
+		MatchLabels: intent.GetTargetServerPodLabels(intentsObjNamespace),
+	}
+}
+
+// networkPolicyName names the policy for the intent's server. Servers of a specific kind have policies of their own,
+// since they select other pods than the policy for servers of any kind with the same name.
+func networkPolicyName(intent otterizev1alpha3.Intent, intentsObjNamespace string) string {
+	serverName := intent.GetTargetServerName()
+	if intent.Kind != "" {
+		serverName = fmt.Sprintf("%s.%s", serverName, strings.ToLower(intent.Kind))
 	}
+	return fmt.Sprintf(otterizev1alpha3.OtterizeNetworkPolicyNameTemplate, serverName, intentsObjNamespace)
 }
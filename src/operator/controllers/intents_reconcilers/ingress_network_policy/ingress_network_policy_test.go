@@ -7,6 +7,7 @@ import (
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
 	"github.com/otterize/intents-operator/src/shared/testbase"
 	"github.com/sirupsen/logrus"
@@ -58,6 +59,7 @@ func (s *NetworkPolicyReconcilerTestSuite) SetupTest() {
 		true,
 		true,
 		allowexternaltraffic.IfBlockedByOtterize,
+		accesslabels.PerServer,
 	)
 
 	s.Reconciler.Recorder = s.Recorder
@@ -345,6 +347,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestRemoveOrphanNetworkPolicy() {
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -567,6 +573,10 @@ func (s *NetworkPolicyReconcilerTestSuite) ignoreRemoveOrphan() {
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -878,6 +888,265 @@ func (s *NetworkPolicyReconcilerTestSuite) TestPolicyNotDeletedForTwoClientsWith
 	s.Empty(res)
 }
 
+func (s *NetworkPolicyReconcilerTestSuite) TestCreateNetworkPolicyMigratingAccessLabels() {
+	s.Reconciler.accessLabels = accesslabels.Migrating
+	clientIntentsName := "client-intents"
+	policyName := "access-to-test-server-from-test-namespace"
+	serviceName := "test-client"
+	formattedTargetServer := "test-server-test-namespace-8ddecb"
+
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: clientIntentsName},
+	}
+	intentsSpec := &otterizev1alpha3.IntentsSpec{
+		Service: otterizev1alpha3.Service{Name: serviceName},
+		Calls:   []otterizev1alpha3.Intent{{Name: "test-server"}},
+	}
+	s.Client.EXPECT().Get(gomock.Any(), req.NamespacedName, gomock.Eq(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, intents *otterizev1alpha3.ClientIntents, options ...client.ListOption) error {
+			intents.Spec = intentsSpec
+			return nil
+		})
+
+	// Other clients in the namespace that call the server are allowed by the same policy
+	otherClientIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-client-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "other-client"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "test-server"}},
+		},
+	}
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.Eq(&otterizev1alpha3.ClientIntentsList{}),
+		&client.MatchingFields{otterizev1alpha3.OtterizeFormattedTargetServerIndexField: formattedTargetServer},
+		&client.ListOptions{Namespace: testNamespace},
+	).DoAndReturn(func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, opts ...client.ListOption) error {
+		list.Items = []otterizev1alpha3.ClientIntents{otherClientIntents}
+		return nil
+	})
+
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: testNamespace, Name: policyName}, gomock.Eq(&v1.NetworkPolicy{})).Return(
+		apierrors.NewNotFound(v1.Resource("networkpolicy"), policyName))
+
+	// Clients are selected both by their access labels and by their identity
+	newPolicy := networkPolicyTemplate(policyName, testNamespace, formattedTargetServer, testNamespace)
+	identityPeer := newPolicy.Spec.Ingress[0].From[0]
+	identityPeer.PodSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      otterizev1alpha3.OtterizeServerLabelKey,
+			Operator: metav1.LabelSelectorOpIn,
+			Values: []string{
+				otterizev1alpha3.GetFormattedOtterizeIdentity("other-client", testNamespace),
+				otterizev1alpha3.GetFormattedOtterizeIdentity(serviceName, testNamespace),
+			},
+		}},
+	}
+	newPolicy.Spec.Ingress[0].From = append(newPolicy.Spec.Ingress[0].From, identityPeer)
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(newPolicy)).Return(nil)
+
+	s.externalNetpolHandler.EXPECT().HandlePodsByLabelSelector(gomock.Any(), testNamespace, gomock.Any())
+	s.ignoreRemoveOrphan()
+
+	res, err := s.Reconciler.Reconcile(context.Background(), req)
+	s.NoError(err)
+	s.Empty(res)
+	s.ExpectEvent(consts.ReasonCreatedNetworkPolicies)
+}
+
+func (s *NetworkPolicyReconcilerTestSuite) TestCreateNetworkPolicyForClientKindsSharingName() {
+	s.Reconciler.accessLabels = accesslabels.ClientIdentity
+	clientIntentsName := "client-intents"
+	policyName := "access-to-test-server-from-test-namespace"
+	formattedTargetServer := "test-server-test-namespace-8ddecb"
+
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: clientIntentsName},
+	}
+	clientIntentsObj := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: clientIntentsName, Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "test-client", Kind: "Deployment"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "test-server"}},
+		},
+	}
+	s.Client.EXPECT().Get(gomock.Any(), req.NamespacedName, gomock.Eq(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, intents *otterizev1alpha3.ClientIntents, options ...client.ListOption) error {
+			clientIntentsObj.DeepCopyInto(intents)
+			return nil
+		})
+
+	// A CronJob with the same name also calls the server, while a StatefulSet with that name doesn't
+	cronJobClientIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "cronjob-client-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "test-client", Kind: "CronJob"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "test-server"}},
+		},
+	}
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.Eq(&otterizev1alpha3.ClientIntentsList{}),
+		&client.MatchingFields{otterizev1alpha3.OtterizeFormattedTargetServerIndexField: formattedTargetServer},
+		&client.ListOptions{Namespace: testNamespace},
+	).DoAndReturn(func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, opts ...client.ListOption) error {
+		list.Items = []otterizev1alpha3.ClientIntents{clientIntentsObj, cronJobClientIntents}
+		return nil
+	})
+
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: testNamespace, Name: policyName}, gomock.Eq(&v1.NetworkPolicy{})).Return(
+		apierrors.NewNotFound(v1.Resource("networkpolicy"), policyName))
+
+	// Each kind is selected by its owner kind label, so that the pods of the StatefulSet aren't allowed
+	newPolicy := networkPolicyTemplate(policyName, testNamespace, formattedTargetServer, testNamespace)
+	kindPeer := func(kind string) v1.NetworkPolicyPeer {
+		peer := *newPolicy.Spec.Ingress[0].From[0].DeepCopy()
+		peer.PodSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				otterizev1alpha3.OtterizeServerLabelKey:    otterizev1alpha3.GetFormattedOtterizeIdentity("test-client", testNamespace),
+				otterizev1alpha3.OtterizeOwnerKindLabelKey: kind,
+			},
+		}
+		return peer
+	}
+	newPolicy.Spec.Ingress[0].From = []v1.NetworkPolicyPeer{kindPeer("CronJob"), kindPeer("Deployment")}
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(newPolicy)).Return(nil)
+
+	s.externalNetpolHandler.EXPECT().HandlePodsByLabelSelector(gomock.Any(), testNamespace, gomock.Any())
+	s.ignoreRemoveOrphan()
+
+	res, err := s.Reconciler.Reconcile(context.Background(), req)
+	s.NoError(err)
+	s.Empty(res)
+	s.ExpectEvent(consts.ReasonCreatedNetworkPolicies)
+}
+
+func (s *NetworkPolicyReconcilerTestSuite) TestCreateNetworkPolicyForServerKind() {
+	clientIntentsName := "client-intents"
+	policyName := "access-to-test-server.statefulset-from-test-namespace"
+	serviceName := "test-client"
+	intent := otterizev1alpha3.Intent{Name: "test-server", Kind: "StatefulSet"}
+	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentityWithKind("test-server", testNamespace, "StatefulSet")
+	s.Require().NotEqual("test-server-test-namespace-8ddecb", formattedTargetServer)
+
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: clientIntentsName},
+	}
+	intentsSpec := &otterizev1alpha3.IntentsSpec{
+		Service: otterizev1alpha3.Service{Name: serviceName},
+		Calls:   []otterizev1alpha3.Intent{intent},
+	}
+	s.Client.EXPECT().Get(gomock.Any(), req.NamespacedName, gomock.Eq(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, intents *otterizev1alpha3.ClientIntents, options ...client.ListOption) error {
+			intents.Spec = intentsSpec
+			return nil
+		})
+
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: testNamespace, Name: policyName}, gomock.Eq(&v1.NetworkPolicy{})).Return(
+		apierrors.NewNotFound(v1.Resource("networkpolicy"), policyName))
+
+	// The policy only selects the pods of the server of that kind, and is labeled with an identity of its own
+	newPolicy := networkPolicyTemplate(policyName, testNamespace, formattedTargetServer, testNamespace)
+	newPolicy.Spec.PodSelector = metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			otterizev1alpha3.OtterizeServerLabelKey:    "test-server-test-namespace-8ddecb",
+			otterizev1alpha3.OtterizeOwnerKindLabelKey: "StatefulSet",
+		},
+	}
+	s.Client.EXPECT().Create(gomock.Any(), gomock.Eq(newPolicy)).Return(nil)
+
+	selector := labels.SelectorFromSet(labels.Set(newPolicy.Spec.PodSelector.MatchLabels))
+	s.externalNetpolHandler.EXPECT().HandlePodsByLabelSelector(gomock.Any(), testNamespace, selector)
+	s.ignoreRemoveOrphan()
+
+	res, err := s.Reconciler.Reconcile(context.Background(), req)
+	s.NoError(err)
+	s.Empty(res)
+	s.ExpectEvent(consts.ReasonCreatedNetworkPolicies)
+}
+
+func (s *NetworkPolicyReconcilerTestSuite) TestPolicyClientsUpdatedForTwoClientsWithSameServer() {
+	s.Reconciler.accessLabels = accesslabels.ClientIdentity
+	clientIntentsName := "client-intents"
+	policyName := "access-to-test-server-from-test-namespace"
+	serverName := "test-server"
+	formattedTargetServer := "test-server-test-namespace-8ddecb"
+
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: clientIntentsName},
+	}
+	clientIntentsObj := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              clientIntentsName,
+			Namespace:         testNamespace,
+			DeletionTimestamp: &metav1.Time{Time: time.Date(2020, 12, 1, 17, 14, 0, 0, time.UTC)},
+		},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "test-client"},
+			Calls:   []otterizev1alpha3.Intent{{Name: serverName}},
+		},
+	}
+	s.Client.EXPECT().Get(gomock.Any(), req.NamespacedName, gomock.Eq(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, intents *otterizev1alpha3.ClientIntents, options ...client.ListOption) error {
+			clientIntentsObj.DeepCopyInto(intents)
+			return nil
+		})
+
+	otherClientIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-client-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "other-client"},
+			Calls:   []otterizev1alpha3.Intent{{Name: serverName}},
+		},
+	}
+	intentsList := otterizev1alpha3.ClientIntentsList{Items: []otterizev1alpha3.ClientIntents{clientIntentsObj, otherClientIntents}}
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.Eq(&otterizev1alpha3.ClientIntentsList{}),
+		&client.MatchingFields{otterizev1alpha3.OtterizeTargetServerIndexField: serverName + "." + testNamespace},
+		&client.ListOptions{Namespace: testNamespace},
+	).DoAndReturn(func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, opts ...client.ListOption) error {
+		intentsList.DeepCopyInto(list)
+		return nil
+	})
+
+	// The policy still allows the deleted client, and should only allow the other one
+	clientsSelector := func(clients ...string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      otterizev1alpha3.OtterizeServerLabelKey,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   clients,
+		}}}
+	}
+	existingPolicy := networkPolicyTemplate(policyName, testNamespace, formattedTargetServer, testNamespace)
+	existingPolicy.Spec.Ingress[0].From[0].PodSelector = clientsSelector(
+		otterizev1alpha3.GetFormattedOtterizeIdentity("other-client", testNamespace),
+		otterizev1alpha3.GetFormattedOtterizeIdentity("test-client", testNamespace),
+	)
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: testNamespace, Name: policyName}, gomock.Eq(&v1.NetworkPolicy{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, networkPolicy *v1.NetworkPolicy, options ...client.ListOption) error {
+			existingPolicy.DeepCopyInto(networkPolicy)
+			return nil
+		})
+	s.Client.EXPECT().List(
+		gomock.Any(),
+		gomock.Eq(&otterizev1alpha3.ClientIntentsList{}),
+		&client.MatchingFields{otterizev1alpha3.OtterizeFormattedTargetServerIndexField: formattedTargetServer},
+		&client.ListOptions{Namespace: testNamespace},
+	).DoAndReturn(func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, opts ...client.ListOption) error {
+		intentsList.DeepCopyInto(list)
+		return nil
+	})
+
+	updatedPolicy := existingPolicy.DeepCopy()
+	updatedPolicy.Spec.Ingress[0].From[0].PodSelector = clientsSelector(otterizev1alpha3.GetFormattedOtterizeIdentity("other-client", testNamespace))
+	s.Client.EXPECT().Patch(gomock.Any(), gomock.Eq(updatedPolicy), intents_reconcilers.MatchPatch(client.MergeFrom(existingPolicy))).Return(nil)
+
+	res, err := s.Reconciler.Reconcile(context.Background(), req)
+	s.NoError(err)
+	s.Empty(res)
+}
+
 func (s *NetworkPolicyReconcilerTestSuite) TestAllServerAreProtected() {
 	s.Reconciler.enforcementDefaultState = false
 	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
@@ -893,6 +1162,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestAllServerAreProtected() {
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -1028,6 +1301,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestUnprotectedServerWithAccessPolicy
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -1156,6 +1433,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestProtectedServiceInDeletionWithAcc
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -1296,6 +1577,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestServerWithoutPolicyNothingShouldH
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -1395,6 +1680,10 @@ func (s *NetworkPolicyReconcilerTestSuite) TestNoNetworkPolicies() {
 			Key:      otterizev1alpha3.OtterizeNetworkPolicyServiceDefaultDeny,
 			Operator: metav1.LabelSelectorOpDoesNotExist,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 	// Get all existing network policies
@@ -2,11 +2,14 @@ package intents_reconcilers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"github.com/Shopify/sarama"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/sirupsen/logrus"
@@ -26,6 +29,9 @@ const (
 	ReasonApplyingKafkaACLsFailed              = "ApplyingKafkaACLsFailed"
 	ReasonAppliedKafkaACLs                     = "AppliedKafkaACLs"
 	ReasonIntentsOperatorIdentityResolveFailed = "IntentsOperatorIdentityResolveFailed"
+	ReasonKafkaServerCircuitBreakerOpen        = "KafkaServerCircuitBreakerOpen"
+
+	kafkaServerBreakerComponent = "kafka-server"
 )
 
 type KafkaACLReconciler struct {
@@ -38,6 +44,7 @@ type KafkaACLReconciler struct {
 	operatorPodName         string
 	operatorPodNamespace    string
 	serviceResolver         serviceidresolver.ServiceResolver
+	breakers                *circuitbreaker.Registry
 	injectablerecorder.InjectableRecorder
 }
 
@@ -62,6 +69,7 @@ func NewKafkaACLReconciler(
 		operatorPodName:         operatorPodName,
 		operatorPodNamespace:    operatorPodNamespace,
 		serviceResolver:         serviceResolver,
+		breakers:                circuitbreaker.GlobalRegistry(),
 	}
 }
 
@@ -83,10 +91,64 @@ func getIntentsByServer(defaultNamespace string, intents []otterizev1alpha3.Inte
 	return intentsByServer
 }
 
+// kafkaServerUnavailableError marks a failure to reach a Kafka server, as opposed to a failure applying a specific
+// client's ACLs on it. Only these failures count against the server's circuit breaker.
+type kafkaServerUnavailableError struct {
+	err error
+}
+
+func (e *kafkaServerUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kafkaServerUnavailableError) Unwrap() error {
+	return e.err
+}
+
+func isKafkaServerUnavailable(err error) bool {
+	var unavailableErr *kafkaServerUnavailableError
+	return goerrors.As(err, &unavailableErr) ||
+		goerrors.Is(err, sarama.ErrOutOfBrokers) ||
+		goerrors.Is(err, sarama.ErrNotConnected) ||
+		goerrors.Is(err, sarama.ErrClosedClient) ||
+		goerrors.Is(err, sarama.ErrBrokerNotAvailable)
+}
+
+// forEachServer runs f for every configured Kafka server, so that a single failing server does not block ACLs on the
+// others. Servers that cannot be reached trip their circuit breaker and are skipped until it closes; other errors are
+// returned without affecting the breaker, since they are specific to the client being reconciled.
+func (r *KafkaACLReconciler) forEachServer(f func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error) error {
+	errs := make([]error, 0)
+	_ = r.KafkaServersStore.MapErr(func(serverName types.NamespacedName, config *otterizev1alpha3.KafkaServerConfig, tls otterizev1alpha3.TLSSource) error {
+		key := circuitbreaker.Key{Component: kafkaServerBreakerComponent, Target: serverName.String()}
+		var clientErr error
+		err := r.breakers.Call(key, func() error {
+			err := f(serverName, config, tls)
+			if err != nil && !isKafkaServerUnavailable(err) {
+				clientErr = err
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if clientErr != nil {
+			errs = append(errs, clientErr)
+		}
+		return nil
+	})
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return goerrors.Join(errs...)
+}
+
 func (r *KafkaACLReconciler) applyACLs(ctx context.Context, intents *otterizev1alpha3.ClientIntents) (serverCount int, err error) {
-	intentsByServer := getIntentsByServer(intents.Namespace, intents.Spec.Calls)
+	intentsByServer := getIntentsByServer(intents.Namespace, intents.GetCallsList())
 
-	if err := r.KafkaServersStore.MapErr(func(serverName types.NamespacedName, config *otterizev1alpha3.KafkaServerConfig, tls otterizev1alpha3.TLSSource) error {
+	err = r.forEachServer(func(serverName types.NamespacedName, config *otterizev1alpha3.KafkaServerConfig, tls otterizev1alpha3.TLSSource) error {
 		intentsForServer := intentsByServer[serverName]
 		shouldCreatePolicy, err := protected_services.IsServerEnforcementEnabledDueToProtectionOrDefaultState(ctx, r.client, serverName.Name, serverName.Namespace, r.enforcementDefaultState)
 		if err != nil {
@@ -102,15 +164,16 @@ func (r *KafkaACLReconciler) applyACLs(ctx context.Context, intents *otterizev1a
 		if err != nil {
 			err = fmt.Errorf("failed to connect to Kafka server %s: %w", serverName, err)
 			r.RecordWarningEventf(intents, ReasonCouldNotConnectToKafkaServer, "Kafka ACL reconcile failed: %s", err.Error())
-			return err
+			return &kafkaServerUnavailableError{err: err}
 		}
 		defer kafkaIntentsAdmin.Close()
-		if err := kafkaIntentsAdmin.ApplyClientIntents(intents.Spec.Service.Name, intents.Namespace, intentsForServer); err != nil {
+		if err := kafkaIntentsAdmin.ApplyClientIntents(ctx, intents.Spec.Service.Name, intents.Namespace, intentsForServer); err != nil {
 			r.RecordWarningEventf(intents, ReasonCouldNotApplyIntentsOnKafkaServer, "Kafka ACL reconcile failed: %s", err.Error())
 			return fmt.Errorf("failed applying intents on kafka server %s: %w", serverName, err)
 		}
 		return nil
-	}); err != nil {
+	})
+	if err != nil {
 		return 0, err
 	}
 
@@ -129,7 +192,7 @@ func (r *KafkaACLReconciler) applyACLs(ctx context.Context, intents *otterizev1a
 }
 
 func (r *KafkaACLReconciler) RemoveACLs(ctx context.Context, intents *otterizev1alpha3.ClientIntents) error {
-	return r.KafkaServersStore.MapErr(func(serverName types.NamespacedName, config *otterizev1alpha3.KafkaServerConfig, tls otterizev1alpha3.TLSSource) error {
+	return r.forEachServer(func(serverName types.NamespacedName, config *otterizev1alpha3.KafkaServerConfig, tls otterizev1alpha3.TLSSource) error {
 		shouldCreatePolicy, err := protected_services.IsServerEnforcementEnabledDueToProtectionOrDefaultState(ctx, r.client, serverName.Name, serverName.Namespace, r.enforcementDefaultState)
 		if err != nil {
 			return err
@@ -138,11 +201,11 @@ func (r *KafkaACLReconciler) RemoveACLs(ctx context.Context, intents *otterizev1
 		// We just pass shouldCreatePolicy to the KafkaIntentsAdmin - it determines whether to create or delete.
 		kafkaIntentsAdmin, err := r.getNewKafkaIntentsAdmin(*config, tls, r.enableKafkaACLCreation, shouldCreatePolicy)
 		if err != nil {
-			return err
+			return &kafkaServerUnavailableError{err: fmt.Errorf("failed to connect to Kafka server %s: %w", serverName, err)}
 		}
 		defer kafkaIntentsAdmin.Close()
 
-		if err := kafkaIntentsAdmin.RemoveClientIntents(intents.Spec.Service.Name, intents.Namespace); err != nil {
+		if err := kafkaIntentsAdmin.RemoveClientIntents(ctx, intents.Spec.Service.Name, intents.Namespace); err != nil {
 			return fmt.Errorf("failed removing intents from kafka server %s: %w", serverName, err)
 		}
 		return nil
@@ -179,14 +242,7 @@ func (r *KafkaACLReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, nil
 	}
 
-	var result ctrl.Result
-	result, err = r.applyAcls(ctx, logger, intents)
-	if err != nil {
-		return result, err
-	}
-
-	return ctrl.Result{}, nil
-
+	return r.applyAcls(ctx, logger, intents)
 }
 
 func (r *KafkaACLReconciler) isIntentsForTheIntentsOperator(ctx context.Context, intents *otterizev1alpha3.ClientIntents) (bool, error) {
@@ -210,6 +266,11 @@ func (r *KafkaACLReconciler) isIntentsForTheIntentsOperator(ctx context.Context,
 func (r *KafkaACLReconciler) applyAcls(ctx context.Context, logger *logrus.Entry, intents *otterizev1alpha3.ClientIntents) (ctrl.Result, error) {
 	logger.Info("Applying new ACLs")
 	serverCount, err := r.applyACLs(ctx, intents)
+	var openErr *circuitbreaker.OpenError
+	if goerrors.As(err, &openErr) {
+		r.RecordWarningEventf(intents, ReasonKafkaServerCircuitBreakerOpen, "Some Kafka servers keep failing, retrying in %s: %s", openErr.RetryAfter, err.Error())
+		return ctrl.Result{}, err
+	}
 	if err != nil {
 		r.RecordWarningEventf(intents, ReasonApplyingKafkaACLsFailed, "could not apply Kafka ACLs: %s", err.Error())
 		return ctrl.Result{}, err
@@ -227,7 +288,8 @@ func (r *KafkaACLReconciler) intentsObjectUnderDeletion(intents *otterizev1alpha
 
 func (r *KafkaACLReconciler) handleIntentsDeletion(ctx context.Context, intents *otterizev1alpha3.ClientIntents, logger *logrus.Entry) (ctrl.Result, error) {
 	logger.Infof("Removing associated Acls")
-	if err := r.RemoveACLs(ctx, intents); err != nil {
+	err := r.RemoveACLs(ctx, intents)
+	if err != nil {
 		r.RecordWarningEventf(intents, ReasonRemovingKafkaACLsFailed, "Could not remove Kafka ACLs: %s", err.Error())
 		return ctrl.Result{}, err
 	}
@@ -11,6 +11,7 @@ import (
 	intentsreconcilersmocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
 	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
 	"github.com/otterize/intents-operator/src/shared/testbase"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,7 @@ import (
 	"go.uber.org/mock/gomock"
 	istiosecurityscheme "istio.io/client-go/pkg/apis/security/v1beta1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -27,6 +29,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"testing"
+	"time"
 )
 
 const (
@@ -87,7 +90,8 @@ func (s *KafkaACLReconcilerTestSuite) setupServerStore(serviceName string) *kafk
 
 	serverConfig.SetNamespace(s.TestNamespace)
 	emptyTls := otterizev1alpha3.TLSSource{}
-	kafkaServersStore := kafkaacls.NewServersStore(emptyTls, true, kafkaacls.NewKafkaIntentsAdmin, true)
+	kafkaAdminPool := kafkaacls.NewAdminPool(nil, kafkaacls.NewStatusTracker())
+	kafkaServersStore := kafkaacls.NewServersStore(emptyTls, true, kafkaAdminPool.NewKafkaIntentsAdmin, true, kafkaAdminPool)
 	kafkaServersStore.Add(serverConfig)
 	return kafkaServersStore
 }
@@ -102,6 +106,8 @@ func (s *KafkaACLReconcilerTestSuite) BeforeTest(_, testName string) {
 	controller := gomock.NewController(s.T())
 	s.mockKafkaAdmin = kafkaaclsmocks.NewMockClusterAdmin(controller)
 	s.mockServiceResolver = intentsreconcilersmocks.NewMockServiceResolver(controller)
+	// None of these tests set client quotas.
+	s.mockKafkaAdmin.EXPECT().DescribeClientQuotas(gomock.Any(), true).Return(nil, nil).AnyTimes()
 
 	s.initKafkaIntentsAdmin(true, true)
 }
@@ -130,7 +136,7 @@ func (s *KafkaACLReconcilerTestSuite) principal() string {
 
 func getMockIntentsAdminFactory(clusterAdmin sarama.ClusterAdmin, usernameMapping string) kafkaacls.IntentsAdminFactoryFunction {
 	return func(kafkaServer otterizev1alpha3.KafkaServerConfig, _ otterizev1alpha3.TLSSource, enableKafkaACLCreation bool, enforcementDefaultState bool) (kafkaacls.KafkaIntentsAdmin, error) {
-		return kafkaacls.NewKafkaIntentsAdminImpl(kafkaServer, clusterAdmin, usernameMapping, enableKafkaACLCreation, enforcementDefaultState), nil
+		return kafkaacls.NewKafkaIntentsAdminImpl(kafkaServer, clusterAdmin, usernameMapping, enableKafkaACLCreation, enforcementDefaultState, kafkaacls.NewStatusTracker()), nil
 	}
 }
 
@@ -413,3 +419,52 @@ func (s *KafkaACLReconcilerTestSuite) generateIntents(operation otterizev1alpha3
 func TestKafkaACLReconcilerTestSuite(t *testing.T) {
 	suite.Run(t, new(KafkaACLReconcilerTestSuite))
 }
+
+type KafkaACLServerBreakerTestSuite struct {
+	suite.Suite
+	reconciler *KafkaACLReconciler
+	serverKey  circuitbreaker.Key
+}
+
+func (s *KafkaACLServerBreakerTestSuite) SetupTest() {
+	serversStore := kafkaacls.NewServersStore(otterizev1alpha3.TLSSource{}, true, nil, true, kafkaacls.NewAdminPool(nil, kafkaacls.NewStatusTracker()))
+	serversStore.Add(&otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServiceName, Namespace: "kafka-ns"},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: kafkaServiceName}},
+	})
+	s.reconciler = &KafkaACLReconciler{
+		KafkaServersStore: serversStore,
+		breakers:          circuitbreaker.NewRegistry(1, time.Minute, time.Minute),
+	}
+	s.serverKey = circuitbreaker.Key{Component: kafkaServerBreakerComponent, Target: types.NamespacedName{Name: kafkaServiceName, Namespace: "kafka-ns"}.String()}
+}
+
+func (s *KafkaACLServerBreakerTestSuite) TestClientErrorsDoNotOpenBreaker() {
+	clientErr := sarama.ErrTopicAuthorizationFailed
+	err := s.reconciler.forEachServer(func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error {
+		return clientErr
+	})
+	s.Require().ErrorIs(err, clientErr)
+	s.Require().False(s.reconciler.breakers.IsOpen(s.serverKey))
+}
+
+func (s *KafkaACLServerBreakerTestSuite) TestUnavailableServerOpensBreaker() {
+	err := s.reconciler.forEachServer(func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error {
+		return &kafkaServerUnavailableError{err: sarama.ErrOutOfBrokers}
+	})
+	var openErr *circuitbreaker.OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Require().True(s.reconciler.breakers.IsOpen(s.serverKey))
+
+	called := false
+	err = s.reconciler.forEachServer(func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error {
+		called = true
+		return nil
+	})
+	s.Require().ErrorAs(err, &openErr)
+	s.Require().False(called)
+}
+
+func TestKafkaACLServerBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaACLServerBreakerTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/prometheus"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -22,14 +23,16 @@ const (
 
 type PodLabelReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme       *runtime.Scheme
+	accessLabels accesslabels.Enum
 	injectablerecorder.InjectableRecorder
 }
 
-func NewPodLabelReconciler(c client.Client, s *runtime.Scheme) *PodLabelReconciler {
+func NewPodLabelReconciler(c client.Client, s *runtime.Scheme, accessLabels accesslabels.Enum) *PodLabelReconciler {
 	return &PodLabelReconciler{
-		Client: c,
-		Scheme: s,
+		Client:       c,
+		Scheme:       s,
+		accessLabels: accessLabels,
 	}
 }
 
@@ -62,6 +65,10 @@ func (r *PodLabelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	serviceName := intents.GetServiceName()
 	intentLabels := intents.GetIntentsLabelMapping(namespace)
+	if !r.accessLabels.LabelsPods() {
+		// Policies select the pods by their identity, so existing access labels are removed.
+		intentLabels = map[string]string{}
+	}
 
 	// List the pods in the namespace and update labels if required
 	labelSelector, err := intents.BuildPodLabelSelector()
@@ -79,7 +86,7 @@ func (r *PodLabelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	for _, pod := range podList.Items {
-		if otterizev1alpha3.IsMissingOtterizeAccessLabels(&pod, intentLabels) {
+		if otterizev1alpha3.IsMissingOtterizeAccessLabels(&pod, intentLabels) || !otterizev1alpha3.HasOtterizeClientLabel(&pod, serviceName) {
 			logrus.Infof("Updating %s pod labels with new intents", serviceName)
 			updatedPod := otterizev1alpha3.UpdateOtterizeAccessLabels(pod.DeepCopy(), serviceName, intentLabels)
 			err := r.Patch(ctx, updatedPod, client.MergeFrom(&pod))
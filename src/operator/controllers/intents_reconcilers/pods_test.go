@@ -5,6 +5,7 @@ import (
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/testbase"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -30,7 +31,7 @@ type PodLabelReconcilerTestSuite struct {
 func (s *PodLabelReconcilerTestSuite) SetupTest() {
 	s.MocksSuiteBase.SetupTest()
 	s.Client = mocks.NewMockClient(s.Controller)
-	s.Reconciler = NewPodLabelReconciler(s.Client, nil)
+	s.Reconciler = NewPodLabelReconciler(s.Client, nil, accesslabels.PerServer)
 	s.Reconciler.Recorder = s.Recorder
 }
 
@@ -111,6 +112,48 @@ func (s *PodLabelReconcilerTestSuite) TestClientAccessLabelAdded() {
 	s.Empty(res)
 }
 
+func (s *PodLabelReconcilerTestSuite) TestClientAccessLabelsRemovedForClientIdentityScheme() {
+	s.Reconciler.accessLabels = accesslabels.ClientIdentity
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "client-intents"},
+	}
+	intentsSpec := otterizev1alpha3.IntentsSpec{
+		Service: otterizev1alpha3.Service{Name: "test-client"},
+		Calls:   []otterizev1alpha3.Intent{{Name: "test-server"}},
+	}
+	s.Client.EXPECT().Get(gomock.Any(), req.NamespacedName, gomock.Eq(&otterizev1alpha3.ClientIntents{})).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, intents *otterizev1alpha3.ClientIntents, options ...client.ListOption) error {
+			intents.Spec = &intentsSpec
+			intents.Namespace = testNamespace
+			return nil
+		})
+
+	// The pod was labeled before the scheme changed, and keeps only its client label
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				"intents.otterize.com/access-test-server-test-namespace-8ddecb": "true",
+				"intents.otterize.com/client":                                   "test-client-test-namespace-537e87",
+			},
+		},
+	}
+	s.Client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pds *v1.PodList, opts ...client.ListOption) error {
+			pds.Items = append(pds.Items, pod)
+			return nil
+		})
+
+	updatedPod := pod.DeepCopy()
+	updatedPod.Labels = map[string]string{"intents.otterize.com/client": "test-client-test-namespace-537e87"}
+	s.Client.EXPECT().Patch(gomock.Any(), gomock.Eq(updatedPod), gomock.Any()).Return(nil)
+
+	res, err := s.Reconciler.Reconcile(context.Background(), req)
+	s.NoError(err)
+	s.Empty(res)
+}
+
 func (s *PodLabelReconcilerTestSuite) TestClientAccessLabelAddedTruncatedNameAndNamespace() {
 	clientIntentsName := "client-intents"
 	serviceName := "test-client-with-a-very-long-name-more-than-20-characters"
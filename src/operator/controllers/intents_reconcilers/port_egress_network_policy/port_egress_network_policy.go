@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetriesgql"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
 	"github.com/samber/lo"
@@ -50,6 +53,11 @@ func NewPortEgressNetworkPolicyReconciler(
 	}
 }
 
+// DependsOn waits for the client pods to be labeled before creating their egress policies.
+func (r *PortEgressNetworkPolicyReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*intents_reconcilers.PodLabelReconciler)(nil)}
+}
+
 func (r *PortEgressNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	intents := &otterizev1alpha3.ClientIntents{}
 	err := r.Get(ctx, req.NamespacedName, intents)
@@ -176,6 +184,7 @@ func (r *PortEgressNetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Con
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, existingPolicy, policyCopy)
 	}
 
 	return nil
@@ -184,7 +193,12 @@ func (r *PortEgressNetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Con
 func (r *PortEgressNetworkPolicyReconciler) CreateNetworkPolicy(ctx context.Context, intentsObjNamespace string, intent otterizev1alpha3.Intent, newPolicy *v1.NetworkPolicy) error {
 	logrus.Infof(
 		"Creating network policy to enable access from namespace %s to %s", intentsObjNamespace, intent.Name)
-	return r.Create(ctx, newPolicy)
+	err := r.Create(ctx, newPolicy)
+	if err != nil {
+		return err
+	}
+	auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, newPolicy)
+	return nil
 }
 
 func (r *PortEgressNetworkPolicyReconciler) cleanPolicies(
@@ -260,7 +274,12 @@ func (r *PortEgressNetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx cont
 }
 
 func (r *PortEgressNetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
-	return r.Delete(ctx, &networkPolicy)
+	err := r.Delete(ctx, &networkPolicy)
+	if err != nil {
+		return err
+	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &networkPolicy)
+	return nil
 }
 
 func matchAccessNetworkPolicy() (labels.Selector, error) {
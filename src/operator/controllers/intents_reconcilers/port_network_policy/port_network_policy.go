@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +43,7 @@ type PortNetworkPolicyReconciler struct {
 	RestrictToNamespaces        []string
 	enableNetworkPolicyCreation bool
 	enforcementDefaultState     bool
+	accessLabels                accesslabels.Enum
 	injectablerecorder.InjectableRecorder
 }
 
@@ -49,6 +54,7 @@ func NewPortNetworkPolicyReconciler(
 	restrictToNamespaces []string,
 	enableNetworkPolicyCreation bool,
 	enforcementDefaultState bool,
+	accessLabels accesslabels.Enum,
 ) *PortNetworkPolicyReconciler {
 	return &PortNetworkPolicyReconciler{
 		Client:                      c,
@@ -57,9 +63,15 @@ func NewPortNetworkPolicyReconciler(
 		RestrictToNamespaces:        restrictToNamespaces,
 		enableNetworkPolicyCreation: enableNetworkPolicyCreation,
 		enforcementDefaultState:     enforcementDefaultState,
+		accessLabels:                accessLabels,
 	}
 }
 
+// DependsOn waits for pod labeling, since port policies may select clients by their access labels.
+func (r *PortNetworkPolicyReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*intents_reconcilers.PodLabelReconciler)(nil)}
+}
+
 func (r *PortNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	intents := &otterizev1alpha3.ClientIntents{}
 	err := r.Get(ctx, req.NamespacedName, intents)
@@ -92,7 +104,9 @@ func (r *PortNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	createdNetpols := 0
-	for _, intent := range intents.GetCallsList() {
+	// Only explicit calls get policies of their own, as only they are indexed by server. The DefaultClientIntents
+	// controller allows the default calls of the namespace for all of its clients.
+	for _, intent := range intents.Spec.Calls {
 		if intent.Type != "" && intent.Type != otterizev1alpha3.IntentTypeHTTP && intent.Type != otterizev1alpha3.IntentTypeKafka {
 			continue
 		}
@@ -122,7 +136,7 @@ func (r *PortNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	if createdNetpols != 0 {
-		callsCount := len(intents.GetCallsList())
+		callsCount := len(intents.Spec.Calls)
 		r.RecordNormalEventf(intents, consts.ReasonCreatedNetworkPolicies, "reconciled %d servers, created %d policies", callsCount, createdNetpols)
 	}
 	return ctrl.Result{}, nil
@@ -157,7 +171,11 @@ func (r *PortNetworkPolicyReconciler) handleNetworkPolicyCreation(
 		}
 		return false, err
 	}
-	newPolicy, err := r.buildNetworkPolicyObjectForIntent(&svc, intent, policyName, intentsObjNamespace)
+	clientIdentities, err := r.clientIdentitiesOfServer(ctx, intentsObj, intent, intentsObjNamespace)
+	if err != nil {
+		return false, err
+	}
+	newPolicy, err := r.buildNetworkPolicyObjectForIntent(&svc, intent, policyName, intentsObjNamespace, clientIdentities)
 	if err != nil {
 		return false, err
 	}
@@ -190,6 +208,7 @@ func (r *PortNetworkPolicyReconciler) UpdateExistingPolicy(ctx context.Context,
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectUpdated(ctx, auditlog.ResourceKindNetworkPolicy, existingPolicy, policyCopy)
 	}
 
 	return nil
@@ -202,6 +221,7 @@ func (r *PortNetworkPolicyReconciler) CreateNetworkPolicy(ctx context.Context, i
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectCreated(ctx, auditlog.ResourceKindNetworkPolicy, newPolicy)
 
 	return r.reconcileEndpointsForPolicy(ctx, newPolicy)
 }
@@ -220,7 +240,7 @@ func (r *PortNetworkPolicyReconciler) cleanPolicies(
 	intents *otterizev1alpha3.ClientIntents,
 ) error {
 	logrus.Infof("Removing network policies for deleted intents for service: %s", intents.Spec.Service.Name)
-	for _, intent := range intents.GetCallsList() {
+	for _, intent := range intents.Spec.Calls {
 		err := r.handleIntentRemoval(ctx, intent, intents.Namespace)
 		if err != nil {
 			return err
@@ -259,9 +279,23 @@ func (r *PortNetworkPolicyReconciler) handleIntentRemoval(
 		if err = r.deleteNetworkPolicy(ctx, intent, intentsObjNamespace); err != nil {
 			return err
 		}
+		return nil
+	}
 
+	// Other clients in the namespace still call the service, so only the removed client should stop being allowed.
+	if !r.accessLabels.SelectsByClientIdentity() {
+		return nil
 	}
-	return nil
+	policyName := fmt.Sprintf(otterizev1alpha3.OtterizeServiceNetworkPolicyNameTemplate, intent.GetTargetServerName(), intentsObjNamespace)
+	policy := &v1.NetworkPolicy{}
+	err = r.Get(ctx, types.NamespacedName{Name: policyName, Namespace: intent.GetTargetServerNamespace(intentsObjNamespace)}, policy)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return r.refreshPolicyClients(ctx, *policy)
 }
 
 func (r *PortNetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx context.Context) error {
@@ -302,12 +336,42 @@ func (r *PortNetworkPolicyReconciler) removeOrphanNetworkPolicies(ctx context.Co
 			if err != nil {
 				return err
 			}
+			continue
+		}
+
+		err = r.refreshPolicyClients(ctx, networkPolicy)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// clientIdentitiesOfServer returns the identities of the clients that call the intent's service from the namespace
+// of the intents, including the client of intentsObj, for policies that select clients by identity.
+func (r *PortNetworkPolicyReconciler) clientIdentitiesOfServer(
+	ctx context.Context, intentsObj *otterizev1alpha3.ClientIntents, intent otterizev1alpha3.Intent, intentsObjNamespace string) (sets.Set[intents_reconcilers.ClientIdentity], error) {
+	clientIdentities := sets.New(intents_reconcilers.ClientIdentityOf(intentsObj, intentsObjNamespace))
+	if !r.accessLabels.SelectsByClientIdentity() {
+		return clientIdentities, nil
+	}
+
+	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentity(intent.GetTargetServerName(), intent.GetTargetServerNamespace(intentsObjNamespace))
+	otherClientIdentities, err := intents_reconcilers.ClientIdentitiesOfServer(ctx, r.Client, "svc:"+formattedTargetServer, intentsObjNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return clientIdentities.Union(otherClientIdentities), nil
+}
+
+// refreshPolicyClients updates the clients allowed by a policy that selects clients by identity, after clients in
+// its namespace stopped calling the service while others still do.
+func (r *PortNetworkPolicyReconciler) refreshPolicyClients(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
+	serverName := networkPolicy.Labels[otterizev1alpha3.OtterizeSvcNetworkPolicy]
+	return intents_reconcilers.RefreshAccessPolicyClients(ctx, r.Client, r.accessLabels, networkPolicy, "svc:"+serverName, fmt.Sprintf(otterizev1alpha3.OtterizeSvcAccessLabelKey, serverName))
+}
+
 func (r *PortNetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, networkPolicy v1.NetworkPolicy) error {
 	err := r.extNetpolHandler.HandleBeforeAccessPolicyRemoval(ctx, &networkPolicy)
 	if err != nil {
@@ -317,6 +381,7 @@ func (r *PortNetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, n
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &networkPolicy)
 
 	return nil
 }
@@ -326,8 +391,14 @@ func matchAccessNetworkPolicy() (labels.Selector, error) {
 		Key:      otterizev1alpha3.OtterizeSvcNetworkPolicy,
 		Operator: metav1.LabelSelectorOpExists,
 	}
+	// Policies for the default calls of a namespace are managed by the DefaultClientIntents controller.
+	isNotDefaultIntentsPolicy := metav1.LabelSelectorRequirement{
+		Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+		Operator: metav1.LabelSelectorOpDoesNotExist,
+	}
 	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
 		isOtterizeNetworkPolicy,
+		isNotDefaultIntentsPolicy,
 	}})
 }
 
@@ -413,7 +484,7 @@ func (r *PortNetworkPolicyReconciler) CleanAllNamespaces(ctx context.Context) er
 
 // buildNetworkPolicyObjectForIntent builds the network policy that represents the intent from the parameter
 func (r *PortNetworkPolicyReconciler) buildNetworkPolicyObjectForIntent(
-	svc *corev1.Service, intent otterizev1alpha3.Intent, policyName, intentsObjNamespace string) (*v1.NetworkPolicy, error) {
+	svc *corev1.Service, intent otterizev1alpha3.Intent, policyName, intentsObjNamespace string, clientIdentities sets.Set[intents_reconcilers.ClientIdentity]) (*v1.NetworkPolicy, error) {
 	targetNamespace := intent.GetTargetServerNamespace(intentsObjNamespace)
 	// The intent's target server made of name + namespace + hash
 	formattedTargetServer := otterizev1alpha3.GetFormattedOtterizeIdentity(intent.GetTargetServerName(), targetNamespace)
@@ -432,21 +503,12 @@ func (r *PortNetworkPolicyReconciler) buildNetworkPolicyObjectForIntent(
 			PodSelector: podSelector,
 			Ingress: []v1.NetworkPolicyIngressRule{
 				{
-					From: []v1.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									fmt.Sprintf(
-										otterizev1alpha3.OtterizeSvcAccessLabelKey, formattedTargetServer): "true",
-								},
-							},
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									otterizev1alpha3.KubernetesStandardNamespaceNameLabelKey: intentsObjNamespace,
-								},
-							},
-						},
-					},
+					From: intents_reconcilers.AccessPolicyPeers(
+						r.accessLabels,
+						fmt.Sprintf(otterizev1alpha3.OtterizeSvcAccessLabelKey, formattedTargetServer),
+						intentsObjNamespace,
+						clientIdentities,
+					),
 				},
 			},
 		},
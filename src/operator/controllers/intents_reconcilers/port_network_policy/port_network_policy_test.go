@@ -7,6 +7,7 @@ import (
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/testbase"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -65,6 +66,7 @@ func (s *NetworkPolicyReconcilerTestSuite) SetupTest() {
 		restrictToNamespaces,
 		true,
 		true,
+		accesslabels.PerServer,
 	)
 
 	s.Reconciler.Recorder = s.Recorder
@@ -83,6 +85,10 @@ func (s *NetworkPolicyReconcilerTestSuite) ignoreRemoveOrphan() {
 			Key:      otterizev1alpha3.OtterizeSvcNetworkPolicy,
 			Operator: metav1.LabelSelectorOpExists,
 		},
+		{
+			Key:      otterizev1alpha3.OtterizeDefaultIntentsNetworkPolicy,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
 	}})
 	s.Require().NoError(err)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
@@ -130,6 +131,7 @@ func (r *ServiceWatcher) removeOrphanEgressServiceNetpols(ctx context.Context) e
 			if err != nil {
 				return err
 			}
+			auditlog.ObjectDeleted(ctx, auditlog.ResourceKindNetworkPolicy, &netpol)
 		}
 
 		if err != nil {
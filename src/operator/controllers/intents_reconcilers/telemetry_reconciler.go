@@ -55,10 +55,10 @@ func (r *TelemetryReconciler) Reconcile(ctx context.Context, req reconcile.Reque
 		return ctrl.Result{}, nil
 	}
 
-	r.intentsCounter[hashedName] = len(intents.Spec.Calls)
+	r.intentsCounter[hashedName] = len(intents.GetCallsList())
 	r.typedIntentsCounter[hashedName] = make(map[otterizev1alpha3.IntentType]int)
 
-	for _, call := range intents.Spec.Calls {
+	for _, call := range intents.GetCallsList() {
 		r.typedIntentsCounter[hashedName][call.Type]++
 	}
 
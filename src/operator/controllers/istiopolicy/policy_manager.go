@@ -9,6 +9,7 @@ import (
 	"github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/consts"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
@@ -85,6 +86,7 @@ func (c *PolicyManagerImpl) DeleteAll(
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectDeleted(ctx, auditlog.ResourceKindAuthorizationPolicy, policy)
 	}
 	return nil
 }
@@ -365,6 +367,7 @@ func (c *PolicyManagerImpl) createOrUpdatePolicies(
 			c.recorder.RecordWarningEventf(clientIntents, ReasonCreatingIstioPolicyFailed, "Failed to create Istio policy: %s", err.Error())
 			return nil, err
 		}
+		auditlog.ObjectCreated(ctx, auditlog.ResourceKindAuthorizationPolicy, newPolicy)
 		createdAnyPolicies = true
 	}
 
@@ -391,6 +394,7 @@ func (c *PolicyManagerImpl) deleteOutdatedPolicies(ctx context.Context, existing
 			if err != nil {
 				return err
 			}
+			auditlog.ObjectDeleted(ctx, auditlog.ResourceKindAuthorizationPolicy, existingPolicy)
 		}
 	}
 	return nil
@@ -410,6 +414,7 @@ func (c *PolicyManagerImpl) updatePolicy(ctx context.Context, existingPolicy *v1
 		c.recorder.RecordWarningEventf(existingPolicy, ReasonUpdatingIstioPolicyFailed, "Failed to update Istio policy: %s", err.Error())
 		return err
 	}
+	auditlog.ObjectUpdated(ctx, auditlog.ResourceKindAuthorizationPolicy, existingPolicy, policyCopy)
 
 	return nil
 }
@@ -0,0 +1,170 @@
+package kafka_server_config_reconcilers
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/protected_services"
+	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
+	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	ReasonKafkaACLDriftRepaired = "KafkaACLDriftRepaired"
+	ReasonKafkaACLResyncFailed  = "KafkaACLResyncFailed"
+)
+
+// KafkaACLDriftReconciler periodically compares the ACLs on a Kafka server with the intents of all of its clients and
+// repairs any difference, so that ACLs added or removed directly on the broker don't go unnoticed until the next
+// ClientIntents change.
+type KafkaACLDriftReconciler struct {
+	client.Client
+	injectablerecorder.InjectableRecorder
+	serversStore            kafkaacls.ServersStore
+	enforcementDefaultState bool
+	resyncInterval          time.Duration
+	now                     func() time.Time
+}
+
+func NewKafkaACLDriftReconciler(
+	client client.Client,
+	serversStore kafkaacls.ServersStore,
+	enforcementDefaultState bool,
+	resyncInterval time.Duration,
+) *KafkaACLDriftReconciler {
+	return &KafkaACLDriftReconciler{
+		Client:                  client,
+		serversStore:            serversStore,
+		enforcementDefaultState: enforcementDefaultState,
+		resyncInterval:          resyncInterval,
+		now:                     time.Now,
+	}
+}
+
+// DependsOn makes the resync run after the server was registered in the servers store.
+func (r *KafkaACLDriftReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*KafkaServerConfigReconciler)(nil)}
+}
+
+func (r *KafkaACLDriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	kafkaServerConfig := &otterizev1alpha3.KafkaServerConfig{}
+	err := r.Get(ctx, req.NamespacedName, kafkaServerConfig)
+	if k8serrors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	serverName := kafkaServerConfig.Spec.Service.Name
+	if !kafkaServerConfig.DeletionTimestamp.IsZero() || !r.serversStore.Exists(serverName, kafkaServerConfig.Namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	// The server is also reconciled when its spec or its protected services change, so a recent sync is not repeated,
+	// unless the operator's credentials were rotated since, which may have changed the principal mapping, or the
+	// operator restarted since, and doesn't know the principals of the server yet.
+	if drift := kafkaServerConfig.Status.ACLDrift; drift != nil {
+		serverStatus := r.serversStore.Status(serverName, kafkaServerConfig.Namespace)
+		rotated := serverStatus.Connectivity.LastRotation.After(drift.LastSyncTime.Time)
+		if elapsed := r.now().Sub(drift.LastSyncTime.Time); elapsed < r.resyncInterval && !rotated && serverStatus.Synced {
+			return ctrl.Result{RequeueAfter: r.resyncInterval - elapsed}, nil
+		}
+	}
+
+	missing, unexpected, err := r.syncServer(ctx, kafkaServerConfig)
+	if err != nil {
+		r.RecordWarningEventf(kafkaServerConfig, ReasonKafkaACLResyncFailed, "failed to resync Kafka ACLs: %s", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	server := fmt.Sprintf("%s.%s", serverName, kafkaServerConfig.Namespace)
+	prometheus.IncrementKafkaACLDriftRepaired(server, "missing", missing)
+	prometheus.IncrementKafkaACLDriftRepaired(server, "unexpected", unexpected)
+	if missing > 0 || unexpected > 0 {
+		logrus.WithFields(logrus.Fields{"server": server, "missing": missing, "unexpected": unexpected}).Warning("Repaired Kafka ACL drift")
+		r.RecordWarningEventf(kafkaServerConfig, ReasonKafkaACLDriftRepaired, "repaired Kafka ACL drift: %d missing, %d unexpected", missing, unexpected)
+	}
+
+	updated := kafkaServerConfig.DeepCopy()
+	updated.Status.ACLDrift = &otterizev1alpha3.KafkaACLDriftStatus{
+		LastSyncTime:   metav1.NewTime(r.now()),
+		MissingACLs:    missing,
+		UnexpectedACLs: unexpected,
+		// The sync recorded the principals of the clients as the ones the operator maintains ACLs for.
+		ManagedPrincipals: r.serversStore.Status(serverName, kafkaServerConfig.Namespace).PrincipalNames(),
+	}
+	if err := r.Status().Patch(ctx, updated, client.MergeFrom(kafkaServerConfig)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: r.resyncInterval}, nil
+}
+
+func (r *KafkaACLDriftReconciler) syncServer(ctx context.Context, kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) (int, int, error) {
+	serverName := kafkaServerConfig.Spec.Service.Name
+	intentsByClient, err := r.intentsByClient(ctx, serverName, kafkaServerConfig.Namespace)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	enforcementEnabled, err := protected_services.IsServerEnforcementEnabledDueToProtectionOrDefaultState(ctx, r.Client, serverName, kafkaServerConfig.Namespace, r.enforcementDefaultState)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	intentsAdmin, err := r.serversStore.GetWithEnforcement(serverName, kafkaServerConfig.Namespace, enforcementEnabled)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer intentsAdmin.Close()
+
+	return intentsAdmin.SyncClientIntents(ctx, intentsByClient, r.managedPrincipals(kafkaServerConfig))
+}
+
+// managedPrincipals returns the principals the operator created ACLs for: those persisted by the last sync, and those
+// the ClientIntents controller applied intents for since, which may have no ClientIntents by now.
+func (r *KafkaACLDriftReconciler) managedPrincipals(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) []string {
+	managedPrincipals := sets.New(r.serversStore.Status(kafkaServerConfig.Spec.Service.Name, kafkaServerConfig.Namespace).PrincipalNames()...)
+	if drift := kafkaServerConfig.Status.ACLDrift; drift != nil {
+		managedPrincipals.Insert(drift.ManagedPrincipals...)
+	}
+	return sets.List(managedPrincipals)
+}
+
+// intentsByClient collects the Kafka intents towards the server from every ClientIntents in the cluster. Clients being
+// deleted are left out, so that ACLs left behind by them are removed as well.
+func (r *KafkaACLDriftReconciler) intentsByClient(ctx context.Context, serverName string, serverNamespace string) (map[types.NamespacedName][]otterizev1alpha3.Intent, error) {
+	intentsList := &otterizev1alpha3.ClientIntentsList{}
+	if err := r.List(ctx, intentsList); err != nil {
+		return nil, err
+	}
+
+	intentsByClient := make(map[types.NamespacedName][]otterizev1alpha3.Intent)
+	for _, clientIntents := range intentsList.Items {
+		if !clientIntents.DeletionTimestamp.IsZero() || clientIntents.Spec == nil {
+			continue
+		}
+		clientName := types.NamespacedName{Name: clientIntents.Spec.Service.Name, Namespace: clientIntents.Namespace}
+		for _, intent := range clientIntents.GetCallsList() {
+			if intent.Type != otterizev1alpha3.IntentTypeKafka {
+				continue
+			}
+			if intent.GetTargetServerName() != serverName || intent.GetTargetServerNamespace(clientIntents.Namespace) != serverNamespace {
+				continue
+			}
+			intentsByClient[clientName] = append(intentsByClient[clientName], intent)
+		}
+	}
+	return intentsByClient, nil
+}
@@ -0,0 +1,174 @@
+package kafka_server_config_reconcilers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	intentsreconcilersmocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/otterize/intents-operator/src/shared/testbase"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+const resyncInterval = 5 * time.Minute
+
+// syncedServersStore reports every server as resynced since the operator started, which the mocked intents admin
+// doesn't record.
+type syncedServersStore struct {
+	kafkaacls.ServersStore
+}
+
+func (s syncedServersStore) Status(serverName string, namespace string) kafkaacls.ServerStatus {
+	status := s.ServersStore.Status(serverName, namespace)
+	status.Synced = true
+	return status
+}
+
+type KafkaACLDriftReconcilerTestSuite struct {
+	testbase.MocksSuiteBase
+	mockIntentsAdmin *kafkaaclsmocks.MockKafkaIntentsAdmin
+	reconciler       *KafkaACLDriftReconciler
+	now              time.Time
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) SetupTest() {
+	s.MocksSuiteBase.SetupTest()
+	s.mockIntentsAdmin = kafkaaclsmocks.NewMockKafkaIntentsAdmin(s.Controller)
+
+	serversStore := kafkaacls.NewServersStore(otterizev1alpha3.TLSSource{}, true, getMockIntentsAdminFactory(s.mockIntentsAdmin), true, kafkaacls.NewAdminPool(nil, kafkaacls.NewStatusTracker()))
+	serversStore.Add(s.kafkaServerConfig())
+
+	s.now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.reconciler = NewKafkaACLDriftReconciler(s.Client, serversStore, true, resyncInterval)
+	s.reconciler.now = func() time.Time { return s.now }
+	s.reconciler.InjectRecorder(s.Recorder)
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) kafkaServerConfig() *otterizev1alpha3.KafkaServerConfig {
+	return &otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServiceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: kafkaServiceName}},
+	}
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) expectGetKafkaServerConfig(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) {
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, ksc *otterizev1alpha3.KafkaServerConfig, _ ...client.GetOption) error {
+			kafkaServerConfig.DeepCopyInto(ksc)
+			return nil
+		})
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) reconcile() (ctrl.Result, error) {
+	return s.reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace},
+	})
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) TestRepairsDriftAndUpdatesStatus() {
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Status.ACLDrift = &otterizev1alpha3.KafkaACLDriftStatus{
+		LastSyncTime:      metav1.NewTime(s.now.Add(-resyncInterval)),
+		ManagedPrincipals: []string{"User:removed.client-namespace"},
+	}
+	s.expectGetKafkaServerConfig(kafkaServerConfig)
+
+	kafkaIntent := otterizev1alpha3.Intent{
+		Name:   kafkaServiceName + "." + testNamespace,
+		Type:   otterizev1alpha3.IntentTypeKafka,
+		Topics: []otterizev1alpha3.KafkaTopic{{Name: kafkaTopicName, Operations: []otterizev1alpha3.KafkaOperation{otterizev1alpha3.KafkaOperationProduce}}},
+	}
+	s.Client.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.ClientIntents{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "client-intents", Namespace: "client-namespace"},
+					Spec: &otterizev1alpha3.IntentsSpec{
+						Service: otterizev1alpha3.Service{Name: "client"},
+						Calls:   []otterizev1alpha3.Intent{kafkaIntent, {Name: "other-server", Type: otterizev1alpha3.IntentTypeKafka}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "http-intents", Namespace: testNamespace},
+					Spec: &otterizev1alpha3.IntentsSpec{
+						Service: otterizev1alpha3.Service{Name: "http-client"},
+						Calls:   []otterizev1alpha3.Intent{{Name: kafkaServiceName, Type: otterizev1alpha3.IntentTypeHTTP}},
+					},
+				},
+			}
+			return nil
+		})
+
+	s.mockIntentsAdmin.EXPECT().SyncClientIntents(gomock.Any(), map[types.NamespacedName][]otterizev1alpha3.Intent{
+		{Name: "client", Namespace: "client-namespace"}: {kafkaIntent},
+	}, []string{"User:removed.client-namespace"}).Return(2, 1, nil)
+	s.mockIntentsAdmin.EXPECT().Close()
+
+	statusWriter := intentsreconcilersmocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, ksc *otterizev1alpha3.KafkaServerConfig, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			s.Require().Equal(&otterizev1alpha3.KafkaACLDriftStatus{
+				LastSyncTime:   metav1.NewTime(s.now),
+				MissingACLs:    2,
+				UnexpectedACLs: 1,
+				// The mocked sync recorded no principals, so the removed principal is no longer managed.
+				ManagedPrincipals: []string{},
+			}, ksc.Status.ACLDrift)
+			return nil
+		})
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Equal(ctrl.Result{RequeueAfter: resyncInterval}, res)
+	s.ExpectEvent(ReasonKafkaACLDriftRepaired)
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) TestRecentSyncIsNotRepeated() {
+	s.reconciler.serversStore = syncedServersStore{ServersStore: s.reconciler.serversStore}
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Status.ACLDrift = &otterizev1alpha3.KafkaACLDriftStatus{LastSyncTime: metav1.NewTime(s.now.Add(-time.Minute))}
+	s.expectGetKafkaServerConfig(kafkaServerConfig)
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Equal(ctrl.Result{RequeueAfter: resyncInterval - time.Minute}, res)
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) TestRecentSyncIsRepeatedAfterRestart() {
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Status.ACLDrift = &otterizev1alpha3.KafkaACLDriftStatus{LastSyncTime: metav1.NewTime(s.now.Add(-time.Minute))}
+	s.expectGetKafkaServerConfig(kafkaServerConfig)
+
+	s.Client.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockIntentsAdmin.EXPECT().SyncClientIntents(gomock.Any(), map[types.NamespacedName][]otterizev1alpha3.Intent{}, gomock.Any()).Return(0, 0, nil)
+	s.mockIntentsAdmin.EXPECT().Close()
+	statusWriter := intentsreconcilersmocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Equal(ctrl.Result{RequeueAfter: resyncInterval}, res)
+}
+
+func (s *KafkaACLDriftReconcilerTestSuite) TestUnknownServerIsSkipped() {
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Spec.Service.Name = "unregistered"
+	s.expectGetKafkaServerConfig(kafkaServerConfig)
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+}
+
+func TestKafkaACLDriftReconcilerTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaACLDriftReconcilerTestSuite))
+}
@@ -7,6 +7,7 @@ import (
 	otterizev1alpha2 "github.com/otterize/intents-operator/src/operator/api/v1alpha2"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers"
+	intentsreconcilersmocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
 	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
 	"github.com/otterize/intents-operator/src/shared/otterizecloud/graphqlclient"
@@ -91,7 +92,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) setupServerStore(serviceName stri
 	serverConfig.SetNamespace(testNamespace)
 	emptyTls := otterizev1alpha3.TLSSource{}
 	factory := getMockIntentsAdminFactory(s.mockIntentsAdmin)
-	kafkaServersStore := kafkaacls.NewServersStore(emptyTls, false, factory, true)
+	kafkaServersStore := kafkaacls.NewServersStore(emptyTls, false, factory, true, kafkaacls.NewAdminPool(nil, kafkaacls.NewStatusTracker()))
 	kafkaServersStore.Add(serverConfig)
 	return kafkaServersStore
 }
@@ -144,7 +145,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestKafkaServerConfigUpload() {
 
 	// Set go mock expectations
 	expectedConfigs := s.getExpectedKafkaServerConfigs(kafkaServerConfig)
-	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
 	s.mockIntentsAdmin.EXPECT().Close()
 
 	emptyList := &otterizev1alpha3.KafkaServerConfigList{}
@@ -194,7 +195,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestRequeueKafkaServerConfigOnFai
 
 	// Set go mock expectations
 	expectedConfigs := s.getExpectedKafkaServerConfigs(kafkaServerConfig)
-	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
 	s.mockIntentsAdmin.EXPECT().Close()
 
 	emptyList := &otterizev1alpha3.KafkaServerConfigList{}
@@ -249,7 +250,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestKafkaServerConfigDelete() {
 	)
 
 	gomock.InOrder(
-		s.mockIntentsAdmin.EXPECT().RemoveServerIntents(deletedKSC.Spec.Topics).Return(nil),
+		s.mockIntentsAdmin.EXPECT().RemoveServerIntents(gomock.Any(), deletedKSC.Spec.Topics).Return(nil),
 		s.mockIntentsAdmin.EXPECT().Close().Times(1),
 	)
 
@@ -326,7 +327,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestIntentsGeneratedForOperator()
 
 	// Expect sending the resource for Intents Admin
 	expectedConfigs := s.getExpectedKafkaServerConfigs(kafkaServerConfig)
-	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
 	s.mockIntentsAdmin.EXPECT().Close()
 
 	// Expect uploading the resource to Cloud
@@ -429,7 +430,7 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestUpdateIntentsGeneratedForOper
 
 	// Expect sending the resource for Intents Admin
 	expectedConfigs := s.getExpectedKafkaServerConfigs(kafkaServerConfig)
-	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
 	s.mockIntentsAdmin.EXPECT().Close()
 
 	// Expect uploading the resource to Cloud
@@ -454,6 +455,101 @@ func (s *KafkaServerConfigReconcilerTestSuite) TestUpdateIntentsGeneratedForOper
 	s.ExpectEvent(ReasonSuccessfullyAppliedKafkaServerConfig)
 }
 
+func (s *KafkaServerConfigReconcilerTestSuite) expectUploadKafkaServerConfig(kafkaServerConfig otterizev1alpha3.KafkaServerConfig, expectedConfigs []graphqlclient.KafkaServerConfigInput) {
+	emptyList := &otterizev1alpha3.KafkaServerConfigList{}
+	s.Client.EXPECT().List(gomock.Any(), emptyList, client.InNamespace(testNamespace), &client.ListOptions{Namespace: testNamespace}).DoAndReturn(
+		func(ctx context.Context, list *otterizev1alpha3.KafkaServerConfigList, _ ...client.ListOption) error {
+			list.Items = append(list.Items, kafkaServerConfig)
+			return nil
+		})
+	s.mockCloudClient.EXPECT().ReportKafkaServerConfig(gomock.Any(), testNamespace, gomock.Eq(expectedConfigs)).Return(nil)
+}
+
+func (s *KafkaServerConfigReconcilerTestSuite) TestManagedTopicsApplied() {
+	kafkaServerConfig := s.generateKafkaServerConfig()
+	kafkaServerConfig.Spec.ManagedTopics = []otterizev1alpha3.ManagedKafkaTopic{
+		{Name: "orders", Partitions: 3, DeletionPolicy: otterizev1alpha3.TopicDeletionPolicyDelete},
+		{Name: "payments", DeletionPolicy: otterizev1alpha3.TopicDeletionPolicyRetain},
+	}
+	// "payments" changed to Retain and "refunds" was removed from the spec.
+	kafkaServerConfig.Status.DeletableTopics = []string{"orders", "payments", "refunds"}
+
+	objectName := types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace}
+	s.Client.EXPECT().Get(gomock.Any(), objectName, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, actualKSC *otterizev1alpha3.KafkaServerConfig, _ ...client.GetOption) error {
+			kafkaServerConfig.DeepCopyInto(actualKSC)
+			return nil
+		})
+
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyManagedTopics(gomock.Any(), kafkaServerConfig.Spec.ManagedTopics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().DeleteManagedTopics(gomock.Any(), []string{"refunds"}).Return(nil)
+	s.mockIntentsAdmin.EXPECT().Close()
+
+	statusWriter := intentsreconcilersmocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, ksc *otterizev1alpha3.KafkaServerConfig, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			s.Require().Equal([]string{"orders"}, ksc.Status.DeletableTopics)
+			return nil
+		})
+
+	s.expectUploadKafkaServerConfig(kafkaServerConfig, s.getExpectedKafkaServerConfigs(kafkaServerConfig))
+
+	res, err := s.reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: objectName})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+	s.ExpectEvent(ReasonSuccessfullyAppliedKafkaServerConfig)
+}
+
+func (s *KafkaServerConfigReconcilerTestSuite) TestManagedTopicsFailure() {
+	kafkaServerConfig := s.generateKafkaServerConfig()
+	kafkaServerConfig.Spec.ManagedTopics = []otterizev1alpha3.ManagedKafkaTopic{{Name: "orders", Partitions: 1}}
+
+	objectName := types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace}
+	s.Client.EXPECT().Get(gomock.Any(), objectName, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, actualKSC *otterizev1alpha3.KafkaServerConfig, _ ...client.GetOption) error {
+			kafkaServerConfig.DeepCopyInto(actualKSC)
+			return nil
+		})
+
+	s.mockIntentsAdmin.EXPECT().ApplyServerTopicsConf(gomock.Any(), kafkaServerConfig.Spec.Topics).Return(nil)
+	s.mockIntentsAdmin.EXPECT().ApplyManagedTopics(gomock.Any(), kafkaServerConfig.Spec.ManagedTopics).Return(errors.New("partitions cannot be removed"))
+	s.mockIntentsAdmin.EXPECT().Close()
+
+	_, err := s.reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: objectName})
+	s.Require().Error(err)
+	s.ExpectEvent(ReasonApplyingManagedTopicsFailed)
+}
+
+func (s *KafkaServerConfigReconcilerTestSuite) TestKafkaServerConfigDeleteDeletesManagedTopics() {
+	deletedKSC := s.generateKafkaServerConfig()
+	deletedKSC.DeletionTimestamp = &metav1.Time{Time: time.Date(2022, 9, 16, 0, 55, 0, 0, time.UTC)}
+	deletedKSC.Spec.ManagedTopics = []otterizev1alpha3.ManagedKafkaTopic{
+		{Name: "orders", DeletionPolicy: otterizev1alpha3.TopicDeletionPolicyDelete},
+		{Name: "payments", DeletionPolicy: otterizev1alpha3.TopicDeletionPolicyRetain},
+	}
+	deletedKSC.Status.DeletableTopics = []string{"orders", "refunds"}
+
+	objectName := types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace}
+	s.Client.EXPECT().Get(gomock.Any(), objectName, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, actualKSC *otterizev1alpha3.KafkaServerConfig, _ ...client.GetOption) error {
+			deletedKSC.DeepCopyInto(actualKSC)
+			return nil
+		})
+
+	gomock.InOrder(
+		s.mockIntentsAdmin.EXPECT().DeleteManagedTopics(gomock.Any(), []string{"orders", "refunds"}).Return(nil),
+		s.mockIntentsAdmin.EXPECT().RemoveServerIntents(gomock.Any(), deletedKSC.Spec.Topics).Return(nil),
+		s.mockIntentsAdmin.EXPECT().Close(),
+	)
+	s.expectUploadKafkaServerConfig(deletedKSC, []graphqlclient.KafkaServerConfigInput{})
+
+	res, err := s.reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: objectName})
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+}
+
 func TestKafkaACLReconcilerTestSuite(t *testing.T) {
 	suite.Run(t, new(KafkaServerConfigReconcilerTestSuite))
 }
@@ -13,8 +13,10 @@ import (
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetriesgql"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
+	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +31,7 @@ const (
 	ReasonIntentsOperatorIdentityResolveFailed = "IntentsOperatorIdentityResolveFailed"
 	ReasonApplyingKafkaServerConfigFailed      = "ApplyingKafkaServerConfigFailed"
 	ReasonSuccessfullyAppliedKafkaServerConfig = "SuccessfullyAppliedKafkaServerConfig"
+	ReasonApplyingManagedTopicsFailed          = "ApplyingManagedTopicsFailed"
 )
 
 // KafkaServerConfigReconciler reconciles a KafkaServerConfig object
@@ -68,7 +71,7 @@ func NewKafkaServerConfigReconciler(
 //+kubebuilder:rbac:groups=k8s.otterize.com,resources=kafkaserverconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=k8s.otterize.com,resources=kafkaserverconfigs/finalizers,verbs=update
 
-func (r *KafkaServerConfigReconciler) removeKafkaServerFromStore(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) error {
+func (r *KafkaServerConfigReconciler) removeKafkaServerFromStore(ctx context.Context, kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) error {
 	logger := logrus.WithFields(
 		logrus.Fields{
 			"name":      kafkaServerConfig.Name,
@@ -86,8 +89,15 @@ func (r *KafkaServerConfigReconciler) removeKafkaServerFromStore(kafkaServerConf
 
 	defer intentsAdmin.Close()
 
+	if topicNames := deletableTopics(kafkaServerConfig); len(topicNames) > 0 {
+		logger.Infof("Deleting %d managed topics", len(topicNames))
+		if err := intentsAdmin.DeleteManagedTopics(ctx, topicNames); err != nil {
+			return err
+		}
+	}
+
 	logger.Info("Removing associated ACLs")
-	if err := intentsAdmin.RemoveServerIntents(kafkaServerConfig.Spec.Topics); err != nil {
+	if err := intentsAdmin.RemoveServerIntents(ctx, kafkaServerConfig.Spec.Topics); err != nil {
 		return err
 	}
 
@@ -116,7 +126,7 @@ func (r *KafkaServerConfigReconciler) removeIntentsFromOperatorToKafkaServer(ctx
 }
 
 func (r *KafkaServerConfigReconciler) handleResourceDeletion(ctx context.Context, kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) (ctrl.Result, error) {
-	if err := r.removeKafkaServerFromStore(kafkaServerConfig); err != nil {
+	if err := r.removeKafkaServerFromStore(ctx, kafkaServerConfig); err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -156,11 +166,8 @@ func (r *KafkaServerConfigReconciler) createIntentsFromOperatorToKafkaServer(ctx
 				Type: otterizev1alpha3.IntentTypeKafka,
 				Name: fmt.Sprintf("%s.%s", config.Spec.Service.Name, config.Namespace),
 				Topics: []otterizev1alpha3.KafkaTopic{{
-					Name: "*",
-					Operations: []otterizev1alpha3.KafkaOperation{
-						otterizev1alpha3.KafkaOperationDescribe,
-						otterizev1alpha3.KafkaOperationAlter,
-					},
+					Name:       "*",
+					Operations: operatorTopicOperations(config),
 				}},
 			}},
 		},
@@ -216,7 +223,8 @@ func (r *KafkaServerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 }
 
 func (r *KafkaServerConfigReconciler) reconcileObject(ctx context.Context, kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) (ctrl.Result, error) {
-	if !kafkaServerConfig.Spec.NoAutoCreateIntentsForOperator {
+	// With Strimzi, ACLs are managed through KafkaUser resources and the operator never connects to the brokers.
+	if !kafkaServerConfig.Spec.NoAutoCreateIntentsForOperator && kafkaServerConfig.Spec.Strimzi == nil {
 		err := r.createIntentsFromOperatorToKafkaServer(ctx, kafkaServerConfig)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -235,15 +243,74 @@ func (r *KafkaServerConfigReconciler) reconcileObject(ctx context.Context, kafka
 	}
 	defer kafkaIntentsAdmin.Close()
 
-	if err := kafkaIntentsAdmin.ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics); err != nil {
+	if err := kafkaIntentsAdmin.ApplyServerTopicsConf(ctx, kafkaServerConfig.Spec.Topics); err != nil {
 		r.RecordWarningEventf(kafkaServerConfig, ReasonApplyingKafkaServerConfigFailed, "failed to apply server config to Kafka broker: %s", err.Error())
 		return ctrl.Result{}, err
 	}
 
+	if err := r.applyManagedTopics(ctx, kafkaServerConfig, kafkaIntentsAdmin); err != nil {
+		r.RecordWarningEventf(kafkaServerConfig, ReasonApplyingManagedTopicsFailed, "failed to apply managed topics to Kafka broker: %s", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	r.RecordNormalEvent(kafkaServerConfig, ReasonSuccessfullyAppliedKafkaServerConfig, "successfully applied server config")
 	return ctrl.Result{}, nil
 }
 
+// applyManagedTopics creates and updates the managed topics, and deletes topics with the Delete policy that were
+// removed from the spec since the last time topics were applied.
+func (r *KafkaServerConfigReconciler) applyManagedTopics(ctx context.Context, kafkaServerConfig *otterizev1alpha3.KafkaServerConfig, kafkaIntentsAdmin kafkaacls.KafkaIntentsAdmin) error {
+	if len(kafkaServerConfig.Spec.ManagedTopics) == 0 && len(kafkaServerConfig.Status.DeletableTopics) == 0 {
+		return nil
+	}
+
+	if err := kafkaIntentsAdmin.ApplyManagedTopics(ctx, kafkaServerConfig.Spec.ManagedTopics); err != nil {
+		return err
+	}
+
+	managedTopicNames := lo.Map(kafkaServerConfig.Spec.ManagedTopics, func(topic otterizev1alpha3.ManagedKafkaTopic, _ int) string {
+		return topic.Name
+	})
+	removedTopics := lo.Without(kafkaServerConfig.Status.DeletableTopics, managedTopicNames...)
+	if len(removedTopics) > 0 {
+		if err := kafkaIntentsAdmin.DeleteManagedTopics(ctx, removedTopics); err != nil {
+			return err
+		}
+	}
+
+	deletableTopics := kafkaServerConfig.Spec.TopicsWithDeletePolicy()
+	if slices.Equal(deletableTopics, kafkaServerConfig.Status.DeletableTopics) {
+		return nil
+	}
+	updated := kafkaServerConfig.DeepCopy()
+	updated.Status.DeletableTopics = deletableTopics
+	return r.Status().Patch(ctx, updated, client.MergeFrom(kafkaServerConfig))
+}
+
+// deletableTopics returns the topics to delete along with the KafkaServerConfig: those with the Delete policy, and
+// those that had it when topics were last applied.
+func deletableTopics(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) []string {
+	return lo.Union(kafkaServerConfig.Spec.TopicsWithDeletePolicy(), kafkaServerConfig.Status.DeletableTopics)
+}
+
+// operatorTopicOperations are the topic operations the operator needs on the server. Managing topics requires
+// creating and deleting them, and reading and changing their configs.
+func operatorTopicOperations(config *otterizev1alpha3.KafkaServerConfig) []otterizev1alpha3.KafkaOperation {
+	operations := []otterizev1alpha3.KafkaOperation{
+		otterizev1alpha3.KafkaOperationDescribe,
+		otterizev1alpha3.KafkaOperationAlter,
+	}
+	if len(config.Spec.ManagedTopics) > 0 || len(config.Status.DeletableTopics) > 0 {
+		operations = append(operations,
+			otterizev1alpha3.KafkaOperationCreate,
+			otterizev1alpha3.KafkaOperationDelete,
+			otterizev1alpha3.KafkaOperationDescribeConfigs,
+			otterizev1alpha3.KafkaOperationAlterConfigs,
+		)
+	}
+	return operations
+}
+
 func (r *KafkaServerConfigReconciler) uploadKafkaServerConfigs(ctx context.Context, namespace string) error {
 	if r.otterizeClient == nil {
 		return nil
@@ -0,0 +1,179 @@
+package kafka_server_config_reconcilers
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
+	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
+	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	ConditionReasonConnected              = "Connected"
+	ConditionReasonConnectionFailed       = "ConnectionFailed"
+	ConditionReasonConnectionNotAttempted = "ConnectionNotAttempted"
+	ConditionReasonStrimziBackend         = "StrimziBackend"
+	ConditionReasonApplied                = "Applied"
+	ConditionReasonApplyFailed            = "ApplyFailed"
+	ConditionReasonNotApplied             = "NotApplied"
+	ConditionReasonNoClientIntents        = "NoClientIntents"
+)
+
+// KafkaServerStatusReconciler reports what the operator knows about a Kafka server in the KafkaServerConfig status:
+// whether it is connected, whether ACLs and topic configuration were applied, and a summary of the managed ACLs.
+// ACLs are also applied by the ClientIntents controller, so the results are taken from the servers store rather than
+// from the other reconcilers of the group.
+type KafkaServerStatusReconciler struct {
+	client.Client
+	injectablerecorder.InjectableRecorder
+	serversStore kafkaacls.ServersStore
+}
+
+func NewKafkaServerStatusReconciler(client client.Client, serversStore kafkaacls.ServersStore) *KafkaServerStatusReconciler {
+	return &KafkaServerStatusReconciler{
+		Client:       client,
+		serversStore: serversStore,
+	}
+}
+
+// DependsOn makes the status reflect the topic configuration and ACL resync done in the same reconcile.
+func (r *KafkaServerStatusReconciler) DependsOn() []reconcilergroup.ReconcilerWithEvents {
+	return []reconcilergroup.ReconcilerWithEvents{(*KafkaServerConfigReconciler)(nil), (*KafkaACLDriftReconciler)(nil)}
+}
+
+func (r *KafkaServerStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	kafkaServerConfig := &otterizev1alpha3.KafkaServerConfig{}
+	err := r.Get(ctx, req.NamespacedName, kafkaServerConfig)
+	if k8serrors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	serverName := kafkaServerConfig.Spec.Service.Name
+	if !kafkaServerConfig.DeletionTimestamp.IsZero() || !r.serversStore.Exists(serverName, kafkaServerConfig.Namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	updated := kafkaServerConfig.DeepCopy()
+	setServerStatus(updated, r.serversStore.Status(serverName, kafkaServerConfig.Namespace))
+	if equality.Semantic.DeepEqual(kafkaServerConfig.Status, updated.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Status().Patch(ctx, updated, client.MergeFrom(kafkaServerConfig)); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func setServerStatus(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig, serverStatus kafkaacls.ServerStatus) {
+	status := &kafkaServerConfig.Status
+	generation := kafkaServerConfig.Generation
+
+	meta.SetStatusCondition(&status.Conditions, connectedCondition(kafkaServerConfig, serverStatus, generation))
+	meta.SetStatusCondition(&status.Conditions, topicConfigAppliedCondition(serverStatus, generation))
+	meta.SetStatusCondition(&status.Conditions, aclsAppliedCondition(serverStatus, generation))
+
+	if !serverStatus.LastSuccess.IsZero() {
+		// The API server stores times with second precision, truncating avoids patching an unchanged status.
+		status.LastSuccessfulSyncTime = &metav1.Time{Time: serverStatus.LastSuccess.Truncate(time.Second)}
+	} else if serverStatus.Synced {
+		status.LastSuccessfulSyncTime = nil
+	}
+	status.BrokerVersion = serverStatus.Connectivity.BrokerVersion
+	// Until the server is resynced after the operator starts, only the principals reconciled since are known, so the
+	// ones reported before the restart are kept.
+	if serverStatus.Synced {
+		status.ManagedACLs = serverStatus.ManagedACLs
+		status.Principals = serverStatus.PrincipalNames()
+	}
+	status.ManagedQuotas = serverStatus.ManagedQuotas
+	status.LastError = serverStatus.LastError
+}
+
+func connectedCondition(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig, serverStatus kafkaacls.ServerStatus, generation int64) metav1.Condition {
+	condition := metav1.Condition{Type: otterizev1alpha3.KafkaServerConditionConnected, ObservedGeneration: generation}
+	switch {
+	case kafkaServerConfig.Spec.Strimzi != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ConditionReasonStrimziBackend
+		condition.Message = "ACLs are managed through Strimzi KafkaUser resources, the operator does not connect to the brokers"
+	case !serverStatus.HasConnectivity:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ConditionReasonConnectionNotAttempted
+		condition.Message = "The operator has not connected to the server yet"
+	case serverStatus.Connectivity.State == kafkaacls.ConnectivityStateConnected:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ConditionReasonConnected
+		condition.Message = fmt.Sprintf("Connected to Kafka %s", serverStatus.Connectivity.BrokerVersion)
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ConditionReasonConnectionFailed
+		condition.Message = serverStatus.Connectivity.LastError
+	}
+	return condition
+}
+
+func topicConfigAppliedCondition(serverStatus kafkaacls.ServerStatus, generation int64) metav1.Condition {
+	condition := metav1.Condition{Type: otterizev1alpha3.KafkaServerConditionTopicConfigApplied, ObservedGeneration: generation}
+	switch {
+	case serverStatus.TopicConfig == nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ConditionReasonNotApplied
+		condition.Message = "The topic configuration has not been applied yet"
+	case serverStatus.TopicConfig.Error != "":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ConditionReasonApplyFailed
+		condition.Message = serverStatus.TopicConfig.Error
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ConditionReasonApplied
+		condition.Message = "The topic configuration was applied"
+	}
+	return condition
+}
+
+func aclsAppliedCondition(serverStatus kafkaacls.ServerStatus, generation int64) metav1.Condition {
+	condition := metav1.Condition{Type: otterizev1alpha3.KafkaServerConditionACLsApplied, ObservedGeneration: generation}
+	failed := 0
+	var lastFailure kafkaacls.OperationResult
+	for _, result := range serverStatus.Principals {
+		if result.Error == "" {
+			continue
+		}
+		failed++
+		if result.Time.After(lastFailure.Time) {
+			lastFailure = result
+		}
+	}
+
+	switch {
+	case failed > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ConditionReasonApplyFailed
+		condition.Message = fmt.Sprintf("Failed applying ACLs for %d of %d principals: %s", failed, len(serverStatus.Principals), lastFailure.Error)
+	case !serverStatus.Synced:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ConditionReasonNotApplied
+		condition.Message = "The ACLs have not been synced since the operator started"
+	case len(serverStatus.Principals) == 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ConditionReasonNoClientIntents
+		condition.Message = "No ClientIntents require ACLs on the server"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ConditionReasonApplied
+		condition.Message = fmt.Sprintf("ACLs were applied for %d principals", len(serverStatus.Principals))
+	}
+	return condition
+}
@@ -0,0 +1,168 @@
+package kafka_server_config_reconcilers
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	intentsreconcilersmocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/otterize/intents-operator/src/shared/testbase"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+type KafkaServerStatusReconcilerTestSuite struct {
+	testbase.MocksSuiteBase
+	reconciler *KafkaServerStatusReconciler
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) SetupTest() {
+	s.MocksSuiteBase.SetupTest()
+	mockIntentsAdmin := kafkaaclsmocks.NewMockKafkaIntentsAdmin(s.Controller)
+	serversStore := kafkaacls.NewServersStore(otterizev1alpha3.TLSSource{}, true, getMockIntentsAdminFactory(mockIntentsAdmin), true, kafkaacls.NewAdminPool(nil, kafkaacls.NewStatusTracker()))
+	serversStore.Add(s.kafkaServerConfig())
+
+	s.reconciler = NewKafkaServerStatusReconciler(s.Client, serversStore)
+	s.reconciler.InjectRecorder(s.Recorder)
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) kafkaServerConfig() *otterizev1alpha3.KafkaServerConfig {
+	return &otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServiceName, Namespace: testNamespace, Generation: 2},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: kafkaServiceName}},
+	}
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) expectGetKafkaServerConfig(kafkaServerConfig *otterizev1alpha3.KafkaServerConfig) {
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, ksc *otterizev1alpha3.KafkaServerConfig, _ ...client.GetOption) error {
+			kafkaServerConfig.DeepCopyInto(ksc)
+			return nil
+		})
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) reconcile() (ctrl.Result, error) {
+	return s.reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: kafkaServiceName, Namespace: testNamespace},
+	})
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) TestInitialStatusIsPatched() {
+	s.expectGetKafkaServerConfig(s.kafkaServerConfig())
+
+	var patched *otterizev1alpha3.KafkaServerConfig
+	statusWriter := intentsreconcilersmocks.NewMockSubResourceWriter(s.Controller)
+	s.Client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, ksc *otterizev1alpha3.KafkaServerConfig, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			patched = ksc
+			return nil
+		})
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+
+	connected := meta.FindStatusCondition(patched.Status.Conditions, otterizev1alpha3.KafkaServerConditionConnected)
+	s.Require().NotNil(connected)
+	s.Require().Equal(metav1.ConditionUnknown, connected.Status)
+	s.Require().Equal(ConditionReasonConnectionNotAttempted, connected.Reason)
+	s.Require().Equal(int64(2), connected.ObservedGeneration)
+	aclsApplied := meta.FindStatusCondition(patched.Status.Conditions, otterizev1alpha3.KafkaServerConditionACLsApplied)
+	s.Require().NotNil(aclsApplied)
+	s.Require().Equal(metav1.ConditionUnknown, aclsApplied.Status)
+	s.Require().Equal(ConditionReasonNotApplied, aclsApplied.Reason)
+	s.Require().Nil(patched.Status.LastSuccessfulSyncTime)
+
+	// Once written, the same status is not patched again.
+	s.expectGetKafkaServerConfig(patched)
+	_, err = s.reconcile()
+	s.Require().NoError(err)
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) TestDeletedServerIsSkipped() {
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	s.expectGetKafkaServerConfig(kafkaServerConfig)
+
+	res, err := s.reconcile()
+	s.Require().NoError(err)
+	s.Require().Empty(res)
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) TestSetServerStatus() {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 500, time.UTC)
+	kafkaServerConfig := s.kafkaServerConfig()
+	setServerStatus(kafkaServerConfig, kafkaacls.ServerStatus{
+		Connectivity:    kafkaacls.ServerConnectivity{State: kafkaacls.ConnectivityStateConnected, BrokerVersion: "3.4.0"},
+		HasConnectivity: true,
+		TopicConfig:     &kafkaacls.OperationResult{Time: now},
+		Principals: map[string]kafkaacls.OperationResult{
+			"User:b": {Time: now},
+			"User:a": {Time: now, Error: "broker unavailable"},
+		},
+		ManagedACLs:   4,
+		ManagedQuotas: map[string][]string{"a": {"producer_byte_rate"}},
+		Synced:        true,
+		LastSuccess:   now,
+		LastError:     "broker unavailable",
+		LastErrorTime: now,
+	})
+
+	status := kafkaServerConfig.Status
+	s.Require().True(meta.IsStatusConditionTrue(status.Conditions, otterizev1alpha3.KafkaServerConditionConnected))
+	s.Require().True(meta.IsStatusConditionTrue(status.Conditions, otterizev1alpha3.KafkaServerConditionTopicConfigApplied))
+	aclsApplied := meta.FindStatusCondition(status.Conditions, otterizev1alpha3.KafkaServerConditionACLsApplied)
+	s.Require().Equal(metav1.ConditionFalse, aclsApplied.Status)
+	s.Require().Equal("Failed applying ACLs for 1 of 2 principals: broker unavailable", aclsApplied.Message)
+
+	s.Require().Equal(now.Truncate(time.Second), status.LastSuccessfulSyncTime.Time)
+	s.Require().Equal("3.4.0", status.BrokerVersion)
+	s.Require().Equal(4, status.ManagedACLs)
+	s.Require().Equal([]string{"User:a", "User:b"}, status.Principals)
+	s.Require().Equal(map[string][]string{"a": {"producer_byte_rate"}}, status.ManagedQuotas)
+	s.Require().Equal("broker unavailable", status.LastError)
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) TestStatusBeforeFirstSyncKeepsPrincipals() {
+	lastSync := metav1.NewTime(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC))
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Status.Principals = []string{"User:a", "User:b"}
+	kafkaServerConfig.Status.ManagedACLs = 4
+	kafkaServerConfig.Status.LastSuccessfulSyncTime = &lastSync
+
+	// The operator restarted, and the server wasn't resynced yet.
+	setServerStatus(kafkaServerConfig, kafkaacls.ServerStatus{Principals: map[string]kafkaacls.OperationResult{}})
+
+	status := kafkaServerConfig.Status
+	aclsApplied := meta.FindStatusCondition(status.Conditions, otterizev1alpha3.KafkaServerConditionACLsApplied)
+	s.Require().Equal(metav1.ConditionUnknown, aclsApplied.Status)
+	s.Require().Equal(ConditionReasonNotApplied, aclsApplied.Reason)
+	s.Require().Equal([]string{"User:a", "User:b"}, status.Principals)
+	s.Require().Equal(4, status.ManagedACLs)
+	s.Require().Equal(&lastSync, status.LastSuccessfulSyncTime)
+}
+
+func (s *KafkaServerStatusReconcilerTestSuite) TestStrimziServerIsNotConnected() {
+	kafkaServerConfig := s.kafkaServerConfig()
+	kafkaServerConfig.Spec.Strimzi = &otterizev1alpha3.StrimziConfig{ClusterName: "cluster"}
+	setServerStatus(kafkaServerConfig, kafkaacls.ServerStatus{
+		TopicConfig: &kafkaacls.OperationResult{Error: "invalid topic"},
+	})
+
+	connected := meta.FindStatusCondition(kafkaServerConfig.Status.Conditions, otterizev1alpha3.KafkaServerConditionConnected)
+	s.Require().Equal(metav1.ConditionUnknown, connected.Status)
+	s.Require().Equal(ConditionReasonStrimziBackend, connected.Reason)
+	s.Require().True(meta.IsStatusConditionFalse(kafkaServerConfig.Status.Conditions, otterizev1alpha3.KafkaServerConditionTopicConfigApplied))
+}
+
+func TestKafkaServerStatusReconcilerTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaServerStatusReconcilerTestSuite))
+}
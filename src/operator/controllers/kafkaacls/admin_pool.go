@@ -0,0 +1,530 @@
+package kafkaacls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sync"
+	"time"
+)
+
+const (
+	healthCheckInterval = 30 * time.Second
+	// credentialsCheckInterval is how often pooled connections are checked for rotated TLS material and credentials.
+	credentialsCheckInterval = time.Minute
+
+	// describeClusterAPIKey is the Kafka protocol key of DescribeCluster, added in Kafka 2.8.
+	describeClusterAPIKey = 60
+	// incrementalAlterConfigsAPIKey is the Kafka protocol key of IncrementalAlterConfigs, added in Kafka 2.3.
+	incrementalAlterConfigsAPIKey = 44
+	apiVersionsAPIKey             = 18
+	describeAclsAPIKey            = 29
+)
+
+type ConnectivityState string
+
+const (
+	ConnectivityStateConnected    ConnectivityState = "Connected"
+	ConnectivityStateDisconnected ConnectivityState = "Disconnected"
+)
+
+// ServerConnectivity describes the last known state of the pooled admin connection to a Kafka server.
+type ServerConnectivity struct {
+	State         ConnectivityState
+	LastError     string
+	LastChecked   time.Time
+	BrokerVersion string
+	// LastRotation is the time the connection was last rebuilt because its TLS material or credentials changed.
+	LastRotation time.Time
+}
+
+// pooledConnection is a long-lived sarama admin for a single Kafka server. KafkaIntentsAdmin instances are cheap
+// wrappers around it, created per call since the enforcement settings differ between callers.
+type pooledConnection struct {
+	admin                   sarama.ClusterAdmin
+	usernameMapping         string
+	previousUsernameMapping string
+	fingerprint             string
+	brokerVersion           string
+	// lastHealthCheck is only accessed while holding the lock of the server.
+	lastHealthCheck time.Time
+	// kafkaServer and tlsSource are what the connection was requested with, kept to check them for rotation.
+	kafkaServer otterizev1alpha3.KafkaServerConfig
+	tlsSource   otterizev1alpha3.TLSSource
+	// borrowers is the number of admins handed out for the connection and not closed yet. A retired connection was
+	// removed from the pool, and its admin is closed once the last borrower is done with it. Both are guarded by the
+	// lock of the pool.
+	borrowers int
+	retired   bool
+}
+
+// usernameMappings are the current username mapping of a server, and the one it replaced, if any.
+type usernameMappings struct {
+	current  string
+	previous string
+}
+
+type clusterAdminConnectFunc func(addrs []string, config *sarama.Config) (sarama.ClusterAdmin, error)
+
+// AdminPool keeps one connection per Kafka server. A connection is recycled when the server config, the TLS material
+// or the credentials it was created from change, and is health-checked before being handed out if it has been idle
+// for a while. The TLS material and credentials are only read again by the periodic rotation check, rather than on
+// every request for a connection whose server config didn't change.
+type AdminPool struct {
+	// lock guards the maps of the pool, and is never held while talking to a server. Connecting and health-checking
+	// are serialized per server by serverLocks instead, so that a slow server only holds up requests for itself.
+	lock         sync.Mutex
+	serverLocks  map[types.NamespacedName]*sync.Mutex
+	connections  map[types.NamespacedName]*pooledConnection
+	connectivity map[types.NamespacedName]ServerConnectivity
+	// usernameMappings outlive recycled connections, so that a mapping change is noticed across reconnects.
+	usernameMappings map[types.NamespacedName]usernameMappings
+	connect          clusterAdminConnectFunc
+	k8sClient        client.Client
+	statusTracker    *StatusTracker
+	now              func() time.Time
+	onRotation       func(kafkaServer otterizev1alpha3.KafkaServerConfig)
+}
+
+// The pool is added to the manager, which runs its periodic rotation check.
+var _ manager.LeaderElectionRunnable = &AdminPool{}
+var _ manager.Runnable = &AdminPool{}
+
+func newAdminPool(connect clusterAdminConnectFunc, statusTracker *StatusTracker) *AdminPool {
+	return &AdminPool{
+		serverLocks:      make(map[types.NamespacedName]*sync.Mutex),
+		connections:      make(map[types.NamespacedName]*pooledConnection),
+		connectivity:     make(map[types.NamespacedName]ServerConnectivity),
+		usernameMappings: make(map[types.NamespacedName]usernameMappings),
+		connect:          connect,
+		statusTracker:    statusTracker,
+		now:              time.Now,
+		onRotation:       statusTracker.notify,
+	}
+}
+
+// NewAdminPool returns a pool of admin connections to Kafka servers. k8sClient is used to read the Secrets referenced by
+// KafkaServerConfig SASL settings and to manage Strimzi KafkaUsers. It should not be backed by the manager's cache, so
+// that the operator doesn't need to watch all Secrets in the cluster. The results of operations on the servers are
+// recorded in statusTracker.
+func NewAdminPool(k8sClient client.Client, statusTracker *StatusTracker) *AdminPool {
+	pool := newAdminPool(sarama.NewClusterAdmin, statusTracker)
+	pool.k8sClient = k8sClient
+	return pool
+}
+
+func serverKey(kafkaServer otterizev1alpha3.KafkaServerConfig) types.NamespacedName {
+	return types.NamespacedName{Name: kafkaServer.Spec.Service.Name, Namespace: kafkaServer.Namespace}
+}
+
+// connectionInputs are the credentials and TLS material a connection is created from, and their fingerprint.
+type connectionInputs struct {
+	credentials *saslCredentials
+	material    *tlsMaterial
+	fingerprint string
+}
+
+func (p *AdminPool) serverLock(key types.NamespacedName) *sync.Mutex {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	serverLock, ok := p.serverLocks[key]
+	if !ok {
+		serverLock = &sync.Mutex{}
+		p.serverLocks[key] = serverLock
+	}
+	return serverLock
+}
+
+func (p *AdminPool) connection(key types.NamespacedName) *pooledConnection {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.connections[key]
+}
+
+// requestedWith returns whether the connection was requested for the same server config and TLS source. The inputs
+// read from them are then assumed unchanged until the periodic rotation check finds otherwise.
+func (conn *pooledConnection) requestedWith(kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource) bool {
+	return conn.kafkaServer.Namespace == kafkaServer.Namespace &&
+		reflect.DeepEqual(conn.kafkaServer.Spec, kafkaServer.Spec) &&
+		conn.tlsSource == tlsSource
+}
+
+func (p *AdminPool) get(kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource) (*pooledConnection, error) {
+	serverLock := p.serverLock(serverKey(kafkaServer))
+	serverLock.Lock()
+	defer serverLock.Unlock()
+	return p.getLocked(kafkaServer, tlsSource)
+}
+
+// borrow returns a connection to the server along with an admin for it. The admin stays usable until it is closed,
+// even if the connection is recycled or evicted in the meantime.
+func (p *AdminPool) borrow(kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource) (*pooledConnection, sarama.ClusterAdmin, error) {
+	serverLock := p.serverLock(serverKey(kafkaServer))
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	conn, err := p.getLocked(kafkaServer, tlsSource)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	conn.borrowers++
+	return conn, &borrowedClusterAdmin{ClusterAdmin: conn.admin, release: func() { p.release(conn) }}, nil
+}
+
+func (p *AdminPool) release(conn *pooledConnection) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	conn.borrowers--
+	if conn.retired && conn.borrowers == 0 {
+		closeClusterAdmin(conn.admin)
+	}
+}
+
+// getLocked returns the connection to the server, connecting to it if needed. The caller holds the lock of the server.
+func (p *AdminPool) getLocked(kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource) (*pooledConnection, error) {
+	key := serverKey(kafkaServer)
+	logger := logrus.WithField("server", key.String())
+	if conn := p.connection(key); conn != nil && conn.requestedWith(kafkaServer, tlsSource) {
+		if p.now().Sub(conn.lastHealthCheck) < healthCheckInterval {
+			return conn, nil
+		}
+		_, _, err := conn.admin.DescribeCluster()
+		if err == nil {
+			conn.lastHealthCheck = p.now()
+			p.setConnectivity(key, nil, conn.brokerVersion)
+			return conn, nil
+		}
+		logger.WithError(err).Warning("Kafka admin connection failed health check, reconnecting")
+		p.close(key, conn)
+	}
+
+	inputs, err := p.resolveInputs(kafkaServer, tlsSource)
+	if err != nil {
+		p.setConnectivity(key, err, "")
+		return nil, err
+	}
+	return p.connectLocked(key, kafkaServer, tlsSource, inputs)
+}
+
+// connectLocked returns the connection of the server if it was created from the same inputs, and otherwise replaces
+// it with a new one. The caller holds the lock of the server.
+func (p *AdminPool) connectLocked(key types.NamespacedName, kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource, inputs connectionInputs) (*pooledConnection, error) {
+	rotated := false
+	if conn := p.connection(key); conn != nil {
+		if conn.fingerprint == inputs.fingerprint {
+			conn.kafkaServer = kafkaServer
+			conn.tlsSource = tlsSource
+			return conn, nil
+		}
+		logrus.WithField("server", key.String()).Info("Kafka server config, TLS material or credentials changed, recycling admin connection")
+		p.close(key, conn)
+		rotated = true
+	}
+
+	conn, version, err := p.dial(kafkaServer, inputs.material, inputs.credentials)
+	if err != nil {
+		p.setConnectivity(key, err, "")
+		return nil, err
+	}
+	conn.fingerprint = inputs.fingerprint
+	conn.lastHealthCheck = p.now()
+	conn.kafkaServer = kafkaServer
+	conn.tlsSource = tlsSource
+	conn.brokerVersion = version.String()
+
+	p.lock.Lock()
+	p.trackUsernameMappingLocked(key, conn)
+	p.connections[key] = conn
+	p.setConnectivityLocked(key, nil, conn.brokerVersion)
+	if rotated {
+		connectivity := p.connectivity[key]
+		connectivity.LastRotation = p.now()
+		p.connectivity[key] = connectivity
+	}
+	p.lock.Unlock()
+
+	if rotated {
+		// The server is resynced so that ACLs follow a principal mapping recomputed from the new certificate. This is
+		// done without holding the lock of the pool, since the callback may block or use the pool.
+		p.onRotation(kafkaServer)
+	}
+	return conn, nil
+}
+
+// trackUsernameMappingLocked remembers the previous username mapping of a server when a new certificate changes it, so
+// that ACLs created under the previous mapping are cleaned up rather than left behind.
+func (p *AdminPool) trackUsernameMappingLocked(key types.NamespacedName, conn *pooledConnection) {
+	mappings, ok := p.usernameMappings[key]
+	if ok && mappings.current != conn.usernameMapping {
+		logrus.WithFields(logrus.Fields{"server": key.String(), "previous": mappings.current, "current": conn.usernameMapping}).Info("Kafka principal mapping changed")
+		mappings.previous = mappings.current
+	}
+	mappings.current = conn.usernameMapping
+	conn.previousUsernameMapping = mappings.previous
+	p.usernameMappings[key] = mappings
+}
+
+// resolveInputs reads the credentials and TLS material of a server, and fingerprints them along with its config.
+func (p *AdminPool) resolveInputs(kafkaServer otterizev1alpha3.KafkaServerConfig, tlsSource otterizev1alpha3.TLSSource) (connectionInputs, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretReadTimeout)
+	defer cancel()
+
+	credentials, err := resolveSASLCredentials(ctx, p.k8sClient, kafkaServer.Namespace, kafkaServer.Spec.SASL)
+	if err != nil {
+		return connectionInputs{}, err
+	}
+	material, err := loadTLSMaterial(ctx, p.k8sClient, kafkaServer.Namespace, tlsSource)
+	if err != nil {
+		return connectionInputs{}, err
+	}
+	fingerprint, err := connectionFingerprint(kafkaServer, material, credentials)
+	if err != nil {
+		return connectionInputs{}, err
+	}
+	return connectionInputs{credentials: credentials, material: material, fingerprint: fingerprint}, nil
+}
+
+// NeedLeaderElection is true since only the leader reconciles Kafka servers, so other replicas hold no connections.
+func (p *AdminPool) NeedLeaderElection() bool {
+	return true
+}
+
+// Start periodically rebuilds pooled connections whose TLS material or credentials were rotated, so that certificates
+// renewed by cert-manager, SPIRE or a Secret update are used before the previous ones expire, even if the server isn't
+// reconciled in the meantime.
+func (p *AdminPool) Start(ctx context.Context) error {
+	ticker := time.NewTicker(credentialsCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.refresh()
+		}
+	}
+}
+
+func (p *AdminPool) refresh() {
+	p.lock.Lock()
+	keys := lo.Keys(p.connections)
+	p.lock.Unlock()
+
+	for _, key := range keys {
+		p.refreshConnection(key)
+	}
+}
+
+func (p *AdminPool) refreshConnection(key types.NamespacedName) {
+	serverLock := p.serverLock(key)
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	conn := p.connection(key)
+	if conn == nil {
+		// Evicted since the connections were listed.
+		return
+	}
+	inputs, err := p.resolveInputs(conn.kafkaServer, conn.tlsSource)
+	if err != nil {
+		logrus.WithError(err).WithField("server", key.String()).Warning("Failed checking Kafka TLS material and credentials for rotation")
+		return
+	}
+	if _, err := p.connectLocked(key, conn.kafkaServer, conn.tlsSource, inputs); err != nil {
+		logrus.WithError(err).WithField("server", key.String()).Error("Failed reconnecting to Kafka server with rotated TLS material or credentials")
+	}
+}
+
+// dial connects to the server with the version required for ACL management, then reconnects with the newest
+// version the broker supports so that other admin requests use up to date protocol versions.
+func (p *AdminPool) dial(kafkaServer otterizev1alpha3.KafkaServerConfig, material *tlsMaterial, credentials *saslCredentials) (*pooledConnection, sarama.KafkaVersion, error) {
+	logger := logrus.WithField("addr", kafkaServer.Spec.Addr)
+	logger.Info("Connecting to kafka server")
+
+	config, usernameMapping, err := newSaramaConfig(kafkaServer, material, credentials)
+	if err != nil {
+		return nil, sarama.KafkaVersion{}, err
+	}
+	addrs := []string{kafkaServer.Spec.Addr}
+
+	admin, err := p.connect(addrs, config)
+	if err != nil {
+		return nil, sarama.KafkaVersion{}, err
+	}
+
+	version := negotiateVersion(admin)
+	if version.IsAtLeast(sarama.V2_0_0_0) && version != config.Version {
+		logger.WithField("version", version.String()).Info("Reconnecting with negotiated Kafka version")
+		negotiatedConfig := *config
+		negotiatedConfig.Version = version
+		negotiatedAdmin, err := p.connect(addrs, &negotiatedConfig)
+		if err == nil {
+			closeClusterAdmin(admin)
+			admin = negotiatedAdmin
+		} else {
+			logger.WithError(err).Warning("Failed reconnecting with negotiated Kafka version, keeping initial connection")
+			version = config.Version
+		}
+	} else {
+		version = config.Version
+	}
+
+	return &pooledConnection{admin: admin, usernameMapping: usernameMapping}, version, nil
+}
+
+func (p *AdminPool) evict(key types.NamespacedName) {
+	serverLock := p.serverLock(key)
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// The lock of the server is kept, since callers may already be waiting on it.
+	p.closeLocked(key)
+	delete(p.connectivity, key)
+	delete(p.usernameMappings, key)
+	prometheus.DeleteKafkaServerConnected(key.String())
+}
+
+// close closes the connection of the server, unless it was already replaced.
+func (p *AdminPool) close(key types.NamespacedName, conn *pooledConnection) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.connections[key] == conn {
+		p.closeLocked(key)
+	}
+}
+
+// closeLocked removes the connection of the server from the pool, and closes its admin unless it is still borrowed.
+func (p *AdminPool) closeLocked(key types.NamespacedName) {
+	conn, ok := p.connections[key]
+	if !ok {
+		return
+	}
+	delete(p.connections, key)
+	conn.retired = true
+	if conn.borrowers == 0 {
+		closeClusterAdmin(conn.admin)
+	}
+}
+
+func (p *AdminPool) getConnectivity(key types.NamespacedName) (ServerConnectivity, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	connectivity, ok := p.connectivity[key]
+	return connectivity, ok
+}
+
+func (p *AdminPool) setConnectivity(key types.NamespacedName, err error, brokerVersion string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.setConnectivityLocked(key, err, brokerVersion)
+}
+
+func (p *AdminPool) setConnectivityLocked(key types.NamespacedName, err error, brokerVersion string) {
+	connectivity := ServerConnectivity{
+		State:         ConnectivityStateConnected,
+		LastChecked:   p.now(),
+		BrokerVersion: brokerVersion,
+		LastRotation:  p.connectivity[key].LastRotation,
+	}
+	if err != nil {
+		connectivity.State = ConnectivityStateDisconnected
+		connectivity.LastError = err.Error()
+	}
+	p.connectivity[key] = connectivity
+	prometheus.SetKafkaServerConnected(key.String(), err == nil)
+}
+
+func closeClusterAdmin(admin sarama.ClusterAdmin) {
+	if err := admin.Close(); err != nil {
+		logrus.WithError(err).Error("Error closing kafka admin client")
+	}
+}
+
+// negotiateVersion maps the API versions advertised by the controller to the newest Kafka version sarama knows it can
+// safely speak. Falls back to the ACL baseline if the broker can't be queried.
+func negotiateVersion(admin sarama.ClusterAdmin) sarama.KafkaVersion {
+	controller, err := admin.Controller()
+	if err != nil {
+		logrus.WithError(err).Warning("Failed getting Kafka controller, using default Kafka version")
+		return sarama.V2_0_0_0
+	}
+	response, err := controller.ApiVersions(&sarama.ApiVersionsRequest{})
+	if err != nil {
+		logrus.WithError(err).Warning("Failed querying Kafka API versions, using default Kafka version")
+		return sarama.V2_0_0_0
+	}
+
+	maxVersions := make(map[int16]int16, len(response.ApiKeys))
+	for _, apiKey := range response.ApiKeys {
+		maxVersions[apiKey.ApiKey] = apiKey.MaxVersion
+	}
+	return versionFromAPIVersions(maxVersions)
+}
+
+func versionFromAPIVersions(maxVersions map[int16]int16) sarama.KafkaVersion {
+	if _, ok := maxVersions[describeClusterAPIKey]; ok {
+		return sarama.V2_8_0_0
+	}
+	if maxVersions[apiVersionsAPIKey] >= 3 {
+		return sarama.V2_4_0_0
+	}
+	if _, ok := maxVersions[incrementalAlterConfigsAPIKey]; ok {
+		return sarama.V2_3_0_0
+	}
+	if maxVersions[describeAclsAPIKey] >= 1 {
+		return sarama.V2_0_0_0
+	}
+	return sarama.V1_0_0_0
+}
+
+// connectionFingerprint identifies the inputs a connection was created from. The TLS material and SASL credentials are
+// part of the hash, so rotating the certificate files or the referenced Secrets recycles the connection.
+func connectionFingerprint(kafkaServer otterizev1alpha3.KafkaServerConfig, material *tlsMaterial, credentials *saslCredentials) (string, error) {
+	serialized, err := json.Marshal(struct {
+		Addr              string
+		TLS               *tlsMaterial
+		SASL              *otterizev1alpha3.SASLConfig
+		Credentials       *saslCredentials
+		PrincipalTemplate string
+	}{
+		Addr:              kafkaServer.Spec.Addr,
+		TLS:               material,
+		SASL:              kafkaServer.Spec.SASL,
+		Credentials:       credentials,
+		PrincipalTemplate: kafkaServer.Spec.PrincipalTemplate,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// borrowedClusterAdmin hands out a pooled connection. Closing it returns the connection to the pool rather than
+// closing it.
+type borrowedClusterAdmin struct {
+	sarama.ClusterAdmin
+	release     func()
+	releaseOnce sync.Once
+}
+
+func (a *borrowedClusterAdmin) Close() error {
+	a.releaseOnce.Do(a.release)
+	return nil
+}
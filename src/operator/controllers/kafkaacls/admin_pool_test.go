@@ -0,0 +1,297 @@
+package kafkaacls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	intentsreconcilersmocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+type AdminPoolTestSuite struct {
+	suite.Suite
+	controller  *gomock.Controller
+	pool        *AdminPool
+	connections []*kafkaaclsmocks.MockClusterAdmin
+	currentTime time.Time
+	tlsSource   otterizev1alpha3.TLSSource
+	server      otterizev1alpha3.KafkaServerConfig
+	rotated     []otterizev1alpha3.KafkaServerConfig
+}
+
+func (s *AdminPoolTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.connections = nil
+	s.currentTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.pool = newAdminPool(func(addrs []string, config *sarama.Config) (sarama.ClusterAdmin, error) {
+		s.Require().Equal([]string{serverAddress}, addrs)
+		s.Require().Equal(sarama.V2_0_0_0, config.Version)
+		admin := kafkaaclsmocks.NewMockClusterAdmin(s.controller)
+		admin.EXPECT().Controller().Return(nil, errors.New("no controller")).AnyTimes()
+		s.connections = append(s.connections, admin)
+		return admin, nil
+	}, NewStatusTracker())
+	s.pool.now = func() time.Time { return s.currentTime }
+	s.rotated = nil
+	s.pool.onRotation = func(kafkaServer otterizev1alpha3.KafkaServerConfig) {
+		// The rotation is recorded by the time the callback runs, and the callback may use the pool.
+		connectivity, ok := s.pool.getConnectivity(serverKey(kafkaServer))
+		s.Require().True(ok)
+		s.Require().Equal(s.currentTime, connectivity.LastRotation)
+		s.rotated = append(s.rotated, kafkaServer)
+	}
+	s.tlsSource = s.writeTLSFiles(s.T().TempDir(), "otterize")
+	s.server = otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec: otterizev1alpha3.KafkaServerConfigSpec{
+			Service: otterizev1alpha3.Service{Name: serverName},
+			Addr:    serverAddress,
+		},
+	}
+}
+
+func (s *AdminPoolTestSuite) writeTLSFiles(dir string, organization string) otterizev1alpha3.TLSSource {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "intents-operator", Organization: []string{organization}},
+		NotBefore:    s.currentTime,
+		NotAfter:     s.currentTime.Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	s.Require().NoError(err)
+
+	tlsSource := otterizev1alpha3.TLSSource{
+		CertFile:   filepath.Join(dir, "cert.pem"),
+		KeyFile:    filepath.Join(dir, "key.pem"),
+		RootCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	s.Require().NoError(os.WriteFile(tlsSource.CertFile, certPEM, 0600))
+	s.Require().NoError(os.WriteFile(tlsSource.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	s.Require().NoError(os.WriteFile(tlsSource.RootCAFile, certPEM, 0600))
+	return tlsSource
+}
+
+func (s *AdminPoolTestSuite) TestConnectionIsReused() {
+	first, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	second, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	s.Require().Same(first, second)
+	s.Require().Len(s.connections, 1)
+	s.Require().Equal("CN=$ServiceName.$Namespace,O=otterize", first.usernameMapping)
+
+	connectivity, ok := s.pool.getConnectivity(serverKey(s.server))
+	s.Require().True(ok)
+	s.Require().Equal(ConnectivityStateConnected, connectivity.State)
+	s.Require().Equal(sarama.V2_0_0_0.String(), connectivity.BrokerVersion)
+}
+
+func (s *AdminPoolTestSuite) TestHealthCheckFailureReconnects() {
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	s.currentTime = s.currentTime.Add(healthCheckInterval)
+	s.connections[0].EXPECT().DescribeCluster().Return(nil, int32(0), errors.New("broken pipe"))
+	s.connections[0].EXPECT().Close().Return(nil)
+	_, err = s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Len(s.connections, 2)
+
+	s.currentTime = s.currentTime.Add(healthCheckInterval)
+	s.connections[1].EXPECT().DescribeCluster().Return(nil, int32(0), nil)
+	_, err = s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Len(s.connections, 2)
+}
+
+func (s *AdminPoolTestSuite) TestConfigChangeRecyclesConnection() {
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	s.connections[0].EXPECT().Close().Return(nil)
+	s.tlsSource = s.writeTLSFiles(s.T().TempDir(), "otterize")
+	_, err = s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Len(s.connections, 2)
+}
+
+func (s *AdminPoolTestSuite) TestRotatedCertificateRecomputesUsernameMapping() {
+	first, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Empty(first.previousUsernameMapping)
+
+	// The certificate is rotated in place, to one with a different subject, and picked up by the periodic check.
+	s.currentTime = s.currentTime.Add(time.Minute)
+	rotatedSource := s.writeTLSFiles(s.T().TempDir(), "otterize-rotated")
+	for _, path := range []string{rotatedSource.CertFile, rotatedSource.KeyFile, rotatedSource.RootCAFile} {
+		content, err := os.ReadFile(path)
+		s.Require().NoError(err)
+		s.Require().NoError(os.WriteFile(filepath.Join(filepath.Dir(s.tlsSource.CertFile), filepath.Base(path)), content, 0600))
+	}
+	s.connections[0].EXPECT().Close().Return(nil)
+	s.pool.refresh()
+
+	s.Require().Len(s.connections, 2)
+	s.Require().Equal([]otterizev1alpha3.KafkaServerConfig{s.server}, s.rotated)
+	second, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Equal("CN=$ServiceName.$Namespace,O=otterize-rotated", second.usernameMapping)
+	s.Require().Equal("CN=$ServiceName.$Namespace,O=otterize", second.previousUsernameMapping)
+
+	connectivity, ok := s.pool.getConnectivity(serverKey(s.server))
+	s.Require().True(ok)
+	s.Require().Equal(s.currentTime, connectivity.LastRotation)
+
+	// Nothing changed since, so the next check keeps the connection.
+	s.pool.refresh()
+	s.Require().Len(s.connections, 2)
+	s.Require().Len(s.rotated, 1)
+}
+
+func (s *AdminPoolTestSuite) TestTLSMaterialFromSecret() {
+	certPEM, err := os.ReadFile(s.tlsSource.CertFile)
+	s.Require().NoError(err)
+	keyPEM, err := os.ReadFile(s.tlsSource.KeyFile)
+	s.Require().NoError(err)
+
+	k8sClient := intentsreconcilersmocks.NewMockClient(s.controller)
+	k8sClient.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "kafka-tls", Namespace: testNamespace}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, secret *corev1.Secret, _ ...client.GetOption) error {
+			secret.Data = map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM, tlsSecretRootCAKey: certPEM}
+			return nil
+		}).AnyTimes()
+	s.pool.k8sClient = k8sClient
+
+	// The secret takes precedence over the files, which don't exist.
+	tlsSource := otterizev1alpha3.TLSSource{SecretName: "kafka-tls", CertFile: filepath.Join(s.T().TempDir(), "missing.pem")}
+	conn, err := s.pool.get(s.server, tlsSource)
+	s.Require().NoError(err)
+	s.Require().Equal("CN=$ServiceName.$Namespace,O=otterize", conn.usernameMapping)
+}
+
+func (s *AdminPoolTestSuite) TestMissingTLSFilesReportDisconnected() {
+	s.tlsSource.CertFile = filepath.Join(s.T().TempDir(), "missing.pem")
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().Error(err)
+
+	connectivity, ok := s.pool.getConnectivity(serverKey(s.server))
+	s.Require().True(ok)
+	s.Require().Equal(ConnectivityStateDisconnected, connectivity.State)
+	s.Require().NotEmpty(connectivity.LastError)
+}
+
+func (s *AdminPoolTestSuite) TestEvictClosesConnection() {
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	s.connections[0].EXPECT().Close().Return(nil)
+	s.pool.evict(serverKey(s.server))
+	_, ok := s.pool.getConnectivity(serverKey(s.server))
+	s.Require().False(ok)
+}
+
+func (s *AdminPoolTestSuite) TestInputsNotReadAgainForUnchangedServer() {
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	// The files are only read again by the periodic rotation check.
+	s.Require().NoError(os.Remove(s.tlsSource.CertFile))
+	s.currentTime = s.currentTime.Add(healthCheckInterval)
+	s.connections[0].EXPECT().DescribeCluster().Return(nil, int32(0), nil)
+	_, err = s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Len(s.connections, 1)
+}
+
+func (s *AdminPoolTestSuite) TestSlowServerDoesNotBlockOtherServers() {
+	slowServerAddress := "slow-kafka:9092"
+	connecting := make(chan struct{})
+	unblock := make(chan struct{})
+	s.pool.connect = func(addrs []string, config *sarama.Config) (sarama.ClusterAdmin, error) {
+		if addrs[0] == slowServerAddress {
+			close(connecting)
+			<-unblock
+			return nil, errors.New("connection timed out")
+		}
+		admin := kafkaaclsmocks.NewMockClusterAdmin(s.controller)
+		admin.EXPECT().Controller().Return(nil, errors.New("no controller")).AnyTimes()
+		return admin, nil
+	}
+	slowServer := *s.server.DeepCopy()
+	slowServer.Spec.Service.Name = "slow-kafka"
+	slowServer.Spec.Addr = slowServerAddress
+
+	slowErr := make(chan error)
+	go func() {
+		_, err := s.pool.get(slowServer, s.tlsSource)
+		slowErr <- err
+	}()
+	<-connecting
+
+	_, err := s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	connectivity, ok := s.pool.getConnectivity(serverKey(s.server))
+	s.Require().True(ok)
+	s.Require().Equal(ConnectivityStateConnected, connectivity.State)
+
+	close(unblock)
+	s.Require().Error(<-slowErr)
+}
+
+func (s *AdminPoolTestSuite) TestBorrowedAdminClosedAfterLastBorrower() {
+	_, first, err := s.pool.borrow(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	_, second, err := s.pool.borrow(s.server, s.tlsSource)
+	s.Require().NoError(err)
+
+	// The connection is recycled while borrowed, and stays usable by the borrowers.
+	s.tlsSource = s.writeTLSFiles(s.T().TempDir(), "otterize")
+	_, err = s.pool.get(s.server, s.tlsSource)
+	s.Require().NoError(err)
+	s.Require().Len(s.connections, 2)
+	s.connections[0].EXPECT().ListAcls(gomock.Any()).Return(nil, nil)
+	_, err = first.ListAcls(sarama.AclFilter{})
+	s.Require().NoError(err)
+
+	s.Require().NoError(first.Close())
+	// Closing twice doesn't release the connection of the other borrower.
+	s.Require().NoError(first.Close())
+	s.connections[0].EXPECT().Close().Return(nil)
+	s.Require().NoError(second.Close())
+}
+
+func (s *AdminPoolTestSuite) TestVersionFromAPIVersions() {
+	s.Require().Equal(sarama.V2_8_0_0, versionFromAPIVersions(map[int16]int16{describeClusterAPIKey: 0, apiVersionsAPIKey: 3}))
+	s.Require().Equal(sarama.V2_4_0_0, versionFromAPIVersions(map[int16]int16{apiVersionsAPIKey: 3, describeAclsAPIKey: 2}))
+	s.Require().Equal(sarama.V2_3_0_0, versionFromAPIVersions(map[int16]int16{incrementalAlterConfigsAPIKey: 0, describeAclsAPIKey: 1}))
+	s.Require().Equal(sarama.V2_0_0_0, versionFromAPIVersions(map[int16]int16{describeAclsAPIKey: 1}))
+	s.Require().Equal(sarama.V1_0_0_0, versionFromAPIVersions(map[int16]int16{describeAclsAPIKey: 0}))
+}
+
+func TestAdminPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminPoolTestSuite))
+}
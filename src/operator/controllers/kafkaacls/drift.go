@@ -0,0 +1,119 @@
+package kafkaacls
+
+import (
+	"context"
+	"fmt"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const driftAuditSubject = "drift"
+
+// SyncClientIntents compares the topic ACLs of the principals the operator owns with the intents of all clients of the
+// server, creating missing ACLs and deleting ones no client asks for, and returns the number of ACLs of each kind. The
+// operator owns the principals of the clients, under the current and the previous username mapping, and the
+// managedPrincipals it created ACLs for before. ACLs of any other principal are left alone, even if it looks like one
+// the username mapping could produce.
+func (a *KafkaIntentsAdminImpl) SyncClientIntents(ctx context.Context, intentsByClient map[types.NamespacedName][]otterizev1alpha3.Intent, managedPrincipals []string) (int, int, error) {
+	logger := logrus.WithFields(
+		logrus.Fields{
+			"serverName":      a.kafkaServer.Spec.Service,
+			"serverNamespace": a.kafkaServer.Namespace,
+		})
+
+	expected := TopicToACLList{}
+	managedACLsByPrincipal := make(map[string]int)
+	ownedPrincipals := sets.New(managedPrincipals...)
+	for clientName, intents := range intentsByClient {
+		principal := a.formatPrincipal(clientName.Name, clientName.Namespace)
+		ownedPrincipals.Insert(principal)
+		if a.previousUserNameMapping != "" {
+			ownedPrincipals.Insert(fmt.Sprintf("User:%s", formatUsername(a.previousUserNameMapping, clientName.Name, clientName.Namespace)))
+		}
+		topics := lo.Flatten(lo.Map(intents, func(intent otterizev1alpha3.Intent, _ int) []otterizev1alpha3.KafkaTopic {
+			return intent.Topics
+		}))
+		clientACLs, err := a.collectTopicsToACLList(principal, topics)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed collecting topics to ACL list %w", err)
+		}
+		for resource, acls := range clientACLs {
+			expected[resource] = lo.Uniq(append(expected[resource], acls...))
+		}
+		if len(topics) > 0 {
+			managedACLsByPrincipal[principal] = lo.SumBy(lo.Values(clientACLs), func(acls []sarama.Acl) int { return len(acls) })
+		}
+	}
+
+	applied, err := a.queryOwnedPrincipalsACLs(ownedPrincipals)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resourceAclsCreate, resourceAclsDelete := a.kafkaResourceAclsDiff(expected, applied)
+	missing, unexpected := 0, countACLs(resourceAclsDelete)
+
+	if len(resourceAclsCreate) > 0 {
+		if a.enforcementEnabledForServer && a.enableKafkaACLCreation {
+			missing = countACLs(resourceAclsCreate)
+			logger.Infof("Creating %d missing ACLs", missing)
+			if err := a.kafkaAdminClient.CreateACLs(resourceAclsCreate); err != nil {
+				return missing, unexpected, fmt.Errorf("failed applying ACLs to server: %w", err)
+			}
+			a.auditACLs(ctx, auditlog.ActionCreated, driftAuditSubject, a.serverTrigger(), nil, resourceAclsCreate)
+		} else {
+			logger.Debugf("Skipped creation of %d missing ACLs because enforcement or Kafka ACL creation is disabled", countACLs(resourceAclsCreate))
+		}
+	}
+
+	if len(resourceAclsDelete) > 0 {
+		logger.Infof("Deleting %d unexpected ACLs", unexpected)
+		if err := a.deleteResourceAcls(resourceAclsDelete); err != nil {
+			return missing, unexpected, fmt.Errorf("failed deleting ACLs on server: %w", err)
+		}
+		a.auditACLs(ctx, auditlog.ActionDeleted, driftAuditSubject, a.serverTrigger(), resourceAclsDelete, nil)
+	}
+
+	if !a.enforcementEnabledForServer || !a.enableKafkaACLCreation {
+		managedACLsByPrincipal = lo.MapValues(managedACLsByPrincipal, func(int, string) int { return 0 })
+	}
+	a.statusTracker.recordSync(a.kafkaServer, managedACLsByPrincipal)
+	return missing, unexpected, nil
+}
+
+func (a *KafkaIntentsAdminImpl) queryOwnedPrincipalsACLs(ownedPrincipals sets.Set[string]) (TopicToACLList, error) {
+	resourceAclsList, err := a.kafkaAdminClient.ListAcls(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceTopic,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		PermissionType:            sarama.AclPermissionAllow,
+		Operation:                 sarama.AclOperationAny,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing ACLs on server: %w", err)
+	}
+
+	applied := TopicToACLList{}
+	for _, resourceAcls := range resourceAclsList {
+		for _, acl := range resourceAcls.Acls {
+			if acl.Principal == AnonymousUserPrincipalName || acl.Principal == AnyUserPrincipalName {
+				continue
+			}
+			if !ownedPrincipals.Has(acl.Principal) {
+				continue
+			}
+			applied[resourceAcls.Resource] = append(applied[resourceAcls.Resource], lo.FromPtr(acl))
+		}
+	}
+	return applied, nil
+}
+
+func countACLs(resourceAcls []*sarama.ResourceAcls) int {
+	return lo.SumBy(resourceAcls, func(acls *sarama.ResourceAcls) int {
+		return len(acls.Acls)
+	})
+}
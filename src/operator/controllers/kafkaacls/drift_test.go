@@ -0,0 +1,150 @@
+package kafkaacls
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"testing"
+)
+
+const driftUsernameMapping = "CN=$ServiceName.$Namespace,O=otterize"
+
+type ACLDriftTestSuite struct {
+	suite.Suite
+	mockClusterAdmin *kafkaaclsmocks.MockClusterAdmin
+}
+
+func (s *ACLDriftTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.mockClusterAdmin = kafkaaclsmocks.NewMockClusterAdmin(controller)
+}
+
+func (s *ACLDriftTestSuite) intentsAdmin(enforcementEnabled bool) KafkaIntentsAdmin {
+	kafkaServerConfig := otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: serverName}, Addr: serverAddress},
+	}
+	return NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, driftUsernameMapping, true, enforcementEnabled, NewStatusTracker())
+}
+
+func topicACLs(topic string, principal string, operations ...sarama.AclOperation) sarama.ResourceAcls {
+	return sarama.ResourceAcls{
+		Resource: sarama.Resource{ResourceType: sarama.AclResourceTopic, ResourceName: topic, ResourcePatternType: sarama.AclPatternLiteral},
+		Acls: lo.Map(operations, func(operation sarama.AclOperation, _ int) *sarama.Acl {
+			return &sarama.Acl{Principal: principal, Host: "*", Operation: operation, PermissionType: sarama.AclPermissionAllow}
+		}),
+	}
+}
+
+func (s *ACLDriftTestSuite) expectListACLs(acls ...sarama.ResourceAcls) {
+	s.mockClusterAdmin.EXPECT().ListAcls(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceTopic,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		PermissionType:            sarama.AclPermissionAllow,
+		Operation:                 sarama.AclOperationAny,
+	}).Return(acls, nil)
+}
+
+func (s *ACLDriftTestSuite) intentsByClient() map[types.NamespacedName][]otterizev1alpha3.Intent {
+	return map[types.NamespacedName][]otterizev1alpha3.Intent{
+		{Name: "client", Namespace: testNamespace}: {{
+			Name:   serverName,
+			Type:   otterizev1alpha3.IntentTypeKafka,
+			Topics: []otterizev1alpha3.KafkaTopic{{Name: "orders", Operations: []otterizev1alpha3.KafkaOperation{otterizev1alpha3.KafkaOperationConsume}}},
+		}},
+	}
+}
+
+func (s *ACLDriftTestSuite) TestNoDrift() {
+	s.expectListACLs(topicACLs("orders", "User:CN=client.test-namespace,O=otterize", sarama.AclOperationRead))
+
+	missing, unexpected, err := s.intentsAdmin(true).SyncClientIntents(context.Background(), s.intentsByClient(), nil)
+	s.Require().NoError(err)
+	s.Require().Zero(missing)
+	s.Require().Zero(unexpected)
+}
+
+func (s *ACLDriftTestSuite) TestRepairsMissingAndUnexpectedACLs() {
+	s.expectListACLs(
+		topicACLs("orders", "User:CN=removed.test-namespace,O=otterize", sarama.AclOperationWrite),
+		// Principals that the operator did not create are ignored, even if the username mapping could produce them.
+		topicACLs("orders", "User:admin", sarama.AclOperationAll),
+		topicACLs("orders", "User:CN=unknown.test-namespace,O=otterize", sarama.AclOperationAll),
+		topicACLs("orders", AnyUserPrincipalName, sarama.AclOperationAll),
+	)
+	expectedCreate := topicACLs("orders", "User:CN=client.test-namespace,O=otterize", sarama.AclOperationRead)
+	s.mockClusterAdmin.EXPECT().CreateACLs(MatchResourceAcls([]*sarama.ResourceAcls{&expectedCreate})).Return(nil)
+	s.mockClusterAdmin.EXPECT().DeleteACL(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceTopic,
+		ResourceName:              lo.ToPtr("orders"),
+		ResourcePatternTypeFilter: sarama.AclPatternLiteral,
+		PermissionType:            sarama.AclPermissionAllow,
+		Operation:                 sarama.AclOperationWrite,
+		Principal:                 lo.ToPtr("User:CN=removed.test-namespace,O=otterize"),
+		Host:                      lo.ToPtr("*"),
+	}, false).Return(nil, nil)
+
+	missing, unexpected, err := s.intentsAdmin(true).SyncClientIntents(context.Background(), s.intentsByClient(), []string{"User:CN=removed.test-namespace,O=otterize"})
+	s.Require().NoError(err)
+	s.Require().Equal(1, missing)
+	s.Require().Equal(1, unexpected)
+}
+
+func (s *ACLDriftTestSuite) TestRemovesACLsOfPreviousUsernameMapping() {
+	s.expectListACLs(
+		topicACLs("orders", "User:CN=client.test-namespace,O=otterize", sarama.AclOperationRead),
+		topicACLs("orders", "User:CN=client.test-namespace,O=previous", sarama.AclOperationRead),
+	)
+	s.mockClusterAdmin.EXPECT().DeleteACL(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceTopic,
+		ResourceName:              lo.ToPtr("orders"),
+		ResourcePatternTypeFilter: sarama.AclPatternLiteral,
+		PermissionType:            sarama.AclPermissionAllow,
+		Operation:                 sarama.AclOperationRead,
+		Principal:                 lo.ToPtr("User:CN=client.test-namespace,O=previous"),
+		Host:                      lo.ToPtr("*"),
+	}, false).Return(nil, nil)
+
+	intentsAdmin := s.intentsAdmin(true).(*KafkaIntentsAdminImpl)
+	intentsAdmin.previousUserNameMapping = "CN=$ServiceName.$Namespace,O=previous"
+	missing, unexpected, err := intentsAdmin.SyncClientIntents(context.Background(), s.intentsByClient(), nil)
+	s.Require().NoError(err)
+	s.Require().Zero(missing)
+	s.Require().Equal(1, unexpected)
+}
+
+func (s *ACLDriftTestSuite) TestMissingACLsNotCreatedWithoutEnforcement() {
+	s.expectListACLs()
+
+	missing, unexpected, err := s.intentsAdmin(false).SyncClientIntents(context.Background(), s.intentsByClient(), nil)
+	s.Require().NoError(err)
+	s.Require().Zero(missing)
+	s.Require().Zero(unexpected)
+}
+
+func (s *ACLDriftTestSuite) TestUnrelatedPrincipalsSurviveWithDefaultUsernameMapping() {
+	kafkaServerConfig := otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: serverName}, Addr: serverAddress},
+	}
+	intentsAdmin := NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "$ServiceName.$Namespace", true, true, NewStatusTracker())
+	s.expectListACLs(
+		topicACLs("orders", "User:client.test-namespace", sarama.AclOperationRead),
+		topicACLs("orders", "User:foo.bar", sarama.AclOperationWrite),
+	)
+
+	missing, unexpected, err := intentsAdmin.SyncClientIntents(context.Background(), s.intentsByClient(), nil)
+	s.Require().NoError(err)
+	s.Require().Zero(missing)
+	s.Require().Zero(unexpected)
+}
+
+func TestACLDriftTestSuite(t *testing.T) {
+	suite.Run(t, new(ACLDriftTestSuite))
+}
@@ -1,18 +1,23 @@
 package kafkaacls
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"github.com/Shopify/sarama"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/lox"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	"github.com/vishalkuo/bimap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"log"
 	"os"
 	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
 )
 
@@ -24,6 +29,8 @@ const (
 	intentsOperatorClientID    = "intents-operator"
 	AnonymousUserPrincipalName = "User:ANONYMOUS"
 	AnyUserPrincipalName       = "User:*"
+	topicsConfAuditSubject     = "topics-config"
+	tlsSecretRootCAKey         = "ca.crt"
 )
 
 var (
@@ -32,19 +39,26 @@ var (
 )
 
 type KafkaIntentsAdmin interface {
-	ApplyServerTopicsConf(topicsConf []otterizev1alpha3.TopicConfig) error
-	ApplyClientIntents(clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) error
-	RemoveClientIntents(clientName string, clientNamespace string) error
-	RemoveServerIntents(topicsConf []otterizev1alpha3.TopicConfig) error
+	ApplyServerTopicsConf(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error
+	ApplyClientIntents(ctx context.Context, clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) error
+	RemoveClientIntents(ctx context.Context, clientName string, clientNamespace string) error
+	RemoveServerIntents(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error
+	SyncClientIntents(ctx context.Context, intentsByClient map[types.NamespacedName][]otterizev1alpha3.Intent, managedPrincipals []string) (missing int, unexpected int, err error)
+	ApplyManagedTopics(ctx context.Context, topics []otterizev1alpha3.ManagedKafkaTopic) error
+	DeleteManagedTopics(ctx context.Context, topicNames []string) error
 	Close()
 }
 
 type KafkaIntentsAdminImpl struct {
-	kafkaServer                 otterizev1alpha3.KafkaServerConfig
-	kafkaAdminClient            sarama.ClusterAdmin
-	userNameMapping             string
+	kafkaServer      otterizev1alpha3.KafkaServerConfig
+	kafkaAdminClient sarama.ClusterAdmin
+	userNameMapping  string
+	// previousUserNameMapping is the mapping used before the operator's certificate was rotated to one with a
+	// different subject. ACLs of principals it produced are removed on the next resync.
+	previousUserNameMapping     string
 	enableKafkaACLCreation      bool
 	enforcementEnabledForServer bool
+	statusTracker               *StatusTracker
 }
 
 var (
@@ -69,18 +83,58 @@ var (
 	}
 )
 
-func getTLSConfig(tlsSource otterizev1alpha3.TLSSource) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(tlsSource.CertFile, tlsSource.KeyFile)
+// tlsMaterial is the PEM encoded content of a TLSSource, read anew for every admin so that rotated certificates are
+// noticed.
+type tlsMaterial struct {
+	CertPem   []byte
+	KeyPem    []byte
+	RootCAPem []byte
+}
+
+// loadTLSMaterial reads the certificate, key and root CA of a TLSSource from its Secret, if one is set, and from its
+// files otherwise. Returns nil for an empty TLSSource.
+func loadTLSMaterial(ctx context.Context, reader client.Reader, namespace string, tlsSource otterizev1alpha3.TLSSource) (*tlsMaterial, error) {
+	if lo.IsEmpty(tlsSource) {
+		return nil, nil
+	}
+
+	if tlsSource.SecretName != "" {
+		if reader == nil {
+			return nil, fmt.Errorf("a TLS secret is configured but no Kubernetes client was set")
+		}
+		secret := &corev1.Secret{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: tlsSource.SecretName, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed reading TLS secret %s: %w", tlsSource.SecretName, err)
+		}
+		return &tlsMaterial{
+			CertPem:   secret.Data[corev1.TLSCertKey],
+			KeyPem:    secret.Data[corev1.TLSPrivateKeyKey],
+			RootCAPem: secret.Data[tlsSecretRootCAKey],
+		}, nil
+	}
+
+	files := []string{tlsSource.CertFile, tlsSource.KeyFile, tlsSource.RootCAFile}
+	contents := make([][]byte, len(files))
+	for i, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading TLS file %s: %w", path, err)
+		}
+		contents[i] = data
+	}
+	return &tlsMaterial{CertPem: contents[0], KeyPem: contents[1], RootCAPem: contents[2]}, nil
+}
+
+func getTLSConfig(material *tlsMaterial) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(material.CertPem, material.KeyPem)
 	if err != nil {
 		return nil, fmt.Errorf("failed loading x509 key pair: %w", err)
 	}
 
 	pool := x509.NewCertPool()
-	rootCAPEM, err := os.ReadFile(tlsSource.RootCAFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed loading root CA PEM file: %w ", err)
+	if !pool.AppendCertsFromPEM(material.RootCAPem) {
+		return nil, fmt.Errorf("failed loading root CA: no PEM encoded certificates found")
 	}
-	pool.AppendCertsFromPEM(rootCAPEM)
 
 	return &tls.Config{
 		Certificates: []tls.Certificate{cert},
@@ -121,49 +175,86 @@ func getUserPrincipalMapping(tlsCert tls.Certificate) (string, error) {
 
 }
 
-func NewKafkaIntentsAdmin(kafkaServer otterizev1alpha3.KafkaServerConfig, defaultTls otterizev1alpha3.TLSSource, enableKafkaACLCreation bool, enforcementEnabledForServer bool) (KafkaIntentsAdmin, error) {
-	logger := logrus.WithField("addr", kafkaServer.Spec.Addr)
-	logger.Info("Connecting to kafka server")
-	addrs := []string{kafkaServer.Spec.Addr}
-
-	config := sarama.NewConfig()
-	config.Version = sarama.V2_0_0_0
+// NewKafkaIntentsAdmin returns an admin for the server, borrowing its connection from the pool. It is an
+// IntentsAdminFactoryFunction.
+func (p *AdminPool) NewKafkaIntentsAdmin(kafkaServer otterizev1alpha3.KafkaServerConfig, defaultTls otterizev1alpha3.TLSSource, enableKafkaACLCreation bool, enforcementEnabledForServer bool) (KafkaIntentsAdmin, error) {
+	if kafkaServer.Spec.Strimzi != nil {
+		if p.k8sClient == nil {
+			return nil, fmt.Errorf("strimzi is configured but no Kubernetes client was set")
+		}
+		return NewStrimziIntentsAdmin(p.k8sClient, kafkaServer, enableKafkaACLCreation, enforcementEnabledForServer, p.statusTracker), nil
+	}
 
 	var tlsSource otterizev1alpha3.TLSSource
-	if lo.IsEmpty(kafkaServer.Spec.TLS) {
+	if lo.IsEmpty(kafkaServer.Spec.TLS) && kafkaServer.Spec.SASL == nil {
 		tlsSource = defaultTls
-		logger.Info("Using TLS configuration from default")
 	} else {
 		tlsSource = kafkaServer.Spec.TLS
-		logger.Info("Using TLS configuration from KafkaServerConfig")
 	}
 
-	tlsConfig, err := getTLSConfig(tlsSource)
+	conn, admin, err := p.borrow(kafkaServer, tlsSource)
 	if err != nil {
 		return nil, err
 	}
 
-	usernameMapping, err := getUserPrincipalMapping(tlsConfig.Certificates[0])
-	if err != nil {
-		return nil, err
-	}
+	return &KafkaIntentsAdminImpl{
+		kafkaServer:                 kafkaServer,
+		kafkaAdminClient:            admin,
+		userNameMapping:             conn.usernameMapping,
+		previousUserNameMapping:     conn.previousUsernameMapping,
+		enableKafkaACLCreation:      enableKafkaACLCreation,
+		enforcementEnabledForServer: enforcementEnabledForServer,
+		statusTracker:               p.statusTracker,
+	}, nil
+}
 
-	config.Net.TLS.Config = tlsConfig
-	config.Net.TLS.Enable = true
+// newSaramaConfig returns the admin client config for a server along with the username mapping used to format client
+// principals. Servers with SASL settings may skip the client certificate, or TLS altogether.
+func newSaramaConfig(kafkaServer otterizev1alpha3.KafkaServerConfig, material *tlsMaterial, credentials *saslCredentials) (*sarama.Config, string, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_0_0_0
 	config.ClientID = intentsOperatorClientID
-
 	sarama.Logger = log.New(os.Stdout, "[sarama] ", log.LstdFlags)
 
-	saramaAdminClient, err := sarama.NewClusterAdmin(addrs, config)
+	usernameMapping := kafkaServer.Spec.PrincipalTemplate
+	if sasl := kafkaServer.Spec.SASL; sasl != nil {
+		configureSASL(config, sasl, credentials)
+		if usernameMapping == "" {
+			usernameMapping = defaultSASLPrincipalTemplate
+		}
+		if sasl.DisableTLS {
+			return config, usernameMapping, nil
+		}
+		config.Net.TLS.Enable = true
+		if material == nil {
+			config.Net.TLS.Config = &tls.Config{}
+			return config, usernameMapping, nil
+		}
+	}
+
+	if material == nil {
+		return nil, "", fmt.Errorf("no TLS certificate or SASL settings configured for the server")
+	}
+	tlsConfig, err := getTLSConfig(material)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	if usernameMapping == "" {
+		usernameMapping, err = getUserPrincipalMapping(tlsConfig.Certificates[0])
+		if err != nil {
+			return nil, "", err
+		}
 	}
 
-	return NewKafkaIntentsAdminImpl(kafkaServer, saramaAdminClient, usernameMapping, enableKafkaACLCreation, enforcementEnabledForServer), nil
+	config.Net.TLS.Config = tlsConfig
+	config.Net.TLS.Enable = true
+
+	return config, usernameMapping, nil
 }
 
-func NewKafkaIntentsAdminImpl(kafkaServer otterizev1alpha3.KafkaServerConfig, saramaAdminClient sarama.ClusterAdmin, usernameMapping string, enableKafkaACLCreation bool, enforcementEnabledForServer bool) KafkaIntentsAdmin {
-	return &KafkaIntentsAdminImpl{kafkaServer: kafkaServer, kafkaAdminClient: saramaAdminClient, userNameMapping: usernameMapping, enableKafkaACLCreation: enableKafkaACLCreation, enforcementEnabledForServer: enforcementEnabledForServer}
+func NewKafkaIntentsAdminImpl(kafkaServer otterizev1alpha3.KafkaServerConfig, saramaAdminClient sarama.ClusterAdmin, usernameMapping string, enableKafkaACLCreation bool, enforcementEnabledForServer bool, statusTracker *StatusTracker) KafkaIntentsAdmin {
+	return &KafkaIntentsAdminImpl{kafkaServer: kafkaServer, kafkaAdminClient: saramaAdminClient, userNameMapping: usernameMapping, enableKafkaACLCreation: enableKafkaACLCreation, enforcementEnabledForServer: enforcementEnabledForServer, statusTracker: statusTracker}
 }
 
 func (a *KafkaIntentsAdminImpl) Close() {
@@ -173,10 +264,18 @@ func (a *KafkaIntentsAdminImpl) Close() {
 }
 
 func (a *KafkaIntentsAdminImpl) formatPrincipal(clientName string, clientNamespace string) string {
-	username := a.userNameMapping
-	username = serviceNameRE.ReplaceAllString(username, clientName)
-	username = namespaceRE.ReplaceAllString(username, clientNamespace)
-	return fmt.Sprintf("User:%s", username)
+	return fmt.Sprintf("User:%s", formatUsername(a.userNameMapping, clientName, clientNamespace))
+}
+
+func hasKafkaTopics(intents []otterizev1alpha3.Intent) bool {
+	return lo.SomeBy(intents, func(intent otterizev1alpha3.Intent) bool {
+		return len(intent.Topics) > 0
+	})
+}
+
+func formatUsername(usernameMapping string, clientName string, clientNamespace string) string {
+	username := serviceNameRE.ReplaceAllString(usernameMapping, clientName)
+	return namespaceRE.ReplaceAllString(username, clientNamespace)
 }
 
 func (a *KafkaIntentsAdminImpl) queryAppliedIntentKafkaTopics(principal string) ([]otterizev1alpha3.KafkaTopic, error) {
@@ -240,7 +339,7 @@ func (a *KafkaIntentsAdminImpl) collectTopicsToACLList(principal string, topics
 	return topicToACLList, nil
 }
 
-func (a *KafkaIntentsAdminImpl) deleteACLsByPrincipal(principal string) (int, error) {
+func (a *KafkaIntentsAdminImpl) deleteACLsByPrincipal(ctx context.Context, principal string) (int, error) {
 	aclFilter := sarama.AclFilter{
 		ResourceType:              sarama.AclResourceTopic,
 		ResourcePatternTypeFilter: sarama.AclPatternAny,
@@ -255,9 +354,27 @@ func (a *KafkaIntentsAdminImpl) deleteACLsByPrincipal(principal string) (int, er
 		return 0, fmt.Errorf("failed deleting ACLs on server: %w", err)
 	}
 
+	a.auditACLs(ctx, auditlog.ActionDeleted, principal, nil, matchedAcls, nil)
 	return len(matchedAcls), nil
 }
 
+func (a *KafkaIntentsAdminImpl) serverTrigger() *auditlog.Trigger {
+	return &auditlog.Trigger{Kind: "KafkaServerConfig", Name: a.kafkaServer.Name, Namespace: a.kafkaServer.Namespace}
+}
+
+// auditACLs records ACL changes applied to the Kafka server. Without a trigger, the record is attributed to the
+// resource being reconciled, which the reconcile context carries.
+func (a *KafkaIntentsAdminImpl) auditACLs(ctx context.Context, action auditlog.Action, subject string, trigger *auditlog.Trigger, before any, after any) {
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            action,
+		ResourceKind:      auditlog.ResourceKindKafkaACL,
+		ResourceName:      fmt.Sprintf("%s:%s", a.kafkaServer.Spec.Service.Name, subject),
+		ResourceNamespace: a.kafkaServer.Namespace,
+		Trigger:           trigger,
+		Diff:              auditlog.Diff(before, after),
+	})
+}
+
 func (a *KafkaIntentsAdminImpl) logACLs() error {
 	logger := logrus.WithFields(
 		logrus.Fields{
@@ -299,8 +416,18 @@ func (a *KafkaIntentsAdminImpl) logACLs() error {
 	return nil
 }
 
-func (a *KafkaIntentsAdminImpl) ApplyClientIntents(clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) error {
+func (a *KafkaIntentsAdminImpl) ApplyClientIntents(ctx context.Context, clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) error {
 	principal := a.formatPrincipal(clientName, clientNamespace)
+	managedACLs, err := a.applyClientIntents(ctx, principal, intents)
+	if err == nil {
+		err = a.applyClientQuotas(ctx, formatUsername(a.userNameMapping, clientName, clientNamespace), intents)
+	}
+	a.statusTracker.recordClientIntents(a.kafkaServer, principal, hasKafkaTopics(intents), managedACLs, err)
+	return err
+}
+
+// applyClientIntents returns the number of ACLs the principal should have after applying its intents.
+func (a *KafkaIntentsAdminImpl) applyClientIntents(ctx context.Context, principal string, intents []otterizev1alpha3.Intent) (int, error) {
 	logger := logrus.WithFields(
 		logrus.Fields{
 			"principal":       principal,
@@ -310,12 +437,12 @@ func (a *KafkaIntentsAdminImpl) ApplyClientIntents(clientName string, clientName
 
 	appliedIntentKafkaTopics, err := a.queryAppliedIntentKafkaTopics(principal)
 	if err != nil {
-		return fmt.Errorf("failed getting applied ACL rules %w", err)
+		return 0, fmt.Errorf("failed getting applied ACL rules %w", err)
 	}
 
 	appliedIntentKafkaAcls, err := a.collectTopicsToACLList(principal, appliedIntentKafkaTopics)
 	if err != nil {
-		return fmt.Errorf("failed collecting topics to ACL list %w", err)
+		return 0, fmt.Errorf("failed collecting topics to ACL list %w", err)
 	}
 
 	expectedIntentKafkaTopics := lo.Flatten(
@@ -325,7 +452,7 @@ func (a *KafkaIntentsAdminImpl) ApplyClientIntents(clientName string, clientName
 	)
 	expectedIntentsKafkaTopicsAcls, err := a.collectTopicsToACLList(principal, expectedIntentKafkaTopics)
 	if err != nil {
-		return fmt.Errorf("failed collecting topics to ACL list %w", err)
+		return 0, fmt.Errorf("failed collecting topics to ACL list %w", err)
 	}
 
 	resourceAclsCreate, resourceAclsDelete := a.kafkaResourceAclsDiff(expectedIntentsKafkaTopicsAcls, appliedIntentKafkaAcls)
@@ -336,8 +463,9 @@ func (a *KafkaIntentsAdminImpl) ApplyClientIntents(clientName string, clientName
 		if a.enforcementEnabledForServer && a.enableKafkaACLCreation {
 			logger.Infof("Creating %d new ACLs", len(resourceAclsCreate))
 			if err := a.kafkaAdminClient.CreateACLs(resourceAclsCreate); err != nil {
-				return fmt.Errorf("failed applying ACLs to server: %w", err)
+				return 0, fmt.Errorf("failed applying ACLs to server: %w", err)
 			}
+			a.auditACLs(ctx, auditlog.ActionCreated, principal, nil, nil, resourceAclsCreate)
 		} else if !a.enableKafkaACLCreation {
 			logger.Infof("Skipped creation of %d new ACLs because Kafka ACL Creation is disabled", len(resourceAclsCreate))
 		} else if !a.enforcementEnabledForServer {
@@ -350,17 +478,22 @@ func (a *KafkaIntentsAdminImpl) ApplyClientIntents(clientName string, clientName
 	} else {
 		logger.Infof("deleting %d ACL rules", len(resourceAclsDelete))
 		if err := a.deleteResourceAcls(resourceAclsDelete); err != nil {
-			return fmt.Errorf("failed deleting ACLs on server: %w", err)
+			return 0, fmt.Errorf("failed deleting ACLs on server: %w", err)
 		}
+		a.auditACLs(ctx, auditlog.ActionDeleted, principal, nil, resourceAclsDelete, nil)
 	}
 
 	if err := a.logACLs(); err != nil {
 		logger.WithError(err).Error("failed logging current ACL rules")
 	}
-	return nil
+
+	if !a.enforcementEnabledForServer || !a.enableKafkaACLCreation {
+		return 0, nil
+	}
+	return lo.SumBy(lo.Values(expectedIntentsKafkaTopicsAcls), func(acls []sarama.Acl) int { return len(acls) }), nil
 }
 
-func (a *KafkaIntentsAdminImpl) RemoveClientIntents(clientName string, clientNamespace string) error {
+func (a *KafkaIntentsAdminImpl) RemoveClientIntents(ctx context.Context, clientName string, clientNamespace string) error {
 	principal := a.formatPrincipal(clientName, clientNamespace)
 	logger := logrus.WithFields(
 		logrus.Fields{
@@ -368,19 +501,28 @@ func (a *KafkaIntentsAdminImpl) RemoveClientIntents(clientName string, clientNam
 			"serverName":      a.kafkaServer.Spec.Service,
 			"serverNamespace": a.kafkaServer.Namespace,
 		})
-	countDeleted, err := a.deleteACLsByPrincipal(principal)
+	countDeleted, err := a.deleteACLsByPrincipal(ctx, principal)
 	if err != nil {
-		return fmt.Errorf("failed clearing acls for principal %s: %w", principal, err)
+		err = fmt.Errorf("failed clearing acls for principal %s: %w", principal, err)
+		a.statusTracker.recordClientIntents(a.kafkaServer, principal, true, 0, err)
+		return err
 	}
 	logger.Infof("%d acl rules was deleted", countDeleted)
 
+	if err := a.applyClientQuotas(ctx, formatUsername(a.userNameMapping, clientName, clientNamespace), nil); err != nil {
+		err = fmt.Errorf("failed clearing client quotas for principal %s: %w", principal, err)
+		a.statusTracker.recordClientIntents(a.kafkaServer, principal, true, 0, err)
+		return err
+	}
+	a.statusTracker.recordClientIntents(a.kafkaServer, principal, false, 0, nil)
+
 	if err := a.logACLs(); err != nil {
 		logger.WithError(err).Error("failed logging current ACL rules")
 	}
 	return nil
 }
 
-func (a *KafkaIntentsAdminImpl) RemoveServerIntents(topicsConf []otterizev1alpha3.TopicConfig) error {
+func (a *KafkaIntentsAdminImpl) RemoveServerIntents(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error {
 	logger := logrus.WithFields(
 		logrus.Fields{
 			"serverName":      a.kafkaServer.Spec.Service,
@@ -394,6 +536,7 @@ func (a *KafkaIntentsAdminImpl) RemoveServerIntents(topicsConf []otterizev1alpha
 	if err != nil {
 		return fmt.Errorf("failed deleting ACLs on server: %w", err)
 	}
+	a.auditACLs(ctx, auditlog.ActionDeleted, topicsConfAuditSubject, a.serverTrigger(), serverACLs, nil)
 
 	deletedRulesCount, err := a.deleteConsumerGroupWildcardACLs()
 	if err != nil {
@@ -555,7 +698,13 @@ func (a *KafkaIntentsAdminImpl) deleteResourceAcls(resourceAclsToDelete []*saram
 	return nil
 }
 
-func (a *KafkaIntentsAdminImpl) ApplyServerTopicsConf(topicsConf []otterizev1alpha3.TopicConfig) error {
+func (a *KafkaIntentsAdminImpl) ApplyServerTopicsConf(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error {
+	err := a.applyServerTopicsConf(ctx, topicsConf)
+	a.statusTracker.recordTopicConfig(a.kafkaServer, err)
+	return err
+}
+
+func (a *KafkaIntentsAdminImpl) applyServerTopicsConf(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error {
 	logger := logrus.WithFields(
 		logrus.Fields{
 			"serverName":      a.kafkaServer.Spec.Service,
@@ -581,6 +730,7 @@ func (a *KafkaIntentsAdminImpl) ApplyServerTopicsConf(topicsConf []otterizev1alp
 			if err := a.kafkaAdminClient.CreateACLs(resourceAclsToCreate); err != nil {
 				return fmt.Errorf("failed creating ACLs: %w", err)
 			}
+			a.auditACLs(ctx, auditlog.ActionCreated, topicsConfAuditSubject, a.serverTrigger(), nil, resourceAclsToCreate)
 		}
 	} else {
 		logger.Info("No new ACLs to create for topic configuration")
@@ -591,6 +741,7 @@ func (a *KafkaIntentsAdminImpl) ApplyServerTopicsConf(topicsConf []otterizev1alp
 		if err := a.deleteResourceAcls(resourceAclsToDelete); err != nil {
 			return fmt.Errorf("failed deleting ACLs: %w", err)
 		}
+		a.auditACLs(ctx, auditlog.ActionDeleted, topicsConfAuditSubject, a.serverTrigger(), resourceAclsToDelete, nil)
 	} else {
 		logger.Info("No existing ACLs to delete for topic configuration")
 	}
@@ -1,6 +1,7 @@
 package kafkaacls
 
 import (
+	"context"
 	"github.com/Shopify/sarama"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
@@ -55,7 +56,7 @@ func (s *IntentAdminSuite) TestApplyServerConfig() {
 		},
 	}
 
-	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true)
+	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true, NewStatusTracker())
 	aclListFilterAnonymous := sarama.AclFilter{
 		ResourceType:              sarama.AclResourceTopic,
 		ResourcePatternTypeFilter: sarama.AclPatternAny,
@@ -93,7 +94,7 @@ func (s *IntentAdminSuite) TestApplyServerConfig() {
 		s.mockClusterAdmin.EXPECT().CreateACLs(MatchResourceAcls(operatorACLForGroup)).Return(nil),
 		s.mockClusterAdmin.EXPECT().ListAcls(aclListFilterAll).Return([]sarama.ResourceAcls{allowAuthenticatedOnly, operatorGroupPermission}, nil),
 	)
-	err := s.intentsAdmin.ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics)
+	err := s.intentsAdmin.ApplyServerTopicsConf(context.Background(), kafkaServerConfig.Spec.Topics)
 	s.Require().NoError(err)
 }
 
@@ -120,7 +121,7 @@ func (s *IntentAdminSuite) TestApplyServerConfigPermissionExists() {
 		},
 	}
 
-	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true)
+	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true, NewStatusTracker())
 
 	aclListFilterAnonymous := sarama.AclFilter{
 		ResourceType:              sarama.AclResourceTopic,
@@ -187,7 +188,7 @@ func (s *IntentAdminSuite) TestApplyServerConfigPermissionExists() {
 		s.mockClusterAdmin.EXPECT().ListAcls(aclListFilterAllPrincipals).Return([]sarama.ResourceAcls{allowAuthenticatedOnly, operatorGroupPermission}, nil),
 	)
 
-	err := s.intentsAdmin.ApplyServerTopicsConf(kafkaServerConfig.Spec.Topics)
+	err := s.intentsAdmin.ApplyServerTopicsConf(context.Background(), kafkaServerConfig.Spec.Topics)
 	s.Require().NoError(err)
 }
 
@@ -215,7 +216,7 @@ func (s *IntentAdminSuite) TestDeleteServerConfig() {
 		},
 	}
 
-	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true)
+	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "user-name-mapping", true, true, NewStatusTracker())
 
 	resource := sarama.Resource{
 		ResourceType:        sarama.AclResourceTopic,
@@ -298,7 +299,7 @@ func (s *IntentAdminSuite) TestDeleteServerConfig() {
 	s.mockClusterAdmin.EXPECT().DeleteACL(authenticatedUserACLFilter, false).Return(authenticatedUsersTopicAcl, nil)
 	s.mockClusterAdmin.EXPECT().DeleteACL(aclDeleteFilterOperatorGroup, false).Return(groupAcl, nil)
 
-	err := s.intentsAdmin.RemoveServerIntents(kafkaServerConfig.Spec.Topics)
+	err := s.intentsAdmin.RemoveServerIntents(context.Background(), kafkaServerConfig.Spec.Topics)
 	s.Require().NoError(err)
 }
 
@@ -5,10 +5,12 @@
 package kafkaaclsmocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	v1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	gomock "go.uber.org/mock/gomock"
+	types "k8s.io/apimachinery/pkg/types"
 )
 
 // MockKafkaIntentsAdmin is a mock of KafkaIntentsAdmin interface.
@@ -35,31 +37,45 @@ func (m *MockKafkaIntentsAdmin) EXPECT() *MockKafkaIntentsAdminMockRecorder {
 }
 
 // ApplyClientIntents mocks base method.
-func (m *MockKafkaIntentsAdmin) ApplyClientIntents(clientName, clientNamespace string, intents []v1alpha3.Intent) error {
+func (m *MockKafkaIntentsAdmin) ApplyClientIntents(ctx context.Context, clientName, clientNamespace string, intents []v1alpha3.Intent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ApplyClientIntents", clientName, clientNamespace, intents)
+	ret := m.ctrl.Call(m, "ApplyClientIntents", ctx, clientName, clientNamespace, intents)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ApplyClientIntents indicates an expected call of ApplyClientIntents.
-func (mr *MockKafkaIntentsAdminMockRecorder) ApplyClientIntents(clientName, clientNamespace, intents interface{}) *gomock.Call {
+func (mr *MockKafkaIntentsAdminMockRecorder) ApplyClientIntents(ctx, clientName, clientNamespace, intents interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyClientIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).ApplyClientIntents), clientName, clientNamespace, intents)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyClientIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).ApplyClientIntents), ctx, clientName, clientNamespace, intents)
+}
+
+// ApplyManagedTopics mocks base method.
+func (m *MockKafkaIntentsAdmin) ApplyManagedTopics(ctx context.Context, topics []v1alpha3.ManagedKafkaTopic) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyManagedTopics", ctx, topics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyManagedTopics indicates an expected call of ApplyManagedTopics.
+func (mr *MockKafkaIntentsAdminMockRecorder) ApplyManagedTopics(ctx, topics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyManagedTopics", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).ApplyManagedTopics), ctx, topics)
 }
 
 // ApplyServerTopicsConf mocks base method.
-func (m *MockKafkaIntentsAdmin) ApplyServerTopicsConf(topicsConf []v1alpha3.TopicConfig) error {
+func (m *MockKafkaIntentsAdmin) ApplyServerTopicsConf(ctx context.Context, topicsConf []v1alpha3.TopicConfig) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ApplyServerTopicsConf", topicsConf)
+	ret := m.ctrl.Call(m, "ApplyServerTopicsConf", ctx, topicsConf)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ApplyServerTopicsConf indicates an expected call of ApplyServerTopicsConf.
-func (mr *MockKafkaIntentsAdminMockRecorder) ApplyServerTopicsConf(topicsConf interface{}) *gomock.Call {
+func (mr *MockKafkaIntentsAdminMockRecorder) ApplyServerTopicsConf(ctx, topicsConf interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyServerTopicsConf", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).ApplyServerTopicsConf), topicsConf)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyServerTopicsConf", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).ApplyServerTopicsConf), ctx, topicsConf)
 }
 
 // Close mocks base method.
@@ -74,30 +90,60 @@ func (mr *MockKafkaIntentsAdminMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).Close))
 }
 
+// DeleteManagedTopics mocks base method.
+func (m *MockKafkaIntentsAdmin) DeleteManagedTopics(ctx context.Context, topicNames []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteManagedTopics", ctx, topicNames)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteManagedTopics indicates an expected call of DeleteManagedTopics.
+func (mr *MockKafkaIntentsAdminMockRecorder) DeleteManagedTopics(ctx, topicNames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteManagedTopics", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).DeleteManagedTopics), ctx, topicNames)
+}
+
 // RemoveClientIntents mocks base method.
-func (m *MockKafkaIntentsAdmin) RemoveClientIntents(clientName, clientNamespace string) error {
+func (m *MockKafkaIntentsAdmin) RemoveClientIntents(ctx context.Context, clientName, clientNamespace string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveClientIntents", clientName, clientNamespace)
+	ret := m.ctrl.Call(m, "RemoveClientIntents", ctx, clientName, clientNamespace)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveClientIntents indicates an expected call of RemoveClientIntents.
-func (mr *MockKafkaIntentsAdminMockRecorder) RemoveClientIntents(clientName, clientNamespace interface{}) *gomock.Call {
+func (mr *MockKafkaIntentsAdminMockRecorder) RemoveClientIntents(ctx, clientName, clientNamespace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveClientIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).RemoveClientIntents), clientName, clientNamespace)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveClientIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).RemoveClientIntents), ctx, clientName, clientNamespace)
 }
 
 // RemoveServerIntents mocks base method.
-func (m *MockKafkaIntentsAdmin) RemoveServerIntents(topicsConf []v1alpha3.TopicConfig) error {
+func (m *MockKafkaIntentsAdmin) RemoveServerIntents(ctx context.Context, topicsConf []v1alpha3.TopicConfig) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveServerIntents", topicsConf)
+	ret := m.ctrl.Call(m, "RemoveServerIntents", ctx, topicsConf)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveServerIntents indicates an expected call of RemoveServerIntents.
-func (mr *MockKafkaIntentsAdminMockRecorder) RemoveServerIntents(topicsConf interface{}) *gomock.Call {
+func (mr *MockKafkaIntentsAdminMockRecorder) RemoveServerIntents(ctx, topicsConf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveServerIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).RemoveServerIntents), ctx, topicsConf)
+}
+
+// SyncClientIntents mocks base method.
+func (m *MockKafkaIntentsAdmin) SyncClientIntents(ctx context.Context, intentsByClient map[types.NamespacedName][]v1alpha3.Intent, managedPrincipals []string) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncClientIntents", ctx, intentsByClient, managedPrincipals)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SyncClientIntents indicates an expected call of SyncClientIntents.
+func (mr *MockKafkaIntentsAdminMockRecorder) SyncClientIntents(ctx, intentsByClient, managedPrincipals interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveServerIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).RemoveServerIntents), topicsConf)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncClientIntents", reflect.TypeOf((*MockKafkaIntentsAdmin)(nil).SyncClientIntents), ctx, intentsByClient, managedPrincipals)
 }
@@ -0,0 +1,142 @@
+package kafkaacls
+
+import (
+	"context"
+	"fmt"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	quotaKeyProducerByteRate  = "producer_byte_rate"
+	quotaKeyConsumerByteRate  = "consumer_byte_rate"
+	quotaKeyRequestPercentage = "request_percentage"
+)
+
+var managedQuotaKeys = []string{quotaKeyProducerByteRate, quotaKeyConsumerByteRate, quotaKeyRequestPercentage}
+
+// mergeKafkaQuotas combines the quotas of a client's intents to a single server. When several intents set the same
+// quota, the highest limit wins. Returns nil if none of the intents set quotas.
+func mergeKafkaQuotas(intents []otterizev1alpha3.Intent) *otterizev1alpha3.KafkaQuotas {
+	var merged *otterizev1alpha3.KafkaQuotas
+	for _, intent := range intents {
+		if intent.KafkaQuotas == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &otterizev1alpha3.KafkaQuotas{}
+		}
+		merged.ProducerByteRate = lo.Max([]int64{merged.ProducerByteRate, intent.KafkaQuotas.ProducerByteRate})
+		merged.ConsumerByteRate = lo.Max([]int64{merged.ConsumerByteRate, intent.KafkaQuotas.ConsumerByteRate})
+		merged.RequestPercentage = lo.Max([]int32{merged.RequestPercentage, intent.KafkaQuotas.RequestPercentage})
+	}
+	return merged
+}
+
+// quotaValues maps quotas to the client quota keys used by the broker, leaving out quotas that are not set.
+func quotaValues(quotas *otterizev1alpha3.KafkaQuotas) map[string]float64 {
+	if quotas == nil {
+		return map[string]float64{}
+	}
+	values := map[string]float64{
+		quotaKeyProducerByteRate:  float64(quotas.ProducerByteRate),
+		quotaKeyConsumerByteRate:  float64(quotas.ConsumerByteRate),
+		quotaKeyRequestPercentage: float64(quotas.RequestPercentage),
+	}
+	return lo.PickBy(values, func(_ string, value float64) bool { return value > 0 })
+}
+
+// applyClientQuotas makes the client quotas of the user match its intents. The operator only removes quotas it set
+// itself, which are tracked in the KafkaServerConfig status, so quotas set by other means are left alone. Nothing is
+// changed while enforcement or Kafka ACL creation is disabled.
+func (a *KafkaIntentsAdminImpl) applyClientQuotas(ctx context.Context, userName string, intents []otterizev1alpha3.Intent) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"user":            userName,
+		"serverName":      a.kafkaServer.Spec.Service,
+		"serverNamespace": a.kafkaServer.Namespace,
+	})
+
+	if !a.enforcementEnabledForServer || !a.enableKafkaACLCreation {
+		if mergeKafkaQuotas(intents) != nil {
+			logger.Info("Skipped applying client quotas because enforcement or Kafka ACL Creation is disabled")
+		}
+		return nil
+	}
+
+	desired := quotaValues(mergeKafkaQuotas(intents))
+	applied := a.statusTracker.quotaKeys(a.kafkaServer, userName)
+	if len(desired) == 0 && len(applied) == 0 {
+		return nil
+	}
+
+	current, err := a.describeClientQuotas(userName)
+	if err != nil {
+		return err
+	}
+
+	changed := lo.PickBy(desired, func(key string, value float64) bool {
+		currentValue, ok := current[key]
+		return !ok || currentValue != value
+	})
+	removed := lo.Filter(lo.Without(applied, lo.Keys(desired)...), func(key string, _ int) bool {
+		_, ok := current[key]
+		return ok
+	})
+
+	entity := []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Name: userName}}
+	for key, value := range changed {
+		if err := a.kafkaAdminClient.AlterClientQuotas(entity, sarama.ClientQuotasOp{Key: key, Value: value}, false); err != nil {
+			return fmt.Errorf("failed setting client quota %s for user %s: %w", key, userName, err)
+		}
+	}
+	for _, key := range removed {
+		if err := a.kafkaAdminClient.AlterClientQuotas(entity, sarama.ClientQuotasOp{Key: key, Remove: true}, false); err != nil {
+			return fmt.Errorf("failed removing client quota %s for user %s: %w", key, userName, err)
+		}
+	}
+	a.statusTracker.recordQuotas(a.kafkaServer, userName, lo.Keys(desired))
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	logger.Infof("Set %d and removed %d client quotas", len(changed), len(removed))
+	a.auditQuotas(ctx, userName, lo.PickByKeys(current, append(lo.Keys(changed), removed...)), lo.PickByKeys(desired, lo.Keys(changed)))
+	return nil
+}
+
+// describeClientQuotas returns the managed quotas currently set on the user.
+func (a *KafkaIntentsAdminImpl) describeClientQuotas(userName string) (map[string]float64, error) {
+	entries, err := a.kafkaAdminClient.DescribeClientQuotas([]sarama.QuotaFilterComponent{
+		{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Match: userName},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed describing client quotas for user %s: %w", userName, err)
+	}
+
+	current := make(map[string]float64)
+	for _, entry := range entries {
+		for key, value := range lo.PickByKeys(entry.Values, managedQuotaKeys) {
+			current[key] = value
+		}
+	}
+	return current, nil
+}
+
+func (a *KafkaIntentsAdminImpl) auditQuotas(ctx context.Context, userName string, before map[string]float64, after map[string]float64) {
+	action := auditlog.ActionUpdated
+	if len(before) == 0 {
+		action = auditlog.ActionCreated
+	} else if len(after) == 0 {
+		action = auditlog.ActionDeleted
+	}
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            action,
+		ResourceKind:      auditlog.ResourceKindKafkaQuota,
+		ResourceName:      fmt.Sprintf("%s:User:%s", a.kafkaServer.Spec.Service.Name, userName),
+		ResourceNamespace: a.kafkaServer.Namespace,
+		Diff:              auditlog.Diff(before, after),
+	})
+}
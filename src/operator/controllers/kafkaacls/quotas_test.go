@@ -0,0 +1,158 @@
+package kafkaacls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+const quotaUserName = "client.test-namespace"
+
+type ClientQuotasTestSuite struct {
+	suite.Suite
+	mockClusterAdmin *kafkaaclsmocks.MockClusterAdmin
+	intentsAdmin     *KafkaIntentsAdminImpl
+}
+
+func (s *ClientQuotasTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.mockClusterAdmin = kafkaaclsmocks.NewMockClusterAdmin(controller)
+	kafkaServerConfig := otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: serverName}, Addr: serverAddress},
+	}
+	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, "$ServiceName.$Namespace", true, true, NewStatusTracker()).(*KafkaIntentsAdminImpl)
+}
+
+func (s *ClientQuotasTestSuite) quotaEntity() []sarama.QuotaEntityComponent {
+	return []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Name: quotaUserName}}
+}
+
+func (s *ClientQuotasTestSuite) expectDescribeClientQuotas(values map[string]float64) {
+	s.mockClusterAdmin.EXPECT().DescribeClientQuotas([]sarama.QuotaFilterComponent{
+		{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Match: quotaUserName},
+	}, true).Return([]sarama.DescribeClientQuotasEntry{{Entity: s.quotaEntity(), Values: values}}, nil)
+}
+
+func (s *ClientQuotasTestSuite) TestMergeTakesHighestLimit() {
+	merged := mergeKafkaQuotas([]otterizev1alpha3.Intent{
+		{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024, RequestPercentage: 50}},
+		{Name: serverName},
+		{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 2048, ConsumerByteRate: 4096}},
+	})
+	s.Require().Equal(&otterizev1alpha3.KafkaQuotas{ProducerByteRate: 2048, ConsumerByteRate: 4096, RequestPercentage: 50}, merged)
+	s.Require().Nil(mergeKafkaQuotas([]otterizev1alpha3.Intent{{Name: serverName}}))
+}
+
+// setAppliedQuotas marks quotas as set by the operator, as if they were loaded from the KafkaServerConfig status.
+func (s *ClientQuotasTestSuite) setAppliedQuotas(quotaKeys ...string) {
+	s.intentsAdmin.kafkaServer.Status.ManagedQuotas = map[string][]string{quotaUserName: quotaKeys}
+}
+
+func (s *ClientQuotasTestSuite) appliedQuotas() []string {
+	return s.intentsAdmin.statusTracker.quotaKeys(s.intentsAdmin.kafkaServer, quotaUserName)
+}
+
+func (s *ClientQuotasTestSuite) TestSetsChangedAndRemovesUnwantedQuotas() {
+	s.setAppliedQuotas(quotaKeyConsumerByteRate, quotaKeyRequestPercentage)
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024, ConsumerByteRate: 2048}}}
+	s.expectDescribeClientQuotas(map[string]float64{
+		quotaKeyProducerByteRate:  1024,
+		quotaKeyConsumerByteRate:  1000,
+		quotaKeyRequestPercentage: 50,
+		// Quotas the operator doesn't manage are left alone.
+		"controller_mutation_rate": 10,
+	})
+	s.mockClusterAdmin.EXPECT().AlterClientQuotas(s.quotaEntity(), sarama.ClientQuotasOp{Key: quotaKeyConsumerByteRate, Value: 2048}, false).Return(nil)
+	s.mockClusterAdmin.EXPECT().AlterClientQuotas(s.quotaEntity(), sarama.ClientQuotasOp{Key: quotaKeyRequestPercentage, Remove: true}, false).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents))
+	s.Require().Equal([]string{quotaKeyConsumerByteRate, quotaKeyProducerByteRate}, s.appliedQuotas())
+}
+
+func (s *ClientQuotasTestSuite) TestUpToDateQuotasAreLeftAlone() {
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{RequestPercentage: 25}}}
+	s.expectDescribeClientQuotas(map[string]float64{quotaKeyRequestPercentage: 25})
+
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents))
+	s.Require().Equal([]string{quotaKeyRequestPercentage}, s.appliedQuotas())
+}
+
+func (s *ClientQuotasTestSuite) TestQuotasSetByOthersAreNotRemoved() {
+	// Without quota intents or quotas set by the operator, the broker isn't even asked.
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, nil))
+
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024}}}
+	s.expectDescribeClientQuotas(map[string]float64{quotaKeyRequestPercentage: 50})
+	s.mockClusterAdmin.EXPECT().AlterClientQuotas(s.quotaEntity(), sarama.ClientQuotasOp{Key: quotaKeyProducerByteRate, Value: 1024}, false).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents))
+	s.Require().Equal([]string{quotaKeyProducerByteRate}, s.appliedQuotas())
+}
+
+func (s *ClientQuotasTestSuite) TestQuotaChangesAreAttributedToReconciledClientIntents() {
+	buffer := &bytes.Buffer{}
+	auditlog.SetGlobalSinks(auditlog.NewWriterSink(buffer))
+	defer auditlog.SetGlobalSinks()
+
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024}}}
+	s.expectDescribeClientQuotas(map[string]float64{})
+	s.mockClusterAdmin.EXPECT().AlterClientQuotas(s.quotaEntity(), sarama.ClientQuotasOp{Key: quotaKeyProducerByteRate, Value: 1024}, false).Return(nil)
+
+	trigger := auditlog.Trigger{Kind: "ClientIntents", Name: "client-intents", Namespace: testNamespace}
+	ctx := auditlog.ContextWithTrigger(context.Background(), trigger)
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(ctx, quotaUserName, intents))
+
+	record := auditlog.Record{}
+	s.Require().NoError(json.Unmarshal(buffer.Bytes(), &record))
+	s.Require().Equal(auditlog.ResourceKindKafkaQuota, record.ResourceKind)
+	s.Require().Equal(&trigger, record.Trigger)
+}
+
+func (s *ClientQuotasTestSuite) TestQuotasAreLeftUntouchedWhenEnforcementIsDisabled() {
+	s.setAppliedQuotas(quotaKeyProducerByteRate)
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ConsumerByteRate: 1024}}}
+
+	s.intentsAdmin.enforcementEnabledForServer = false
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, nil))
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents))
+
+	s.intentsAdmin.enforcementEnabledForServer = true
+	s.intentsAdmin.enableKafkaACLCreation = false
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, nil))
+	s.Require().NoError(s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents))
+
+	s.Require().Equal([]string{quotaKeyProducerByteRate}, s.appliedQuotas())
+}
+
+func (s *ClientQuotasTestSuite) TestDescribeFailureIsReturned() {
+	s.mockClusterAdmin.EXPECT().DescribeClientQuotas(gomock.Any(), true).Return(nil, sarama.ErrUnsupportedVersion)
+
+	intents := []otterizev1alpha3.Intent{{Name: serverName, KafkaQuotas: &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024}}}
+	err := s.intentsAdmin.applyClientQuotas(context.Background(), quotaUserName, intents)
+	s.Require().True(errors.Is(err, sarama.ErrUnsupportedVersion))
+}
+
+func (s *ClientQuotasTestSuite) TestRemoveClientIntentsRemovesQuotas() {
+	s.setAppliedQuotas(quotaKeyProducerByteRate)
+	s.mockClusterAdmin.EXPECT().ListAcls(gomock.Any()).Return(nil, nil).AnyTimes()
+	s.mockClusterAdmin.EXPECT().DeleteACL(gomock.Any(), true).Return(nil, nil)
+	s.expectDescribeClientQuotas(map[string]float64{quotaKeyProducerByteRate: 1024})
+	s.mockClusterAdmin.EXPECT().AlterClientQuotas(s.quotaEntity(), sarama.ClientQuotasOp{Key: quotaKeyProducerByteRate, Remove: true}, false).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.RemoveClientIntents(context.Background(), "client", testNamespace))
+	s.Require().Empty(s.appliedQuotas())
+}
+
+func TestClientQuotasTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientQuotasTestSuite))
+}
@@ -0,0 +1,163 @@
+package kafkaacls
+
+import (
+	"context"
+	"fmt"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/xdg-go/scram"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	defaultSASLPrincipalTemplate = "$ServiceName.$Namespace"
+	secretReadTimeout            = 10 * time.Second
+	oauthTokenTimeout            = 10 * time.Second
+)
+
+// saslCredentials holds the secret values referenced by a SASLConfig.
+type saslCredentials struct {
+	Username     string
+	Password     string
+	ClientID     string
+	ClientSecret string
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+func resolveSASLCredentials(ctx context.Context, reader client.Reader, namespace string, sasl *otterizev1alpha3.SASLConfig) (*saslCredentials, error) {
+	if sasl == nil {
+		return nil, nil
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("SASL is configured but no Kubernetes client was set")
+	}
+
+	var required []*otterizev1alpha3.SecretKeyRef
+	switch sasl.Mechanism {
+	case otterizev1alpha3.SASLMechanismPlain, otterizev1alpha3.SASLMechanismSCRAMSHA256, otterizev1alpha3.SASLMechanismSCRAMSHA512:
+		required = []*otterizev1alpha3.SecretKeyRef{sasl.UsernameSecretRef, sasl.PasswordSecretRef}
+	case otterizev1alpha3.SASLMechanismOAuthBearer:
+		if sasl.TokenURL == "" {
+			return nil, fmt.Errorf("SASL mechanism %s requires tokenURL", sasl.Mechanism)
+		}
+		required = []*otterizev1alpha3.SecretKeyRef{sasl.ClientIDSecretRef, sasl.ClientSecretSecretRef}
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", sasl.Mechanism)
+	}
+
+	values := make([]string, len(required))
+	for i, ref := range required {
+		if ref == nil {
+			return nil, fmt.Errorf("SASL mechanism %s is missing a required secret reference", sasl.Mechanism)
+		}
+		value, err := readSecretKey(ctx, reader, namespace, *ref)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	if sasl.Mechanism == otterizev1alpha3.SASLMechanismOAuthBearer {
+		return &saslCredentials{ClientID: values[0], ClientSecret: values[1]}, nil
+	}
+	return &saslCredentials{Username: values[0], Password: values[1]}, nil
+}
+
+func readSecretKey(ctx context.Context, reader client.Reader, namespace string, ref otterizev1alpha3.SecretKeyRef) (string, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed reading secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+func configureSASL(config *sarama.Config, sasl *otterizev1alpha3.SASLConfig, credentials *saslCredentials) {
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Handshake = true
+	config.Net.SASL.Version = sarama.SASLHandshakeV1
+
+	switch sasl.Mechanism {
+	case otterizev1alpha3.SASLMechanismPlain:
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case otterizev1alpha3.SASLMechanismSCRAMSHA256:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return newSCRAMClient(scram.SHA256) }
+	case otterizev1alpha3.SASLMechanismSCRAMSHA512:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return newSCRAMClient(scram.SHA512) }
+	case otterizev1alpha3.SASLMechanismOAuthBearer:
+		config.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		config.Net.SASL.TokenProvider = newOAuthTokenProvider(sasl, credentials)
+	}
+	config.Net.SASL.User = credentials.Username
+	config.Net.SASL.Password = credentials.Password
+}
+
+// oauthTokenProvider fetches tokens with the client credentials flow. The underlying token source caches the token
+// and refreshes it when it expires.
+type oauthTokenProvider struct {
+	tokenSource oauth2.TokenSource
+}
+
+func newOAuthTokenProvider(sasl *otterizev1alpha3.SASLConfig, credentials *saslCredentials) *oauthTokenProvider {
+	clientCredentials := clientcredentials.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		TokenURL:     sasl.TokenURL,
+		Scopes:       sasl.Scopes,
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: oauthTokenTimeout})
+	return &oauthTokenProvider{tokenSource: clientCredentials.TokenSource(ctx)}
+}
+
+func (p *oauthTokenProvider) Token() (*sarama.AccessToken, error) {
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching OAuth token: %w", err)
+	}
+	return &sarama.AccessToken{Token: token.AccessToken}, nil
+}
+
+// scramClient adapts a SCRAM conversation of github.com/xdg-go/scram to sarama. Usernames and passwords are
+// normalized with SASLprep, as required by RFC 5802.
+type scramClient struct {
+	hashGenerator  scram.HashGeneratorFcn
+	nonceGenerator scram.NonceGeneratorFcn
+	conversation   *scram.ClientConversation
+}
+
+func newSCRAMClient(hashGenerator scram.HashGeneratorFcn) *scramClient {
+	return &scramClient{hashGenerator: hashGenerator}
+}
+
+func (c *scramClient) Begin(username string, password string, authzID string) error {
+	scramClient, err := c.hashGenerator.NewClient(username, password, authzID)
+	if err != nil {
+		return fmt.Errorf("failed creating SCRAM client: %w", err)
+	}
+	if c.nonceGenerator != nil {
+		scramClient = scramClient.WithNonceGenerator(c.nonceGenerator)
+	}
+	c.conversation = scramClient.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+// Done reports whether the conversation is over and the server proved that it knows the password.
+func (c *scramClient) Done() bool {
+	return c.conversation.Done() && c.conversation.Valid()
+}
@@ -0,0 +1,128 @@
+package kafkaacls
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/stretchr/testify/suite"
+	"github.com/xdg-go/scram"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+const saslSecretName = "kafka-credentials"
+
+type SASLTestSuite struct {
+	suite.Suite
+	client *mocks.MockClient
+}
+
+func (s *SASLTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.client = mocks.NewMockClient(controller)
+}
+
+func (s *SASLTestSuite) expectSecret(data map[string][]byte) {
+	s.client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: saslSecretName, Namespace: testNamespace}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, secret *corev1.Secret, opts ...client.GetOption) error {
+			secret.Data = data
+			return nil
+		}).AnyTimes()
+}
+
+// Test vector from RFC 7677, section 3.
+func (s *SASLTestSuite) TestSCRAMSHA256Exchange() {
+	scramClient := newSCRAMClient(scram.SHA256)
+	scramClient.nonceGenerator = func() string { return "rOprNGfwEbeRWgbNEkqO" }
+	s.Require().NoError(scramClient.Begin("user", "pencil", ""))
+
+	clientFirst, err := scramClient.Step("")
+	s.Require().NoError(err)
+	s.Require().Equal("n,,n=user,r=rOprNGfwEbeRWgbNEkqO", clientFirst)
+
+	clientFinal, err := scramClient.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	s.Require().NoError(err)
+	s.Require().Equal("c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", clientFinal)
+	s.Require().False(scramClient.Done())
+
+	_, err = scramClient.Step("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+	s.Require().NoError(err)
+	s.Require().True(scramClient.Done())
+}
+
+func (s *SASLTestSuite) TestSCRAMRejectsBadServerSignature() {
+	scramClient := newSCRAMClient(scram.SHA256)
+	scramClient.nonceGenerator = func() string { return "rOprNGfwEbeRWgbNEkqO" }
+	s.Require().NoError(scramClient.Begin("user", "pencil", ""))
+	_, _ = scramClient.Step("")
+	_, err := scramClient.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	s.Require().NoError(err)
+
+	_, err = scramClient.Step("v=AAAA")
+	s.Require().Error(err)
+	s.Require().False(scramClient.Done())
+}
+
+func (s *SASLTestSuite) TestResolveSCRAMCredentials() {
+	s.expectSecret(map[string][]byte{"username": []byte("operator"), "password": []byte("secret")})
+	sasl := &otterizev1alpha3.SASLConfig{
+		Mechanism:         otterizev1alpha3.SASLMechanismSCRAMSHA512,
+		UsernameSecretRef: &otterizev1alpha3.SecretKeyRef{Name: saslSecretName, Key: "username"},
+		PasswordSecretRef: &otterizev1alpha3.SecretKeyRef{Name: saslSecretName, Key: "password"},
+	}
+
+	credentials, err := resolveSASLCredentials(context.Background(), s.client, testNamespace, sasl)
+	s.Require().NoError(err)
+	s.Require().Equal(&saslCredentials{Username: "operator", Password: "secret"}, credentials)
+
+	server := otterizev1alpha3.KafkaServerConfig{Spec: otterizev1alpha3.KafkaServerConfigSpec{Addr: serverAddress, SASL: sasl}}
+	config, usernameMapping, err := newSaramaConfig(server, nil, credentials)
+	s.Require().NoError(err)
+	s.Require().Equal(defaultSASLPrincipalTemplate, usernameMapping)
+	s.Require().Equal(sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), config.Net.SASL.Mechanism)
+	s.Require().Equal("operator", config.Net.SASL.User)
+	s.Require().True(config.Net.TLS.Enable)
+	s.Require().NotNil(config.Net.SASL.SCRAMClientGeneratorFunc)
+}
+
+func (s *SASLTestSuite) TestPrincipalTemplateAndPlaintext() {
+	server := otterizev1alpha3.KafkaServerConfig{Spec: otterizev1alpha3.KafkaServerConfigSpec{
+		Addr:              serverAddress,
+		SASL:              &otterizev1alpha3.SASLConfig{Mechanism: otterizev1alpha3.SASLMechanismPlain, DisableTLS: true},
+		PrincipalTemplate: "svc-$ServiceName",
+	}}
+	config, usernameMapping, err := newSaramaConfig(server, nil, &saslCredentials{Username: "operator", Password: "secret"})
+	s.Require().NoError(err)
+	s.Require().Equal("svc-$ServiceName", usernameMapping)
+	s.Require().False(config.Net.TLS.Enable)
+
+	admin := &KafkaIntentsAdminImpl{userNameMapping: usernameMapping}
+	s.Require().Equal("User:svc-client", admin.formatPrincipal("client", testNamespace))
+}
+
+func (s *SASLTestSuite) TestMissingSecretKey() {
+	s.expectSecret(map[string][]byte{"client-id": []byte("operator")})
+	sasl := &otterizev1alpha3.SASLConfig{
+		Mechanism:             otterizev1alpha3.SASLMechanismOAuthBearer,
+		TokenURL:              "https://idp.example.com/token",
+		ClientIDSecretRef:     &otterizev1alpha3.SecretKeyRef{Name: saslSecretName, Key: "client-id"},
+		ClientSecretSecretRef: &otterizev1alpha3.SecretKeyRef{Name: saslSecretName, Key: "client-secret"},
+	}
+
+	_, err := resolveSASLCredentials(context.Background(), s.client, testNamespace, sasl)
+	s.Require().ErrorContains(err, "client-secret")
+}
+
+func (s *SASLTestSuite) TestMissingSecretReference() {
+	sasl := &otterizev1alpha3.SASLConfig{Mechanism: otterizev1alpha3.SASLMechanismPlain}
+	_, err := resolveSASLCredentials(context.Background(), s.client, testNamespace, sasl)
+	s.Require().Error(err)
+}
+
+func TestSASLTestSuite(t *testing.T) {
+	suite.Run(t, new(SASLTestSuite))
+}
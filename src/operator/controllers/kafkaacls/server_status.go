@@ -0,0 +1,254 @@
+package kafkaacls
+
+import (
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sort"
+	"sync"
+	"time"
+)
+
+const statusEventsBufferSize = 100
+
+// OperationResult is the outcome of the last attempt of an operation against a Kafka server.
+type OperationResult struct {
+	Error string
+	Time  time.Time
+}
+
+// ServerStatus summarizes what the operator knows about a Kafka server: the state of its admin connection and the
+// results of the last ACL operations applied to it.
+type ServerStatus struct {
+	Connectivity    ServerConnectivity
+	HasConnectivity bool
+	// TopicConfig is nil until the topic configuration was applied once.
+	TopicConfig *OperationResult
+	// Principals maps every principal the operator applied intents for to the result of the last attempt.
+	Principals map[string]OperationResult
+	// ManagedACLs is the number of ACLs the operator expects to exist for the principals.
+	ManagedACLs int
+	// ManagedQuotas maps the users the operator set client quotas for to the sorted quota keys it set. Nil if none.
+	ManagedQuotas map[string][]string
+	// Synced is whether the server was resynced since the operator started. Until then, Principals only holds the
+	// clients whose intents were applied since, rather than every principal with intents on the server.
+	Synced        bool
+	LastSuccess   time.Time
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// PrincipalNames returns the principals with intents on the server, sorted.
+func (s ServerStatus) PrincipalNames() []string {
+	names := make([]string, 0, len(s.Principals))
+	for principal := range s.Principals {
+		names = append(names, principal)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type serverResults struct {
+	kafkaServerConfig types.NamespacedName
+	topicConfig       *OperationResult
+	principals        map[string]OperationResult
+	principalACLs     map[string]int
+	quotas            map[string][]string
+	synced            bool
+	lastSuccess       time.Time
+}
+
+// StatusTracker keeps the results of ACL operations per server. The ClientIntents and KafkaServerConfig controllers
+// both apply ACLs, but only the latter writes the KafkaServerConfig status, so results are collected here and changes
+// are announced on a channel the kafkaserverstatus controller watches.
+type StatusTracker struct {
+	lock    sync.Mutex
+	servers map[types.NamespacedName]*serverResults
+	events  chan event.GenericEvent
+	now     func() time.Time
+}
+
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		servers: make(map[types.NamespacedName]*serverResults),
+		events:  make(chan event.GenericEvent, statusEventsBufferSize),
+		now:     time.Now,
+	}
+}
+
+// Events returns a channel that receives the KafkaServerConfig whose status is out of date whenever the result of
+// applying client intents to its server changes.
+func (t *StatusTracker) Events() <-chan event.GenericEvent {
+	return t.events
+}
+
+func (t *StatusTracker) resultsLocked(kafkaServer otterizev1alpha3.KafkaServerConfig) *serverResults {
+	key := serverKey(kafkaServer)
+	results, ok := t.servers[key]
+	if !ok {
+		results = &serverResults{principals: make(map[string]OperationResult), principalACLs: make(map[string]int), quotas: make(map[string][]string)}
+		// The quotas the operator set are persisted in the status, so they can still be removed after a restart.
+		for userName, quotaKeys := range kafkaServer.Status.ManagedQuotas {
+			results.quotas[userName] = append([]string(nil), quotaKeys...)
+		}
+		t.servers[key] = results
+	}
+	results.kafkaServerConfig = types.NamespacedName{Name: kafkaServer.Name, Namespace: kafkaServer.Namespace}
+	return results
+}
+
+func (t *StatusTracker) newResult(err error) OperationResult {
+	result := OperationResult{Time: t.now()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (t *StatusTracker) recordTopicConfig(kafkaServer otterizev1alpha3.KafkaServerConfig, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	results := t.resultsLocked(kafkaServer)
+	result := t.newResult(err)
+	results.topicConfig = &result
+	if err == nil {
+		results.lastSuccess = result.Time
+	}
+}
+
+// recordClientIntents stores the result of applying the intents of a principal, along with the number of ACLs the
+// operator manages for it. Principals without intents are forgotten once their ACLs were removed successfully.
+func (t *StatusTracker) recordClientIntents(kafkaServer otterizev1alpha3.KafkaServerConfig, principal string, hasIntents bool, managedACLs int, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	results := t.resultsLocked(kafkaServer)
+	previous, existed := results.principals[principal]
+	previousACLs := results.principalACLs[principal]
+	result := t.newResult(err)
+
+	if err == nil {
+		results.lastSuccess = result.Time
+	}
+	if err == nil && !hasIntents {
+		delete(results.principals, principal)
+		delete(results.principalACLs, principal)
+	} else {
+		results.principals[principal] = result
+		if err == nil {
+			results.principalACLs[principal] = managedACLs
+		}
+	}
+
+	_, exists := results.principals[principal]
+	if existed != exists || previous.Error != result.Error || previousACLs != results.principalACLs[principal] {
+		t.notifyLocked(results)
+	}
+}
+
+// recordSync replaces the results of all principals after a successful resync of the server, which applied the
+// intents of every principal with intents on it.
+func (t *StatusTracker) recordSync(kafkaServer otterizev1alpha3.KafkaServerConfig, managedACLsByPrincipal map[string]int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	results := t.resultsLocked(kafkaServer)
+	result := t.newResult(nil)
+	results.synced = true
+	results.lastSuccess = result.Time
+	results.principals = make(map[string]OperationResult, len(managedACLsByPrincipal))
+	results.principalACLs = make(map[string]int, len(managedACLsByPrincipal))
+	for principal, managedACLs := range managedACLsByPrincipal {
+		results.principals[principal] = result
+		results.principalACLs[principal] = managedACLs
+	}
+}
+
+// quotaKeys returns the client quota keys the operator set for the user.
+func (t *StatusTracker) quotaKeys(kafkaServer otterizev1alpha3.KafkaServerConfig, userName string) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]string(nil), t.resultsLocked(kafkaServer).quotas[userName]...)
+}
+
+// recordQuotas stores the client quota keys the operator set for the user, forgetting the user if there are none.
+func (t *StatusTracker) recordQuotas(kafkaServer otterizev1alpha3.KafkaServerConfig, userName string, quotaKeys []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	results := t.resultsLocked(kafkaServer)
+	quotaKeys = append([]string(nil), quotaKeys...)
+	sort.Strings(quotaKeys)
+	if reflect.DeepEqual(results.quotas[userName], quotaKeys) || (len(quotaKeys) == 0 && len(results.quotas[userName]) == 0) {
+		return
+	}
+	if len(quotaKeys) == 0 {
+		delete(results.quotas, userName)
+	} else {
+		results.quotas[userName] = quotaKeys
+	}
+	t.notifyLocked(results)
+}
+
+// notify announces that the status of the server should be refreshed, regardless of ACL operation results.
+func (t *StatusTracker) notify(kafkaServer otterizev1alpha3.KafkaServerConfig) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.notifyLocked(t.resultsLocked(kafkaServer))
+}
+
+func (t *StatusTracker) notifyLocked(results *serverResults) {
+	kafkaServerConfig := &otterizev1alpha3.KafkaServerConfig{}
+	kafkaServerConfig.SetName(results.kafkaServerConfig.Name)
+	kafkaServerConfig.SetNamespace(results.kafkaServerConfig.Namespace)
+	select {
+	case t.events <- event.GenericEvent{Object: kafkaServerConfig}:
+	default:
+		// The controller is behind; the status is refreshed on its next reconcile anyway.
+	}
+}
+
+func (t *StatusTracker) remove(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.servers, key)
+}
+
+func (t *StatusTracker) get(key types.NamespacedName) ServerStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	status := ServerStatus{Principals: make(map[string]OperationResult)}
+	results, ok := t.servers[key]
+	if !ok {
+		return status
+	}
+
+	status.Synced = results.synced
+	status.LastSuccess = results.lastSuccess
+	recordError := func(result OperationResult) {
+		if result.Error != "" && result.Time.After(status.LastErrorTime) {
+			status.LastError = result.Error
+			status.LastErrorTime = result.Time
+		}
+	}
+	if results.topicConfig != nil {
+		topicConfig := *results.topicConfig
+		status.TopicConfig = &topicConfig
+		recordError(topicConfig)
+	}
+	for principal, result := range results.principals {
+		status.Principals[principal] = result
+		status.ManagedACLs += results.principalACLs[principal]
+		recordError(result)
+	}
+	if len(results.quotas) > 0 {
+		status.ManagedQuotas = make(map[string][]string, len(results.quotas))
+		for userName, quotaKeys := range results.quotas {
+			status.ManagedQuotas[userName] = append([]string(nil), quotaKeys...)
+		}
+	}
+	return status
+}
@@ -0,0 +1,130 @@
+package kafkaacls
+
+import (
+	"errors"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"testing"
+	"time"
+)
+
+type StatusTrackerTestSuite struct {
+	suite.Suite
+	tracker     *StatusTracker
+	kafkaServer otterizev1alpha3.KafkaServerConfig
+	now         time.Time
+}
+
+func (s *StatusTrackerTestSuite) SetupTest() {
+	s.now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.tracker = NewStatusTracker()
+	s.tracker.now = func() time.Time { return s.now }
+	s.kafkaServer = otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: serverName}},
+	}
+}
+
+func (s *StatusTrackerTestSuite) status() ServerStatus {
+	return s.tracker.get(types.NamespacedName{Name: serverName, Namespace: testNamespace})
+}
+
+func (s *StatusTrackerTestSuite) expectEvent() {
+	select {
+	case e := <-s.tracker.events:
+		s.Require().Equal(kafkaServerConfigResourceName, e.Object.GetName())
+		s.Require().Equal(testNamespace, e.Object.GetNamespace())
+	default:
+		s.Fail("expected a status event")
+	}
+}
+
+func (s *StatusTrackerTestSuite) expectNoEvent() {
+	select {
+	case <-s.tracker.events:
+		s.Fail("unexpected status event")
+	default:
+	}
+}
+
+func (s *StatusTrackerTestSuite) TestUnknownServer() {
+	status := s.status()
+	s.Require().Nil(status.TopicConfig)
+	s.Require().Empty(status.Principals)
+	s.Require().True(status.LastSuccess.IsZero())
+}
+
+func (s *StatusTrackerTestSuite) TestRecordsClientIntents() {
+	s.tracker.recordClientIntents(s.kafkaServer, "User:b", true, 2, nil)
+	s.expectEvent()
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 3, nil)
+	s.expectEvent()
+
+	// Reapplying the same intents doesn't change the status.
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 3, nil)
+	s.expectNoEvent()
+
+	status := s.status()
+	s.Require().Equal([]string{"User:a", "User:b"}, status.PrincipalNames())
+	s.Require().Equal(5, status.ManagedACLs)
+	s.Require().Equal(s.now, status.LastSuccess)
+	s.Require().Empty(status.LastError)
+}
+
+func (s *StatusTrackerTestSuite) TestFailureKeepsManagedACLs() {
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 3, nil)
+	s.expectEvent()
+
+	s.now = s.now.Add(time.Minute)
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 4, errors.New("broker unavailable"))
+	s.expectEvent()
+
+	status := s.status()
+	s.Require().Equal(3, status.ManagedACLs)
+	s.Require().Equal("broker unavailable", status.LastError)
+	s.Require().Equal(s.now, status.LastErrorTime)
+	s.Require().Equal(s.now.Add(-time.Minute), status.LastSuccess)
+}
+
+func (s *StatusTrackerTestSuite) TestRemovedPrincipalIsForgotten() {
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 3, nil)
+	s.expectEvent()
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", false, 0, nil)
+	s.expectEvent()
+
+	status := s.status()
+	s.Require().Empty(status.Principals)
+	s.Require().Zero(status.ManagedACLs)
+}
+
+func (s *StatusTrackerTestSuite) TestSyncReplacesPrincipals() {
+	s.tracker.recordClientIntents(s.kafkaServer, "User:a", true, 3, errors.New("broker unavailable"))
+	s.expectEvent()
+	s.Require().False(s.status().Synced)
+
+	s.tracker.recordSync(s.kafkaServer, map[string]int{"User:b": 2})
+
+	status := s.status()
+	s.Require().True(status.Synced)
+	s.Require().Equal([]string{"User:b"}, status.PrincipalNames())
+	s.Require().Equal(2, status.ManagedACLs)
+	s.Require().Empty(status.LastError)
+}
+
+func (s *StatusTrackerTestSuite) TestTopicConfig() {
+	s.tracker.recordTopicConfig(s.kafkaServer, errors.New("invalid topic"))
+
+	status := s.status()
+	s.Require().NotNil(status.TopicConfig)
+	s.Require().Equal("invalid topic", status.TopicConfig.Error)
+	s.Require().Equal("invalid topic", status.LastError)
+
+	s.tracker.remove(types.NamespacedName{Name: serverName, Namespace: testNamespace})
+	s.Require().Nil(s.status().TopicConfig)
+}
+
+func TestStatusTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusTrackerTestSuite))
+}
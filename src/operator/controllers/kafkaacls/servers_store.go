@@ -4,6 +4,7 @@ import (
 	"errors"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
 	"k8s.io/apimachinery/pkg/types"
+	"sync"
 )
 
 var (
@@ -15,46 +16,57 @@ type ServersStore interface {
 	Remove(serverName string, namespace string)
 	Exists(serverName string, namespace string) bool
 	Get(serverName string, namespace string) (KafkaIntentsAdmin, error)
+	GetWithEnforcement(serverName string, namespace string, enforcementEnabled bool) (KafkaIntentsAdmin, error)
+	Status(serverName string, namespace string) ServerStatus
 	MapErr(f func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error) error
 }
 
 type ServersStoreImpl struct {
+	// lock guards serversByName, which is updated by the KafkaServerConfig controller while the ClientIntents controller
+	// and the ACL resync read it.
+	lock                        sync.RWMutex
 	serversByName               map[types.NamespacedName]*otterizev1alpha3.KafkaServerConfig
 	enableKafkaACLCreation      bool
 	tlsSourceFiles              otterizev1alpha3.TLSSource
 	IntentsAdminFactoryFunction IntentsAdminFactoryFunction
 	enforcementDefaultState     bool
+	adminPool                   *AdminPool
 }
 
-func NewServersStore(tlsSourceFiles otterizev1alpha3.TLSSource, enableKafkaACLCreation bool, factoryFunc IntentsAdminFactoryFunction, enforcementDefaultState bool) *ServersStoreImpl {
+func NewServersStore(tlsSourceFiles otterizev1alpha3.TLSSource, enableKafkaACLCreation bool, factoryFunc IntentsAdminFactoryFunction, enforcementDefaultState bool, adminPool *AdminPool) *ServersStoreImpl {
 	return &ServersStoreImpl{
 		serversByName:               map[types.NamespacedName]*otterizev1alpha3.KafkaServerConfig{},
 		enableKafkaACLCreation:      enableKafkaACLCreation,
 		tlsSourceFiles:              tlsSourceFiles,
 		IntentsAdminFactoryFunction: factoryFunc,
 		enforcementDefaultState:     enforcementDefaultState,
+		adminPool:                   adminPool,
 	}
 }
 
 func (s *ServersStoreImpl) Add(config *otterizev1alpha3.KafkaServerConfig) {
 	name := types.NamespacedName{Name: config.Spec.Service.Name, Namespace: config.Namespace}
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.serversByName[name] = config
 }
 
 func (s *ServersStoreImpl) Remove(serverName string, namespace string) {
 	name := types.NamespacedName{Name: serverName, Namespace: namespace}
+	s.lock.Lock()
 	delete(s.serversByName, name)
+	s.lock.Unlock()
+	s.adminPool.evict(name)
+	s.adminPool.statusTracker.remove(name)
 }
 
 func (s *ServersStoreImpl) Exists(serverName string, namespace string) bool {
-	name := types.NamespacedName{Name: serverName, Namespace: namespace}
-	_, ok := s.serversByName[name]
+	_, ok := s.getConfig(serverName, namespace)
 	return ok
 }
 
 func (s *ServersStoreImpl) Get(serverName string, namespace string) (KafkaIntentsAdmin, error) {
-	name := types.NamespacedName{Name: serverName, Namespace: namespace}
-	config, ok := s.serversByName[name]
+	config, ok := s.getConfig(serverName, namespace)
 	if !ok {
 		return nil, ServerSpecNotFound
 	}
@@ -62,8 +74,45 @@ func (s *ServersStoreImpl) Get(serverName string, namespace string) (KafkaIntent
 	return s.IntentsAdminFactoryFunction(*config, s.tlsSourceFiles, s.enableKafkaACLCreation, s.enforcementDefaultState)
 }
 
+// GetWithEnforcement returns an admin for the server whose enforcement state was decided by the caller, typically
+// taking ProtectedServices into account, instead of the global default.
+func (s *ServersStoreImpl) GetWithEnforcement(serverName string, namespace string, enforcementEnabled bool) (KafkaIntentsAdmin, error) {
+	config, ok := s.getConfig(serverName, namespace)
+	if !ok {
+		return nil, ServerSpecNotFound
+	}
+
+	return s.IntentsAdminFactoryFunction(*config, s.tlsSourceFiles, s.enableKafkaACLCreation, enforcementEnabled)
+}
+
+// Status returns the state of the pooled admin connection to the server, if one was attempted, along with the results
+// of the last ACL operations applied to it.
+func (s *ServersStoreImpl) Status(serverName string, namespace string) ServerStatus {
+	name := types.NamespacedName{Name: serverName, Namespace: namespace}
+	status := s.adminPool.statusTracker.get(name)
+	status.Connectivity, status.HasConnectivity = s.adminPool.getConnectivity(name)
+	return status
+}
+
+// getConfig returns the config of the server. The lock isn't held while admins are created, since that may connect to
+// the server.
+func (s *ServersStoreImpl) getConfig(serverName string, namespace string) (*otterizev1alpha3.KafkaServerConfig, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	config, ok := s.serversByName[types.NamespacedName{Name: serverName, Namespace: namespace}]
+	return config, ok
+}
+
+// MapErr calls f for every server. It iterates over a copy of the servers, so f may use the store.
 func (s *ServersStoreImpl) MapErr(f func(types.NamespacedName, *otterizev1alpha3.KafkaServerConfig, otterizev1alpha3.TLSSource) error) error {
+	s.lock.RLock()
+	serversByName := make(map[types.NamespacedName]*otterizev1alpha3.KafkaServerConfig, len(s.serversByName))
 	for serverName, config := range s.serversByName {
+		serversByName[serverName] = config
+	}
+	s.lock.RUnlock()
+
+	for serverName, config := range serversByName {
 		if err := f(serverName, config, s.tlsSourceFiles); err != nil {
 			return err
 		}
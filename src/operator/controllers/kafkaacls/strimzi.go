@@ -0,0 +1,472 @@
+package kafkaacls
+
+import (
+	"context"
+	"fmt"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"time"
+)
+
+const (
+	strimziClusterLabelKey   = "strimzi.io/cluster"
+	strimziKafkaServerLabel  = "intents.otterize.com/kafka-server"
+	strimziManagedByLabelKey = "app.kubernetes.io/managed-by"
+	strimziManagedByValue    = "intents-operator"
+	strimziRequestTimeout    = 30 * time.Second
+)
+
+// strimziManagedSpecFields are the fields of the KafkaUser spec the operator owns. Authentication is only set when
+// creating the KafkaUser, so it can be changed by hand.
+var strimziManagedSpecFields = []string{"authorization", "quotas"}
+
+var kafkaUserGVK = schema.GroupVersionKind{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaUser"}
+
+// StrimziIntentsAdmin expresses Kafka intents as Strimzi KafkaUser resources with simple authorization, which the
+// Strimzi User Operator turns into ACLs. Each client gets one KafkaUser, named after the principal template, holding
+// all of its ACLs on the cluster.
+type StrimziIntentsAdmin struct {
+	k8sClient                   client.Client
+	kafkaServer                 otterizev1alpha3.KafkaServerConfig
+	enableKafkaACLCreation      bool
+	enforcementEnabledForServer bool
+	statusTracker               *StatusTracker
+}
+
+//+kubebuilder:rbac:groups=kafka.strimzi.io,resources=kafkausers,verbs=get;list;create;update;delete
+
+func NewStrimziIntentsAdmin(k8sClient client.Client, kafkaServer otterizev1alpha3.KafkaServerConfig, enableKafkaACLCreation bool, enforcementEnabledForServer bool, statusTracker *StatusTracker) KafkaIntentsAdmin {
+	return &StrimziIntentsAdmin{
+		k8sClient:                   k8sClient,
+		kafkaServer:                 kafkaServer,
+		enableKafkaACLCreation:      enableKafkaACLCreation,
+		enforcementEnabledForServer: enforcementEnabledForServer,
+		statusTracker:               statusTracker,
+	}
+}
+
+func (a *StrimziIntentsAdmin) namespace() string {
+	if a.kafkaServer.Spec.Strimzi.Namespace != "" {
+		return a.kafkaServer.Spec.Strimzi.Namespace
+	}
+	return a.kafkaServer.Namespace
+}
+
+func (a *StrimziIntentsAdmin) serverLabelValue() string {
+	return fmt.Sprintf("%s.%s", a.kafkaServer.Spec.Service.Name, a.kafkaServer.Namespace)
+}
+
+func (a *StrimziIntentsAdmin) kafkaUserName(clientName string, clientNamespace string) string {
+	template := a.kafkaServer.Spec.PrincipalTemplate
+	if template == "" {
+		template = defaultSASLPrincipalTemplate
+	}
+	return formatUsername(template, clientName, clientNamespace)
+}
+
+func (a *StrimziIntentsAdmin) logger(userName string) *logrus.Entry {
+	return logrus.WithFields(logrus.Fields{
+		"kafkaUser":       userName,
+		"serverName":      a.kafkaServer.Spec.Service,
+		"serverNamespace": a.kafkaServer.Namespace,
+	})
+}
+
+func (a *StrimziIntentsAdmin) ApplyServerTopicsConf(ctx context.Context, topicsConf []otterizev1alpha3.TopicConfig) error {
+	// KafkaUsers only hold ACLs for their own principal, so the anonymous and wildcard ACLs derived from the topic
+	// configuration can't be expressed. Those defaults belong to the authorization settings of the Kafka resource.
+	if len(topicsConf) > 0 {
+		a.logger("").Info("Topic configuration is not applied with the Strimzi backend, configure authorization on the Kafka resource instead")
+	}
+	return nil
+}
+
+func (a *StrimziIntentsAdmin) ApplyClientIntents(ctx context.Context, clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) error {
+	if !hasKafkaTopics(intents) {
+		return a.RemoveClientIntents(ctx, clientName, clientNamespace)
+	}
+
+	userName := a.kafkaUserName(clientName, clientNamespace)
+	managedACLs, err := a.applyClientIntents(ctx, userName, clientName, clientNamespace, intents)
+	a.statusTracker.recordClientIntents(a.kafkaServer, userName, true, managedACLs, err)
+	return err
+}
+
+// applyClientIntents returns the number of ACLs the KafkaUser holds after applying the intents.
+func (a *StrimziIntentsAdmin) applyClientIntents(ctx context.Context, userName string, clientName string, clientNamespace string, intents []otterizev1alpha3.Intent) (int, error) {
+	logger := a.logger(userName)
+
+	spec, err := strimziSpec(intents)
+	if err != nil {
+		return 0, err
+	}
+
+	if !a.enableKafkaACLCreation {
+		logger.Info("Skipped updating KafkaUser because Kafka ACL Creation is disabled")
+		return 0, nil
+	}
+	if !a.enforcementEnabledForServer {
+		logger.Info("Skipped updating KafkaUser because enforcement is disabled")
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, strimziRequestTimeout)
+	defer cancel()
+
+	kafkaUser, err := a.getKafkaUser(ctx, userName)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := a.applyKafkaUser(ctx, kafkaUser, userName, clientName, clientNamespace, spec); err != nil {
+		return 0, err
+	}
+	return strimziACLCount(spec), nil
+}
+
+// applyKafkaUser creates the KafkaUser if it doesn't exist yet, or updates its authorization and quotas, and reports
+// whether anything had to be changed.
+func (a *StrimziIntentsAdmin) applyKafkaUser(ctx context.Context, kafkaUser *unstructured.Unstructured, userName string, clientName string, clientNamespace string, spec map[string]interface{}) (bool, error) {
+	logger := a.logger(userName)
+
+	if kafkaUser == nil {
+		kafkaUser = a.newKafkaUser(userName)
+		if err := setStrimziSpec(kafkaUser, spec); err != nil {
+			return false, fmt.Errorf("failed setting spec of KafkaUser %s: %w", userName, err)
+		}
+		logger.Info("Creating KafkaUser")
+		if err := a.k8sClient.Create(ctx, kafkaUser); err != nil {
+			return false, fmt.Errorf("failed creating KafkaUser %s: %w", userName, err)
+		}
+		a.audit(ctx, auditlog.ActionCreated, userName, clientName, clientNamespace, nil, spec)
+		return true, nil
+	}
+
+	if !isManagedKafkaUser(kafkaUser) {
+		return false, fmt.Errorf("KafkaUser %s/%s exists and is not managed by the intents operator", a.namespace(), userName)
+	}
+
+	existing, err := managedStrimziSpec(kafkaUser)
+	if err != nil {
+		return false, fmt.Errorf("failed reading spec of KafkaUser %s: %w", userName, err)
+	}
+	if reflect.DeepEqual(existing, spec) {
+		logger.Info("KafkaUser is up to date")
+		return false, nil
+	}
+
+	if err := setStrimziSpec(kafkaUser, spec); err != nil {
+		return false, fmt.Errorf("failed setting spec of KafkaUser %s: %w", userName, err)
+	}
+	logger.Info("Updating KafkaUser ACLs and quotas")
+	if err := a.k8sClient.Update(ctx, kafkaUser); err != nil {
+		return false, fmt.Errorf("failed updating KafkaUser %s: %w", userName, err)
+	}
+	a.audit(ctx, auditlog.ActionUpdated, userName, clientName, clientNamespace, existing, spec)
+	return true, nil
+}
+
+func (a *StrimziIntentsAdmin) RemoveClientIntents(ctx context.Context, clientName string, clientNamespace string) error {
+	userName := a.kafkaUserName(clientName, clientNamespace)
+	err := a.removeClientIntents(ctx, userName, clientName, clientNamespace)
+	a.statusTracker.recordClientIntents(a.kafkaServer, userName, err != nil, 0, err)
+	return err
+}
+
+func (a *StrimziIntentsAdmin) removeClientIntents(ctx context.Context, userName string, clientName string, clientNamespace string) error {
+	ctx, cancel := context.WithTimeout(ctx, strimziRequestTimeout)
+	defer cancel()
+
+	kafkaUser, err := a.getKafkaUser(ctx, userName)
+	if err != nil {
+		return err
+	}
+	if kafkaUser == nil || !isManagedKafkaUser(kafkaUser) {
+		return nil
+	}
+	return a.deleteKafkaUser(ctx, kafkaUser, clientName, clientNamespace)
+}
+
+func (a *StrimziIntentsAdmin) RemoveServerIntents(ctx context.Context, _ []otterizev1alpha3.TopicConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, strimziRequestTimeout)
+	defer cancel()
+
+	kafkaUsers, err := a.listManagedKafkaUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range kafkaUsers {
+		if err := a.deleteKafkaUser(ctx, &kafkaUsers[i], "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncClientIntents makes the KafkaUsers managed for this server match the intents of its clients. Drift is counted in
+// KafkaUsers: users that were recreated or updated count as missing, and users of clients that no longer have intents
+// count as unexpected. Only KafkaUsers labeled as managed by the operator are touched, so managed principals aren't
+// needed to tell them apart.
+func (a *StrimziIntentsAdmin) SyncClientIntents(ctx context.Context, intentsByClient map[types.NamespacedName][]otterizev1alpha3.Intent, _ []string) (int, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, strimziRequestTimeout)
+	defer cancel()
+
+	kafkaUsers, err := a.listManagedKafkaUsers(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	existingByName := lo.SliceToMap(kafkaUsers, func(kafkaUser unstructured.Unstructured) (string, *unstructured.Unstructured) {
+		return kafkaUser.GetName(), kafkaUser.DeepCopy()
+	})
+
+	missing, unexpected := 0, 0
+	desired := make(map[string]int)
+	for clientName, intents := range intentsByClient {
+		if !hasKafkaTopics(intents) {
+			continue
+		}
+		userName := a.kafkaUserName(clientName.Name, clientName.Namespace)
+		desired[userName] = 0
+
+		if !a.enableKafkaACLCreation || !a.enforcementEnabledForServer {
+			continue
+		}
+
+		spec, err := strimziSpec(intents)
+		if err != nil {
+			return missing, unexpected, err
+		}
+		desired[userName] = strimziACLCount(spec)
+		kafkaUser, ok := existingByName[userName]
+		if !ok {
+			// Not labeled as ours, but may still exist - applyKafkaUser refuses to take over unmanaged users.
+			kafkaUser, err = a.getKafkaUser(ctx, userName)
+			if err != nil {
+				return missing, unexpected, err
+			}
+		}
+		changed, err := a.applyKafkaUser(ctx, kafkaUser, userName, clientName.Name, clientName.Namespace, spec)
+		if err != nil {
+			return missing, unexpected, err
+		}
+		if changed {
+			missing++
+		}
+	}
+
+	for userName, kafkaUser := range existingByName {
+		if _, ok := desired[userName]; ok {
+			continue
+		}
+		if err := a.deleteKafkaUser(ctx, kafkaUser, "", ""); err != nil {
+			return missing, unexpected, err
+		}
+		unexpected++
+	}
+
+	a.statusTracker.recordSync(a.kafkaServer, desired)
+	return missing, unexpected, nil
+}
+
+func (a *StrimziIntentsAdmin) ApplyManagedTopics(ctx context.Context, topics []otterizev1alpha3.ManagedKafkaTopic) error {
+	if len(topics) > 0 {
+		a.logger("").Info("Managed topics are not applied with the Strimzi backend, use Strimzi KafkaTopic resources instead")
+	}
+	return nil
+}
+
+func (a *StrimziIntentsAdmin) DeleteManagedTopics(ctx context.Context, _ []string) error {
+	return nil
+}
+
+func (a *StrimziIntentsAdmin) Close() {}
+
+func (a *StrimziIntentsAdmin) listManagedKafkaUsers(ctx context.Context) ([]unstructured.Unstructured, error) {
+	kafkaUsers := &unstructured.UnstructuredList{}
+	kafkaUsers.SetGroupVersionKind(kafkaUserGVK.GroupVersion().WithKind(kafkaUserGVK.Kind + "List"))
+	err := a.k8sClient.List(ctx, kafkaUsers, client.InNamespace(a.namespace()), client.MatchingLabels{
+		strimziKafkaServerLabel:  a.serverLabelValue(),
+		strimziManagedByLabelKey: strimziManagedByValue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing KafkaUsers: %w", err)
+	}
+	return kafkaUsers.Items, nil
+}
+
+func (a *StrimziIntentsAdmin) getKafkaUser(ctx context.Context, userName string) (*unstructured.Unstructured, error) {
+	kafkaUser := &unstructured.Unstructured{}
+	kafkaUser.SetGroupVersionKind(kafkaUserGVK)
+	err := a.k8sClient.Get(ctx, types.NamespacedName{Name: userName, Namespace: a.namespace()}, kafkaUser)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed getting KafkaUser %s: %w", userName, err)
+	}
+	return kafkaUser, nil
+}
+
+func (a *StrimziIntentsAdmin) deleteKafkaUser(ctx context.Context, kafkaUser *unstructured.Unstructured, clientName string, clientNamespace string) error {
+	a.logger(kafkaUser.GetName()).Info("Deleting KafkaUser")
+	if err := a.k8sClient.Delete(ctx, kafkaUser); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed deleting KafkaUser %s: %w", kafkaUser.GetName(), err)
+	}
+	before, _ := managedStrimziSpec(kafkaUser)
+	a.audit(ctx, auditlog.ActionDeleted, kafkaUser.GetName(), clientName, clientNamespace, before, nil)
+	return nil
+}
+
+func (a *StrimziIntentsAdmin) newKafkaUser(userName string) *unstructured.Unstructured {
+	spec := map[string]interface{}{}
+	if authentication := a.kafkaServer.Spec.Strimzi.Authentication; authentication != "" {
+		spec["authentication"] = map[string]interface{}{"type": string(authentication)}
+	}
+
+	kafkaUser := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	kafkaUser.SetGroupVersionKind(kafkaUserGVK)
+	kafkaUser.SetName(userName)
+	kafkaUser.SetNamespace(a.namespace())
+	kafkaUser.SetLabels(map[string]string{
+		strimziClusterLabelKey:   a.kafkaServer.Spec.Strimzi.ClusterName,
+		strimziKafkaServerLabel:  a.serverLabelValue(),
+		strimziManagedByLabelKey: strimziManagedByValue,
+	})
+	return kafkaUser
+}
+
+func (a *StrimziIntentsAdmin) audit(ctx context.Context, action auditlog.Action, userName string, clientName string, clientNamespace string, before any, after any) {
+	// Changes for a client are attributed to the ClientIntents the context carries, others to the server.
+	var trigger *auditlog.Trigger
+	if clientName == "" {
+		trigger = &auditlog.Trigger{Kind: "KafkaServerConfig", Name: a.kafkaServer.Name, Namespace: a.kafkaServer.Namespace}
+	}
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            action,
+		ResourceKind:      auditlog.ResourceKindKafkaACL,
+		ResourceName:      fmt.Sprintf("%s:%s", a.kafkaServer.Spec.Service.Name, userName),
+		ResourceNamespace: a.kafkaServer.Namespace,
+		Trigger:           trigger,
+		Diff:              auditlog.Diff(before, after),
+	})
+}
+
+func isManagedKafkaUser(kafkaUser *unstructured.Unstructured) bool {
+	return kafkaUser.GetLabels()[strimziManagedByLabelKey] == strimziManagedByValue
+}
+
+// strimziSpec builds the parts of the KafkaUser spec managed by the operator: the authorization derived from the
+// client's topics, and the quotas requested by its intents.
+func strimziSpec(intents []otterizev1alpha3.Intent) (map[string]interface{}, error) {
+	topics := lo.Flatten(lo.Map(intents, func(intent otterizev1alpha3.Intent, _ int) []otterizev1alpha3.KafkaTopic {
+		return intent.Topics
+	}))
+	authorization, err := strimziAuthorization(topics)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]interface{}{"authorization": authorization}
+	if quotas := strimziQuotas(mergeKafkaQuotas(intents)); len(quotas) > 0 {
+		spec["quotas"] = quotas
+	}
+	return spec, nil
+}
+
+// managedStrimziSpec returns the fields of the KafkaUser spec managed by the operator, in the form built by strimziSpec.
+func managedStrimziSpec(kafkaUser *unstructured.Unstructured) (map[string]interface{}, error) {
+	spec := make(map[string]interface{})
+	for _, field := range strimziManagedSpecFields {
+		value, found, err := unstructured.NestedMap(kafkaUser.Object, "spec", field)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			spec[field] = value
+		}
+	}
+	return spec, nil
+}
+
+// setStrimziSpec sets the managed fields of the KafkaUser spec, removing the ones that are no longer wanted.
+func setStrimziSpec(kafkaUser *unstructured.Unstructured, spec map[string]interface{}) error {
+	for _, field := range strimziManagedSpecFields {
+		value, ok := spec[field].(map[string]interface{})
+		if !ok {
+			unstructured.RemoveNestedField(kafkaUser.Object, "spec", field)
+			continue
+		}
+		if err := unstructured.SetNestedMap(kafkaUser.Object, value, "spec", field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// strimziQuotas maps quotas to the quotas section of a KafkaUser, leaving out quotas that are not set.
+func strimziQuotas(quotas *otterizev1alpha3.KafkaQuotas) map[string]interface{} {
+	if quotas == nil {
+		return nil
+	}
+	values := map[string]interface{}{
+		"producerByteRate":  quotas.ProducerByteRate,
+		"consumerByteRate":  quotas.ConsumerByteRate,
+		"requestPercentage": int64(quotas.RequestPercentage),
+	}
+	return lo.PickBy(values, func(_ string, value interface{}) bool { return value.(int64) > 0 })
+}
+
+// strimziAuthorization builds the simple authorization section of a KafkaUser from the client's topics, using the
+// same operation mapping as the broker ACLs. Consumers also need access to their consumer groups, which the broker
+// backend grants to all users with a wildcard group ACL.
+func strimziAuthorization(topics []otterizev1alpha3.KafkaTopic) (map[string]interface{}, error) {
+	operationsByTopic := make(map[string][]string)
+	for _, topic := range topics {
+		for _, operation := range topic.Operations {
+			aclOperation, ok := KafkaOperationToAclOperationBMap.Get(operation)
+			if !ok {
+				return nil, fmt.Errorf("unknown operation '%v'", operation)
+			}
+			operationsByTopic[topic.Name] = append(operationsByTopic[topic.Name], aclOperation.String())
+		}
+	}
+
+	topicNames := lo.Keys(operationsByTopic)
+	sort.Strings(topicNames)
+	acls := make([]interface{}, 0, len(topicNames)+1)
+	for _, topicName := range topicNames {
+		operations := lo.Uniq(operationsByTopic[topicName])
+		sort.Strings(operations)
+		acls = append(acls, strimziACL("topic", topicName, operations))
+	}
+	acls = append(acls, strimziACL("group", "*", []string{"Describe", "Read"}))
+
+	return map[string]interface{}{"type": "simple", "acls": acls}, nil
+}
+
+func strimziACLCount(spec map[string]interface{}) int {
+	authorization, _ := spec["authorization"].(map[string]interface{})
+	acls, _ := authorization["acls"].([]interface{})
+	return len(acls)
+}
+
+func strimziACL(resourceType string, name string, operations []string) map[string]interface{} {
+	return map[string]interface{}{
+		"resource": map[string]interface{}{
+			"type":        resourceType,
+			"name":        name,
+			"patternType": "literal",
+		},
+		"operations": lo.ToAnySlice(operations),
+		"host":       "*",
+	}
+}
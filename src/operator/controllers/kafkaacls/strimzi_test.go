@@ -0,0 +1,207 @@
+package kafkaacls
+
+import (
+	"context"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+const (
+	strimziClusterName = "my-cluster"
+	strimziNamespace   = "kafka"
+	clientName         = "client"
+)
+
+type StrimziIntentsAdminTestSuite struct {
+	suite.Suite
+	client       *mocks.MockClient
+	intentsAdmin KafkaIntentsAdmin
+}
+
+func (s *StrimziIntentsAdminTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.client = mocks.NewMockClient(controller)
+	kafkaServer := otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec: otterizev1alpha3.KafkaServerConfigSpec{
+			Service: otterizev1alpha3.Service{Name: serverName},
+			Strimzi: &otterizev1alpha3.StrimziConfig{
+				ClusterName:    strimziClusterName,
+				Namespace:      strimziNamespace,
+				Authentication: otterizev1alpha3.StrimziAuthenticationTLS,
+			},
+		},
+	}
+	s.intentsAdmin = NewStrimziIntentsAdmin(s.client, kafkaServer, true, true, NewStatusTracker())
+}
+
+func (s *StrimziIntentsAdminTestSuite) kafkaUserKey() types.NamespacedName {
+	return types.NamespacedName{Name: clientName + "." + testNamespace, Namespace: strimziNamespace}
+}
+
+func (s *StrimziIntentsAdminTestSuite) expectKafkaUser(existing *unstructured.Unstructured) {
+	s.client.EXPECT().Get(gomock.Any(), s.kafkaUserKey(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, kafkaUser *unstructured.Unstructured, opts ...client.GetOption) error {
+			if existing == nil {
+				return k8serrors.NewNotFound(kafkaUserGVK.GroupVersion().WithResource("kafkausers").GroupResource(), name.Name)
+			}
+			existing.DeepCopyInto(kafkaUser)
+			return nil
+		})
+}
+
+func (s *StrimziIntentsAdminTestSuite) intents() []otterizev1alpha3.Intent {
+	return []otterizev1alpha3.Intent{{
+		Name: serverName,
+		Type: otterizev1alpha3.IntentTypeKafka,
+		Topics: []otterizev1alpha3.KafkaTopic{
+			{Name: "orders", Operations: []otterizev1alpha3.KafkaOperation{otterizev1alpha3.KafkaOperationConsume, otterizev1alpha3.KafkaOperationDescribe}},
+			{Name: "events", Operations: []otterizev1alpha3.KafkaOperation{otterizev1alpha3.KafkaOperationProduce}},
+		},
+	}}
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestCreatesKafkaUser() {
+	s.expectKafkaUser(nil)
+	var created *unstructured.Unstructured
+	s.client.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, kafkaUser *unstructured.Unstructured, opts ...client.CreateOption) error {
+			created = kafkaUser
+			return nil
+		})
+
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+
+	s.Require().Equal(s.kafkaUserKey().Name, created.GetName())
+	s.Require().Equal(strimziNamespace, created.GetNamespace())
+	s.Require().Equal(strimziClusterName, created.GetLabels()[strimziClusterLabelKey])
+	s.Require().Equal("KafkaUser", created.GetKind())
+
+	authenticationType, _, _ := unstructured.NestedString(created.Object, "spec", "authentication", "type")
+	s.Require().Equal("tls", authenticationType)
+
+	acls, _, _ := unstructured.NestedSlice(created.Object, "spec", "authorization", "acls")
+	s.Require().Equal([]interface{}{
+		strimziACL("topic", "events", []string{"Write"}),
+		strimziACL("topic", "orders", []string{"Describe", "Read"}),
+		strimziACL("group", "*", []string{"Describe", "Read"}),
+	}, acls)
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestUpToDateKafkaUserIsNotUpdated() {
+	authorization, err := strimziAuthorization(s.intents()[0].Topics)
+	s.Require().NoError(err)
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"authorization": authorization},
+	}}
+	existing.SetLabels(map[string]string{strimziManagedByLabelKey: strimziManagedByValue})
+	s.expectKafkaUser(existing)
+
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestUpdatesChangedACLs() {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"authorization": map[string]interface{}{"type": "simple", "acls": []interface{}{}}},
+	}}
+	existing.SetLabels(map[string]string{strimziManagedByLabelKey: strimziManagedByValue})
+	s.expectKafkaUser(existing)
+	s.client.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestSetsAndRemovesQuotas() {
+	authorization, err := strimziAuthorization(s.intents()[0].Topics)
+	s.Require().NoError(err)
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"authorization": authorization},
+	}}
+	existing.SetLabels(map[string]string{strimziManagedByLabelKey: strimziManagedByValue})
+	var updated *unstructured.Unstructured
+	s.client.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, kafkaUser *unstructured.Unstructured, opts ...client.UpdateOption) error {
+			updated = kafkaUser.DeepCopy()
+			return nil
+		}).Times(2)
+
+	intents := s.intents()
+	intents[0].KafkaQuotas = &otterizev1alpha3.KafkaQuotas{ProducerByteRate: 1024, RequestPercentage: 50}
+	s.expectKafkaUser(existing)
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, intents))
+
+	quotas, _, _ := unstructured.NestedMap(updated.Object, "spec", "quotas")
+	s.Require().Equal(map[string]interface{}{"producerByteRate": int64(1024), "requestPercentage": int64(50)}, quotas)
+
+	s.expectKafkaUser(updated)
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+
+	_, found, _ := unstructured.NestedMap(updated.Object, "spec", "quotas")
+	s.Require().False(found)
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestRefusesUnmanagedKafkaUser() {
+	s.expectKafkaUser(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	s.Require().Error(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestRemoveClientIntentsDeletesKafkaUser() {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	existing.SetName(s.kafkaUserKey().Name)
+	existing.SetLabels(map[string]string{strimziManagedByLabelKey: strimziManagedByValue})
+	s.expectKafkaUser(existing)
+	s.client.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.RemoveClientIntents(context.Background(), clientName, testNamespace))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestRemoveServerIntentsDeletesManagedUsers() {
+	s.client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, list *unstructured.UnstructuredList, opts ...client.ListOption) error {
+			list.Items = []unstructured.Unstructured{{Object: map[string]interface{}{}}, {Object: map[string]interface{}{}}}
+			return nil
+		})
+	s.client.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	s.Require().NoError(s.intentsAdmin.RemoveServerIntents(context.Background(), nil))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestEnforcementDisabled() {
+	kafkaServer := s.intentsAdmin.(*StrimziIntentsAdmin).kafkaServer
+	s.intentsAdmin = NewStrimziIntentsAdmin(s.client, kafkaServer, true, false, NewStatusTracker())
+
+	s.Require().NoError(s.intentsAdmin.ApplyClientIntents(context.Background(), clientName, testNamespace, s.intents()))
+}
+
+func (s *StrimziIntentsAdminTestSuite) TestSyncClientIntents() {
+	stale := unstructured.Unstructured{Object: map[string]interface{}{}}
+	stale.SetName("removed." + testNamespace)
+	stale.SetLabels(map[string]string{strimziManagedByLabelKey: strimziManagedByValue})
+	s.client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, list *unstructured.UnstructuredList, opts ...client.ListOption) error {
+			list.Items = []unstructured.Unstructured{stale}
+			return nil
+		})
+	s.expectKafkaUser(nil)
+	s.client.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.client.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+
+	missing, unexpected, err := s.intentsAdmin.SyncClientIntents(context.Background(), map[types.NamespacedName][]otterizev1alpha3.Intent{
+		{Name: clientName, Namespace: testNamespace}: s.intents(),
+	}, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(1, missing)
+	s.Require().Equal(1, unexpected)
+}
+
+func TestStrimziIntentsAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(StrimziIntentsAdminTestSuite))
+}
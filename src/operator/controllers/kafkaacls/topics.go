@@ -0,0 +1,162 @@
+package kafkaacls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+)
+
+// Let the broker pick the partition count and replication factor when they are not set (requires Kafka 2.4).
+const brokerDefault = -1
+
+// ApplyManagedTopics creates the topics that don't exist on the server yet, and brings existing topics in line with
+// their spec: partitions are added when more are requested, and configs that differ are set. Topics are never deleted
+// here, see DeleteManagedTopics.
+func (a *KafkaIntentsAdminImpl) ApplyManagedTopics(ctx context.Context, topics []otterizev1alpha3.ManagedKafkaTopic) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	names := lo.Map(topics, func(topic otterizev1alpha3.ManagedKafkaTopic, _ int) string { return topic.Name })
+	metadata, err := a.kafkaAdminClient.DescribeTopics(names)
+	if err != nil {
+		return fmt.Errorf("failed describing topics: %w", err)
+	}
+	metadataByName := lo.SliceToMap(metadata, func(topicMetadata *sarama.TopicMetadata) (string, *sarama.TopicMetadata) {
+		return topicMetadata.Name, topicMetadata
+	})
+
+	errs := make([]error, 0)
+	for _, topic := range topics {
+		topicMetadata, ok := metadataByName[topic.Name]
+		if !ok || errors.Is(topicMetadata.Err, sarama.ErrUnknownTopicOrPartition) {
+			errs = append(errs, a.createTopic(ctx, topic))
+			continue
+		}
+		if topicMetadata.Err != sarama.ErrNoError {
+			errs = append(errs, fmt.Errorf("failed describing topic %s: %w", topic.Name, topicMetadata.Err))
+			continue
+		}
+		errs = append(errs, a.updateTopic(ctx, topic, topicMetadata))
+	}
+	return errors.Join(errs...)
+}
+
+func (a *KafkaIntentsAdminImpl) createTopic(ctx context.Context, topic otterizev1alpha3.ManagedKafkaTopic) error {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     brokerDefault,
+		ReplicationFactor: brokerDefault,
+		ConfigEntries:     configEntries(topic.Configs),
+	}
+	if topic.Partitions > 0 {
+		detail.NumPartitions = topic.Partitions
+	}
+	if topic.ReplicationFactor > 0 {
+		detail.ReplicationFactor = topic.ReplicationFactor
+	}
+
+	a.topicLogger(topic.Name).Info("Creating topic")
+	if err := a.kafkaAdminClient.CreateTopic(topic.Name, detail, false); err != nil {
+		return fmt.Errorf("failed creating topic %s: %w", topic.Name, err)
+	}
+	a.auditTopic(ctx, auditlog.ActionCreated, topic.Name, nil, topic)
+	return nil
+}
+
+func (a *KafkaIntentsAdminImpl) updateTopic(ctx context.Context, topic otterizev1alpha3.ManagedKafkaTopic, topicMetadata *sarama.TopicMetadata) error {
+	logger := a.topicLogger(topic.Name)
+
+	partitions := int32(len(topicMetadata.Partitions))
+	if topic.Partitions > 0 && topic.Partitions < partitions {
+		return fmt.Errorf("topic %s has %d partitions, partitions cannot be removed", topic.Name, partitions)
+	}
+	if topic.Partitions > partitions {
+		logger.Infof("Increasing partitions from %d to %d", partitions, topic.Partitions)
+		if err := a.kafkaAdminClient.CreatePartitions(topic.Name, topic.Partitions, nil, false); err != nil {
+			return fmt.Errorf("failed adding partitions to topic %s: %w", topic.Name, err)
+		}
+		a.auditTopic(ctx, auditlog.ActionUpdated, topic.Name, map[string]int32{"partitions": partitions}, map[string]int32{"partitions": topic.Partitions})
+	}
+
+	if topic.ReplicationFactor > 0 && partitions > 0 && int(topic.ReplicationFactor) != len(topicMetadata.Partitions[0].Replicas) {
+		logger.Warningf("Topic has replication factor %d, changing it to %d requires a partition reassignment and is not done by the operator",
+			len(topicMetadata.Partitions[0].Replicas), topic.ReplicationFactor)
+	}
+
+	if len(topic.Configs) == 0 {
+		return nil
+	}
+
+	currentConfigs, err := a.kafkaAdminClient.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic.Name})
+	if err != nil {
+		return fmt.Errorf("failed describing configs of topic %s: %w", topic.Name, err)
+	}
+	current := lo.SliceToMap(currentConfigs, func(entry sarama.ConfigEntry) (string, string) {
+		return entry.Name, entry.Value
+	})
+
+	changed := lo.PickBy(topic.Configs, func(name string, value string) bool {
+		currentValue, ok := current[name]
+		return !ok || currentValue != value
+	})
+	if len(changed) == 0 {
+		return nil
+	}
+
+	logger.Infof("Setting %d topic configs", len(changed))
+	entries := lo.MapValues(changed, func(value string, _ string) sarama.IncrementalAlterConfigsEntry {
+		return sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: lo.ToPtr(value)}
+	})
+	if err := a.kafkaAdminClient.IncrementalAlterConfig(sarama.TopicResource, topic.Name, entries, false); err != nil {
+		return fmt.Errorf("failed altering configs of topic %s: %w", topic.Name, err)
+	}
+	a.auditTopic(ctx, auditlog.ActionUpdated, topic.Name, lo.PickByKeys(current, lo.Keys(changed)), changed)
+	return nil
+}
+
+// DeleteManagedTopics deletes topics the user opted in to deleting. Topics that don't exist are ignored.
+func (a *KafkaIntentsAdminImpl) DeleteManagedTopics(ctx context.Context, topicNames []string) error {
+	for _, name := range topicNames {
+		a.topicLogger(name).Info("Deleting topic")
+		err := a.kafkaAdminClient.DeleteTopic(name)
+		if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed deleting topic %s: %w", name, err)
+		}
+		a.auditTopic(ctx, auditlog.ActionDeleted, name, name, nil)
+	}
+	return nil
+}
+
+func (a *KafkaIntentsAdminImpl) topicLogger(topicName string) *logrus.Entry {
+	return logrus.WithFields(logrus.Fields{
+		"topic":           topicName,
+		"serverName":      a.kafkaServer.Spec.Service,
+		"serverNamespace": a.kafkaServer.Namespace,
+	})
+}
+
+func (a *KafkaIntentsAdminImpl) auditTopic(ctx context.Context, action auditlog.Action, topicName string, before any, after any) {
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            action,
+		ResourceKind:      auditlog.ResourceKindKafkaTopic,
+		ResourceName:      fmt.Sprintf("%s:%s", a.kafkaServer.Spec.Service.Name, topicName),
+		ResourceNamespace: a.kafkaServer.Namespace,
+		Trigger:           a.serverTrigger(),
+		Diff:              auditlog.Diff(before, after),
+	})
+}
+
+func configEntries(configs map[string]string) map[string]*string {
+	if len(configs) == 0 {
+		return nil
+	}
+	return lo.MapValues(configs, func(value string, _ string) *string { return lo.ToPtr(value) })
+}
@@ -0,0 +1,108 @@
+package kafkaacls
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	kafkaaclsmocks "github.com/otterize/intents-operator/src/operator/controllers/kafkaacls/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+type ManagedTopicsTestSuite struct {
+	suite.Suite
+	mockClusterAdmin *kafkaaclsmocks.MockClusterAdmin
+	intentsAdmin     KafkaIntentsAdmin
+}
+
+func (s *ManagedTopicsTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.mockClusterAdmin = kafkaaclsmocks.NewMockClusterAdmin(controller)
+	kafkaServerConfig := otterizev1alpha3.KafkaServerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: kafkaServerConfigResourceName, Namespace: testNamespace},
+		Spec:       otterizev1alpha3.KafkaServerConfigSpec{Service: otterizev1alpha3.Service{Name: serverName}, Addr: serverAddress},
+	}
+	s.intentsAdmin = NewKafkaIntentsAdminImpl(kafkaServerConfig, s.mockClusterAdmin, driftUsernameMapping, true, true, NewStatusTracker())
+}
+
+func topicMetadata(name string, partitions int, replicas int) *sarama.TopicMetadata {
+	return &sarama.TopicMetadata{
+		Name: name,
+		Partitions: lo.Times(partitions, func(i int) *sarama.PartitionMetadata {
+			return &sarama.PartitionMetadata{ID: int32(i), Replicas: lo.Times(replicas, func(j int) int32 { return int32(j) })}
+		}),
+	}
+}
+
+func (s *ManagedTopicsTestSuite) TestCreatesMissingTopic() {
+	topics := []otterizev1alpha3.ManagedKafkaTopic{
+		{Name: "orders", Partitions: 6, ReplicationFactor: 3, Configs: map[string]string{"retention.ms": "86400000"}},
+		{Name: "events"},
+	}
+	s.mockClusterAdmin.EXPECT().DescribeTopics([]string{"orders", "events"}).Return([]*sarama.TopicMetadata{
+		{Name: "orders", Err: sarama.ErrUnknownTopicOrPartition},
+	}, nil)
+	s.mockClusterAdmin.EXPECT().CreateTopic("orders", &sarama.TopicDetail{
+		NumPartitions:     6,
+		ReplicationFactor: 3,
+		ConfigEntries:     map[string]*string{"retention.ms": lo.ToPtr("86400000")},
+	}, false).Return(nil)
+	s.mockClusterAdmin.EXPECT().CreateTopic("events", &sarama.TopicDetail{
+		NumPartitions:     brokerDefault,
+		ReplicationFactor: brokerDefault,
+	}, false).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.ApplyManagedTopics(context.Background(), topics))
+}
+
+func (s *ManagedTopicsTestSuite) TestUpdatesExistingTopic() {
+	topics := []otterizev1alpha3.ManagedKafkaTopic{
+		{Name: "orders", Partitions: 6, Configs: map[string]string{"retention.ms": "86400000", "cleanup.policy": "delete"}},
+	}
+	s.mockClusterAdmin.EXPECT().DescribeTopics([]string{"orders"}).Return([]*sarama.TopicMetadata{topicMetadata("orders", 3, 1)}, nil)
+	s.mockClusterAdmin.EXPECT().CreatePartitions("orders", int32(6), nil, false).Return(nil)
+	s.mockClusterAdmin.EXPECT().DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: "orders"}).Return([]sarama.ConfigEntry{
+		{Name: "retention.ms", Value: "604800000"},
+		{Name: "cleanup.policy", Value: "delete"},
+	}, nil)
+	s.mockClusterAdmin.EXPECT().IncrementalAlterConfig(sarama.TopicResource, "orders", map[string]sarama.IncrementalAlterConfigsEntry{
+		"retention.ms": {Operation: sarama.IncrementalAlterConfigsOperationSet, Value: lo.ToPtr("86400000")},
+	}, false).Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.ApplyManagedTopics(context.Background(), topics))
+}
+
+func (s *ManagedTopicsTestSuite) TestUpToDateTopicIsLeftAlone() {
+	topics := []otterizev1alpha3.ManagedKafkaTopic{{Name: "orders", Partitions: 3, Configs: map[string]string{"cleanup.policy": "compact"}}}
+	s.mockClusterAdmin.EXPECT().DescribeTopics([]string{"orders"}).Return([]*sarama.TopicMetadata{topicMetadata("orders", 3, 1)}, nil)
+	s.mockClusterAdmin.EXPECT().DescribeConfig(gomock.Any()).Return([]sarama.ConfigEntry{{Name: "cleanup.policy", Value: "compact"}}, nil)
+
+	s.Require().NoError(s.intentsAdmin.ApplyManagedTopics(context.Background(), topics))
+}
+
+func (s *ManagedTopicsTestSuite) TestPartitionsAreNotRemoved() {
+	topics := []otterizev1alpha3.ManagedKafkaTopic{{Name: "orders", Partitions: 1}, {Name: "events"}}
+	s.mockClusterAdmin.EXPECT().DescribeTopics([]string{"orders", "events"}).Return([]*sarama.TopicMetadata{
+		topicMetadata("orders", 3, 1),
+		{Name: "events", Err: sarama.ErrUnknownTopicOrPartition},
+	}, nil)
+	// A failing topic doesn't prevent the others from being applied.
+	s.mockClusterAdmin.EXPECT().CreateTopic("events", gomock.Any(), false).Return(nil)
+
+	err := s.intentsAdmin.ApplyManagedTopics(context.Background(), topics)
+	s.Require().ErrorContains(err, "partitions cannot be removed")
+}
+
+func (s *ManagedTopicsTestSuite) TestDeleteIgnoresMissingTopics() {
+	s.mockClusterAdmin.EXPECT().DeleteTopic("orders").Return(sarama.ErrUnknownTopicOrPartition)
+	s.mockClusterAdmin.EXPECT().DeleteTopic("events").Return(nil)
+
+	s.Require().NoError(s.intentsAdmin.DeleteManagedTopics(context.Background(), []string{"orders", "events"}))
+}
+
+func TestManagedTopicsTestSuite(t *testing.T) {
+	suite.Run(t, new(ManagedTopicsTestSuite))
+}
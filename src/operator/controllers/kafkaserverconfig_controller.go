@@ -23,18 +23,25 @@ import (
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/otterize/intents-operator/src/shared/operator_cloud_client"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig"
 	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -46,17 +53,21 @@ const (
 type KafkaServerConfigReconciler struct {
 	client.Client
 	injectablerecorder.InjectableRecorder
-	group *reconcilergroup.Group
+	group            *reconcilergroup.Group
+	statusReconciler *kafka_server_config_reconcilers.KafkaServerStatusReconciler
+	statusTracker    *kafkaacls.StatusTracker
 }
 
 func NewKafkaServerConfigReconciler(
 	client client.Client,
 	scheme *runtime.Scheme,
 	serversStore kafkaacls.ServersStore,
+	statusTracker *kafkaacls.StatusTracker,
 	operatorPodName string,
 	operatorPodNameSpace string,
 	cloudClient operator_cloud_client.CloudClient,
 	serviceResolver serviceidresolver.ServiceResolver,
+	enforcementDefaultState bool,
 ) *KafkaServerConfigReconciler {
 	kscReconciler := kafka_server_config_reconcilers.NewKafkaServerConfigReconciler(
 		client,
@@ -78,14 +89,24 @@ func NewKafkaServerConfigReconciler(
 		kscReconciler,
 	)
 
+	if resyncInterval := viper.GetDuration(operatorconfig.KafkaACLResyncIntervalKey); resyncInterval > 0 {
+		driftReconciler := kafka_server_config_reconcilers.NewKafkaACLDriftReconciler(client, serversStore, enforcementDefaultState, resyncInterval)
+		group.AddToGroup(driftReconciler)
+	}
+
+	statusReconciler := kafka_server_config_reconcilers.NewKafkaServerStatusReconciler(client, serversStore)
+	group.AddToGroup(statusReconciler)
+
 	if telemetrysender.IsTelemetryEnabled() {
 		telemetryReconciler := kafka_server_config_reconcilers.NewTelemetryReconciler(client)
 		group.AddToGroup(telemetryReconciler)
 	}
 
 	return &KafkaServerConfigReconciler{
-		Client: client,
-		group:  group,
+		Client:           client,
+		group:            group,
+		statusReconciler: statusReconciler,
+		statusTracker:    statusTracker,
 	}
 }
 
@@ -97,19 +118,46 @@ func (r *KafkaServerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 func (r *KafkaServerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	err := ctrl.NewControllerManagedBy(mgr).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
-		For(&otterizev1alpha3.KafkaServerConfig{}).
+		For(&otterizev1alpha3.KafkaServerConfig{}, builder.WithPredicates(ignoreStatusOnlyUpdates())).
 		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
 		Watches(&otterizev1alpha3.ProtectedService{}, handler.EnqueueRequestsFromMapFunc(r.mapProtectedServiceToKafkaServerConfig)).
+		WatchesRawSource(r.group.RecoveredSource(), &handler.EnqueueRequestForObject{}).
 		Complete(r)
 	if err != nil {
 		return err
 	}
 
+	// Results of applying ClientIntents only change the status, so they are reported by a controller of their own
+	// rather than by reconciling the whole server.
+	err = ctrl.NewControllerManagedBy(mgr).
+		Named("kafkaserverstatus").
+		WithOptions(controller.Options{RecoverPanic: lo.ToPtr(true)}).
+		WatchesRawSource(&source.Channel{Source: r.statusTracker.Events()}, &handler.EnqueueRequestForObject{}).
+		Complete(r.statusReconciler)
+	if err != nil {
+		return err
+	}
+
 	r.group.InjectRecorder(mgr.GetEventRecorderFor(groupName))
+	r.statusReconciler.InjectRecorder(mgr.GetEventRecorderFor(groupName))
 
 	return nil
 }
 
+// ignoreStatusOnlyUpdates filters out updates the reconcilers make to the status, which would otherwise trigger another
+// reconcile of the server. Status changes caused by ClientIntents are reported by the kafkaserverstatus controller.
+func ignoreStatusOnlyUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				!e.ObjectOld.GetDeletionTimestamp().Equal(e.ObjectNew.GetDeletionTimestamp()) ||
+				!reflect.DeepEqual(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers()) ||
+				!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+				!reflect.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+		},
+	}
+}
+
 func (r *KafkaServerConfigReconciler) InitKafkaServerConfigIndices(mgr ctrl.Manager) error {
 	return mgr.GetCache().IndexField(
 		context.Background(),
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/operator/controllers/defaultintents"
 	"github.com/otterize/intents-operator/src/operator/controllers/istiopolicy"
 	"github.com/otterize/intents-operator/src/prometheus"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
 	"github.com/otterize/intents-operator/src/shared/serviceidresolver/serviceidentity"
 	"github.com/samber/lo"
@@ -15,12 +17,15 @@ import (
 	"github.com/spf13/viper"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
@@ -34,16 +39,18 @@ type PodWatcher struct {
 	client.Client
 	serviceIdResolver *serviceidresolver.Resolver
 	istioPolicyAdmin  istiopolicy.PolicyManager
+	accessLabels      accesslabels.Enum
 	injectablerecorder.InjectableRecorder
 }
 
-func NewPodWatcher(c client.Client, eventRecorder record.EventRecorder, watchedNamespaces []string, enforcementDefaultState bool, istioEnforcementEnabled bool) *PodWatcher {
+func NewPodWatcher(c client.Client, eventRecorder record.EventRecorder, watchedNamespaces []string, enforcementDefaultState bool, istioEnforcementEnabled bool, accessLabels accesslabels.Enum) *PodWatcher {
 	recorder := injectablerecorder.InjectableRecorder{Recorder: eventRecorder}
 	creator := istiopolicy.NewPolicyManager(c, &recorder, watchedNamespaces, enforcementDefaultState, istioEnforcementEnabled)
 	return &PodWatcher{
 		Client:             c,
 		serviceIdResolver:  serviceidresolver.NewResolver(c),
 		istioPolicyAdmin:   creator,
+		accessLabels:       accessLabels,
 		InjectableRecorder: recorder,
 	}
 }
@@ -170,6 +177,26 @@ func (p *PodWatcher) addOtterizePodLabels(ctx context.Context, req ctrl.Request,
 		return nil
 	}
 
+	updatedPod, hasUpdates, err := LabelPod(ctx, p.Client, serviceID, pod, p.accessLabels)
+	if err != nil {
+		return err
+	}
+
+	if hasUpdates {
+		err = p.Patch(ctx, updatedPod, client.MergeFrom(&pod))
+		if err != nil {
+			logrus.Errorf("Failed updating Otterize labels for pod %s in namespace %s", pod.Name, pod.Namespace)
+			return err
+		}
+	}
+	return nil
+}
+
+// LabelPod returns a copy of the pod with its server identity label, and the access labels of the servers its
+// intents allow it to reach, and whether any of them changed. Access labels are removed rather than set when the
+// access label scheme selects clients by their identity alone. Listed intents carry the default calls of their
+// namespace when reader is a defaultintents.Client; pods without intents get the default calls regardless.
+func LabelPod(ctx context.Context, reader client.Reader, serviceID serviceidentity.ServiceIdentity, pod v1.Pod, accessLabels accesslabels.Enum) (*v1.Pod, bool, error) {
 	otterizeServerLabelValue := otterizev1alpha3.GetFormattedOtterizeIdentity(serviceID.Name, pod.Namespace)
 	updatedPod := pod.DeepCopy()
 	hasUpdates := false
@@ -186,27 +213,61 @@ func (p *PodWatcher) addOtterizePodLabels(ctx context.Context, req ctrl.Request,
 		hasUpdates = true
 	}
 
+	// Update owner kind label - distinguishes workloads of different kinds that share a name, for intents that
+	// specify a kind.
+	if serviceID.Kind != "" && updatedPod.Labels[otterizev1alpha3.OtterizeOwnerKindLabelKey] != serviceID.Kind {
+		if updatedPod.Labels == nil {
+			updatedPod.Labels = make(map[string]string)
+		}
+		updatedPod.Labels[otterizev1alpha3.OtterizeOwnerKindLabelKey] = serviceID.Kind
+		hasUpdates = true
+	} else if _, ok := updatedPod.Labels[otterizev1alpha3.OtterizeOwnerKindLabelKey]; serviceID.Kind == "" && ok {
+		delete(updatedPod.Labels, otterizev1alpha3.OtterizeOwnerKindLabelKey)
+		hasUpdates = true
+	}
+
 	var intents otterizev1alpha3.ClientIntentsList
-	err := p.List(
+	err := reader.List(
 		ctx, &intents,
 		&client.MatchingFields{OtterizeClientNameIndexField: serviceID.Name},
 		&client.ListOptions{Namespace: pod.Namespace})
 
 	if err != nil {
 		logrus.WithFields(logrus.Fields{"ServiceName": serviceID, "Namespace": pod.Namespace}).Errorln("Failed listing intents")
-		return err
+		return nil, false, err
+	}
+
+	hasIntentsOfKind := lo.ContainsBy(intents.Items, func(intent otterizev1alpha3.ClientIntents) bool {
+		return intent.Spec.Service.Kind == "" || intent.Spec.Service.Kind == serviceID.Kind
+	})
+	if !hasIntentsOfKind {
+		// Clients without intents still make the default calls of their namespace.
+		defaultIntents, err := defaultClientIntentsOfPod(ctx, reader, serviceID, pod)
+		if err != nil {
+			return nil, false, err
+		}
+		if defaultIntents != nil {
+			intents.Items = append(intents.Items, *defaultIntents)
+		}
 	}
 
 	if len(intents.Items) != 0 {
 		// Update access labels - which servers the client can access (current intents), and remove old access labels (deleted intents)
 		otterizeAccessLabels := make(map[string]string)
 		for _, intent := range intents.Items {
+			if intent.Spec.Service.Kind != "" && intent.Spec.Service.Kind != serviceID.Kind {
+				// These intents belong to a workload of another kind with the same name.
+				continue
+			}
 			currIntentLabels := intent.GetIntentsLabelMapping(pod.Namespace)
 			for k, v := range currIntentLabels {
 				otterizeAccessLabels[k] = v
 			}
 		}
-		if otterizev1alpha3.IsMissingOtterizeAccessLabels(&pod, otterizeAccessLabels) {
+		if !accessLabels.LabelsPods() {
+			otterizeAccessLabels = map[string]string{}
+		}
+		if otterizev1alpha3.IsMissingOtterizeAccessLabels(&pod, otterizeAccessLabels) || !otterizev1alpha3.HasOtterizeClientLabel(&pod, serviceID.Name) {
 			logrus.Infof("Updating Otterize access labels for %s", serviceID.Name)
 			updatedPod = otterizev1alpha3.UpdateOtterizeAccessLabels(updatedPod.DeepCopy(), serviceID.Name, otterizeAccessLabels)
 			prometheus.IncrementPodsLabeledForNetworkPolicies(1)
@@ -214,14 +275,27 @@ func (p *PodWatcher) addOtterizePodLabels(ctx context.Context, req ctrl.Request,
 		}
 	}
 
-	if hasUpdates {
-		err = p.Patch(ctx, updatedPod, client.MergeFrom(&pod))
-		if err != nil {
-			logrus.Errorf("Failed updating Otterize labels for pod %s in namespace %s", pod.Name, pod.Namespace)
-			return err
-		}
+	return updatedPod, hasUpdates, nil
+}
+
+// defaultClientIntentsOfPod returns client intents with the default calls of the pod's namespace, or nil if the
+// namespace has none.
+func defaultClientIntentsOfPod(ctx context.Context, reader client.Reader, serviceID serviceidentity.ServiceIdentity, pod v1.Pod) (*otterizev1alpha3.ClientIntents, error) {
+	defaultCalls, err := defaultintents.CallsOfNamespace(ctx, reader, pod.Namespace)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ServiceName": serviceID, "Namespace": pod.Namespace}).Errorln("Failed listing default intents")
+		return nil, err
 	}
-	return nil
+	if len(defaultCalls) == 0 {
+		return nil, nil
+	}
+
+	clientIntents := &otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace},
+		Spec:       &otterizev1alpha3.IntentsSpec{Service: otterizev1alpha3.Service{Name: serviceID.Name, Kind: serviceID.Kind}},
+	}
+	clientIntents.SetDefaultCalls(defaultCalls)
+	return clientIntents, nil
 }
 
 func (p *PodWatcher) istioEnforcementEnabled() bool {
@@ -287,5 +361,24 @@ func (p *PodWatcher) Register(mgr manager.Manager) error {
 		return fmt.Errorf("unable to watch Pods: %p", err)
 	}
 
+	if err = watcher.Watch(source.Kind(mgr.GetCache(), &otterizev1alpha3.DefaultClientIntents{}), handler.EnqueueRequestsFromMapFunc(p.mapDefaultClientIntentsToPods)); err != nil {
+		return fmt.Errorf("unable to watch DefaultClientIntents: %p", err)
+	}
+
 	return nil
 }
+
+// mapDefaultClientIntentsToPods enqueues the pods of the namespace of the default intents, since pods of clients
+// without intents are labeled with the default calls of their namespace.
+func (p *PodWatcher) mapDefaultClientIntentsToPods(ctx context.Context, obj client.Object) []reconcile.Request {
+	var pods v1.PodList
+	err := p.List(ctx, &pods, &client.ListOptions{Namespace: obj.GetNamespace()})
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed listing pods for default intents %s/%s", obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	return lo.Map(pods.Items, func(pod v1.Pod, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}}
+	})
+}
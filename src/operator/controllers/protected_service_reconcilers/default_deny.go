@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/injectablerecorder"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/networking/v1"
@@ -86,7 +87,7 @@ func (r *DefaultDenyReconciler) blockAccessToServices(ctx context.Context, prote
 		_, found := serversToProtect[existingPolicyServerName]
 		if found {
 			desiredPolicy := serversToProtect[existingPolicyServerName]
-			err = r.updateIfNeeded(existingPolicy, desiredPolicy)
+			err = r.updateIfNeeded(ctx, existingPolicy, desiredPolicy)
 			if err != nil {
 				return err
 			}
@@ -96,6 +97,7 @@ func (r *DefaultDenyReconciler) blockAccessToServices(ctx context.Context, prote
 			if err != nil {
 				return err
 			}
+			auditlog.ObjectDeleted(ctx, auditlog.ResourceKindDefaultDenyPolicy, &existingPolicy)
 			logrus.Infof("Deleted network policy %s", existingPolicy.Name)
 		}
 	}
@@ -105,6 +107,7 @@ func (r *DefaultDenyReconciler) blockAccessToServices(ctx context.Context, prote
 		if err != nil {
 			return err
 		}
+		auditlog.ObjectCreated(ctx, auditlog.ResourceKindDefaultDenyPolicy, &networkPolicy)
 		logrus.Infof("Created network policy %s", networkPolicy.Name)
 	}
 
@@ -112,6 +115,7 @@ func (r *DefaultDenyReconciler) blockAccessToServices(ctx context.Context, prote
 }
 
 func (r *DefaultDenyReconciler) updateIfNeeded(
+	ctx context.Context,
 	existingPolicy v1.NetworkPolicy,
 	newPolicy v1.NetworkPolicy,
 ) error {
@@ -119,13 +123,15 @@ func (r *DefaultDenyReconciler) updateIfNeeded(
 		return nil
 	}
 
+	policyBeforeUpdate := existingPolicy.DeepCopy()
 	existingPolicy.Spec = newPolicy.Spec
 	existingPolicy.Labels = newPolicy.Labels
 
-	err := r.Update(context.Background(), &existingPolicy)
+	err := r.Update(ctx, &existingPolicy)
 	if err != nil {
 		return err
 	}
+	auditlog.ObjectUpdated(ctx, auditlog.ResourceKindDefaultDenyPolicy, policyBeforeUpdate, &existingPolicy)
 
 	logrus.Infof("Updated network policy %s", existingPolicy.Name)
 	return nil
@@ -172,6 +178,7 @@ func (r *DefaultDenyReconciler) DeleteAllDefaultDeny(ctx context.Context, namesp
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		auditlog.ObjectDeleted(ctx, auditlog.ResourceKindDefaultDenyPolicy, &existingPolicy)
 		logrus.Infof("Deleted network policy %s", existingPolicy.Name)
 	}
 
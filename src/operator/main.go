@@ -32,8 +32,11 @@ import (
 	"github.com/otterize/intents-operator/src/operator/controllers/pod_reconcilers"
 	"github.com/otterize/intents-operator/src/operator/otterizecrds"
 	"github.com/otterize/intents-operator/src/operator/webhooks"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/otterize/intents-operator/src/shared/awsagent"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
 	"github.com/otterize/intents-operator/src/shared/operator_cloud_client"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
 	"github.com/otterize/intents-operator/src/shared/reconcilergroup"
 	"github.com/sirupsen/logrus"
@@ -41,13 +44,16 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/metadata"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"time"
 
 	otterizev1alpha2 "github.com/otterize/intents-operator/src/operator/api/v1alpha2"
 	"github.com/otterize/intents-operator/src/operator/controllers"
+	"github.com/otterize/intents-operator/src/operator/controllers/defaultintents"
 	"github.com/otterize/intents-operator/src/operator/controllers/external_traffic"
 	"github.com/otterize/intents-operator/src/operator/controllers/kafkaacls"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig"
@@ -66,8 +72,11 @@ import (
 	istiosecurityscheme "istio.io/client-go/pkg/apis/security/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -79,6 +88,8 @@ func init() {
 	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(istiosecurityscheme.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1beta1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1alpha2.AddToScheme(scheme))
 	utilruntime.Must(otterizev1alpha2.AddToScheme(scheme))
 	utilruntime.Must(otterizev1alpha3.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -100,6 +111,7 @@ func main() {
 	probeAddr := viper.GetString(operatorconfig.ProbeAddrKey)
 	enableLeaderElection := viper.GetBool(operatorconfig.EnableLeaderElectionKey)
 	selfSignedCert := viper.GetBool(operatorconfig.SelfSignedCertKey)
+	certManagerSecret := viper.GetString(operatorconfig.WebhookCertManagerSecretKey)
 	allowExternalTraffic := allowexternaltraffic.Enum(viper.GetString(operatorconfig.AllowExternalTrafficKey))
 	watchedNamespaces := viper.GetStringSlice(operatorconfig.WatchedNamespacesKey)
 	enforcementConfig := controllers.EnforcementConfig{
@@ -110,6 +122,7 @@ func main() {
 		EnableDatabaseReconciler:             viper.GetBool(operatorconfig.EnableDatabaseReconciler),
 		EnableEgressNetworkPolicyReconcilers: viper.GetBool(operatorconfig.EnableEgressNetworkPolicyReconcilersKey),
 		EnableAWSPolicy:                      viper.GetBool(operatorconfig.EnableAWSPolicyKey),
+		AccessLabels:                         accesslabels.Enum(viper.GetString(operatorconfig.AccessLabelsKey)),
 	}
 	disableWebhookServer := viper.GetBool(operatorconfig.DisableWebhookServerKey)
 	tlsSource := otterizev1alpha3.TLSSource{
@@ -130,6 +143,12 @@ func main() {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 
+	circuitbreaker.SetGlobalRegistry(circuitbreaker.NewRegistry(
+		viper.GetInt(operatorconfig.CircuitBreakerFailureThresholdKey),
+		viper.GetDuration(operatorconfig.CircuitBreakerInitialBackoffKey),
+		viper.GetDuration(operatorconfig.CircuitBreakerMaxBackoffKey),
+	))
+
 	metricsServer := echo.New()
 	metricsServer.GET("/metrics", echoprometheus.NewHandler())
 
@@ -162,6 +181,7 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal(err, "unable to start manager")
 	}
+	initAuditLog(mgr)
 	signalHandlerCtx := ctrl.SetupSignalHandler()
 
 	metadataClient, err := metadata.NewForConfig(ctrl.GetConfigOrDie())
@@ -188,9 +208,31 @@ func main() {
 		logrus.WithError(err).Fatal("unable to create kubernetes API client")
 	}
 
-	kafkaServersStore := kafkaacls.NewServersStore(tlsSource, enforcementConfig.EnableKafkaACL, kafkaacls.NewKafkaIntentsAdmin, enforcementConfig.EnforcementDefaultState)
+	kafkaStatusTracker := kafkaacls.NewStatusTracker()
+	kafkaAdminPool := kafkaacls.NewAdminPool(directClient, kafkaStatusTracker)
+	kafkaServersStore := kafkaacls.NewServersStore(tlsSource, enforcementConfig.EnableKafkaACL, kafkaAdminPool.NewKafkaIntentsAdmin, enforcementConfig.EnforcementDefaultState, kafkaAdminPool)
+	if err := mgr.Add(kafkaAdminPool); err != nil {
+		logrus.WithError(err).Fatal("unable to register Kafka credentials rotation watcher")
+	}
 
-	extNetpolHandler := external_traffic.NewNetworkPolicyHandler(mgr.GetClient(), mgr.GetScheme(), allowExternalTraffic)
+	ingressControllerSelector, err := external_traffic.NewIngressControllerSelector(
+		viper.GetString(operatorconfig.ExternalTrafficIngressNamespaceSelectorKey),
+		viper.GetString(operatorconfig.ExternalTrafficIngressPodSelectorKey),
+	)
+	if err != nil {
+		logrus.WithError(err).Fatal("invalid external traffic configuration")
+	}
+	gatewayRouteKinds, err := external_traffic.DetectGatewayRouteKinds(mgr.GetRESTMapper())
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to detect Gateway API route kinds")
+	}
+	// Reconcilers that read ClientIntents use intentsClient, so that the intents carry the default calls of their namespace.
+	intentsClient := defaultintents.NewClient(mgr.GetClient())
+	gatewayDataPlaneSelector := external_traffic.GatewayDataPlaneSelector{
+		Namespace:    viper.GetString(operatorconfig.ExternalTrafficGatewayNamespaceKey),
+		NameLabelKey: viper.GetString(operatorconfig.ExternalTrafficGatewayNameLabelKey),
+	}
+	extNetpolHandler := external_traffic.NewNetworkPolicyHandler(mgr.GetClient(), mgr.GetScheme(), allowExternalTraffic, ingressControllerSelector, gatewayRouteKinds, gatewayDataPlaneSelector)
 	endpointReconciler := external_traffic.NewEndpointsReconciler(mgr.GetClient(), extNetpolHandler)
 	externalPolicySvcReconciler := external_traffic.NewServiceReconciler(mgr.GetClient(), extNetpolHandler)
 	networkPolicyHandler := ingress_network_policy.NewNetworkPolicyReconciler(
@@ -201,6 +243,7 @@ func main() {
 		enforcementConfig.EnableNetworkPolicy,
 		enforcementConfig.EnforcementDefaultState,
 		allowExternalTraffic,
+		enforcementConfig.AccessLabels,
 	)
 	egressNetworkPolicyHandler := egress_network_policy.NewEgressNetworkPolicyReconciler(mgr.GetClient(), scheme, watchedNamespaces, enforcementConfig.EnableNetworkPolicy, enforcementConfig.EnforcementDefaultState)
 	additionalIntentsReconcilers := make([]reconcilergroup.ReconcilerWithEvents, 0)
@@ -217,7 +260,7 @@ func main() {
 			logrus.WithError(err).Fatal("unable to register pod watcher")
 		}
 	}
-	svcNetworkPolicyHandler := port_network_policy.NewPortNetworkPolicyReconciler(mgr.GetClient(), scheme, extNetpolHandler, watchedNamespaces, enforcementConfig.EnableNetworkPolicy, enforcementConfig.EnforcementDefaultState)
+	svcNetworkPolicyHandler := port_network_policy.NewPortNetworkPolicyReconciler(mgr.GetClient(), scheme, extNetpolHandler, watchedNamespaces, enforcementConfig.EnableNetworkPolicy, enforcementConfig.EnforcementDefaultState, enforcementConfig.AccessLabels)
 	svcEgressNetworkPolicyHandler := port_egress_network_policy.NewPortEgressNetworkPolicyReconciler(mgr.GetClient(), scheme, watchedNamespaces, enforcementConfig.EnableNetworkPolicy, enforcementConfig.EnforcementDefaultState)
 
 	if err = endpointReconciler.InitIngressReferencedServicesIndex(mgr); err != nil {
@@ -225,13 +268,33 @@ func main() {
 	}
 
 	ingressReconciler := external_traffic.NewIngressReconciler(mgr.GetClient(), extNetpolHandler)
+	gatewayRouteReconcilers := make([]*external_traffic.GatewayRouteReconciler, 0)
+	for _, kind := range gatewayRouteKinds {
+		gatewayRouteReconciler := external_traffic.NewGatewayRouteReconciler(mgr.GetClient(), extNetpolHandler, kind)
+		if err = gatewayRouteReconciler.InitGatewayRouteServicesIndex(mgr); err != nil {
+			logrus.WithError(err).Fatal("unable to init index for gateway routes")
+		}
+		gatewayRouteReconcilers = append(gatewayRouteReconcilers, gatewayRouteReconciler)
+	}
 
 	otterizeCloudClient, connectedToCloud, err := operator_cloud_client.NewClient(signalHandlerCtx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to initialize Otterize Cloud client")
 	}
-	if connectedToCloud {
+	connectedToCloud = connectedToCloud && otterizeCloudClient != nil
+	if connectedToCloud && viper.GetBool(otterizecloudclient.CloudReportOutboxEnabledKey) {
+		otterizeCloudClient = startCloudReportOutbox(signalHandlerCtx, otterizeCloudClient, directClient, podNamespace)
+	}
+	otterizeCloudClient, err = operator_cloud_client.NewClientWithReportingSinks(otterizeCloudClient, directClient, podNamespace)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to initialize reporting sinks")
+	}
+	if otterizeCloudClient != nil {
 		uploadConfiguration(signalHandlerCtx, otterizeCloudClient, enforcementConfig)
+	}
+	// Connection status and network policy uploads are only meaningful to Otterize Cloud, so they are not started when
+	// reporting only to sinks.
+	if connectedToCloud {
 		operator_cloud_client.StartPeriodicallyReportConnectionToCloud(otterizeCloudClient, signalHandlerCtx)
 
 		netpolUploader := external_traffic.NewNetworkPolicyUploaderReconciler(mgr.GetClient(), mgr.GetScheme(), otterizeCloudClient)
@@ -246,35 +309,51 @@ func main() {
 		logrus.Infof("Running with enforcement disabled globally, won't perform any enforcement")
 	}
 
-	if selfSignedCert {
-		logrus.Infoln("Creating self signing certs")
-		certBundle, err :=
-			webhooks.GenerateSelfSignedCertificate("intents-operator-webhook-service", podNamespace)
-		if err != nil {
-			logrus.WithError(err).Fatal("unable to create self signed certs for webhook")
-		}
-		err = webhooks.WriteCertToFiles(certBundle)
-		if err != nil {
-			logrus.WithError(err).Fatal("failed writing certs to file system")
-		}
-
+	if certManagerSecret != "" || selfSignedCert {
+		// The conversion webhooks of the CRDs must point at the operator namespace whichever way certs are issued.
 		err = otterizecrds.Ensure(signalHandlerCtx, directClient, podNamespace)
 		if err != nil {
 			logrus.WithError(err).Fatal("unable to ensure otterize CRDs")
 		}
+	}
 
-		err = webhooks.UpdateValidationWebHookCA(signalHandlerCtx,
-			"otterize-validating-webhook-configuration", certBundle.CertPem)
+	if certManagerSecret != "" {
+		// Takes precedence over self-signed certs, which are enabled by default. The CRDs, like the webhook
+		// configurations, must carry the cert-manager.io/inject-ca-from annotation for the cert-manager CA injector to
+		// fill in their caBundle.
+		clientset, err := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
 		if err != nil {
-			logrus.WithError(err).Fatal("updating validation webhook certificate failed")
+			logrus.WithError(err).Fatal("unable to create kubernetes clientset")
 		}
-		err = webhooks.UpdateConversionWebhookCAs(signalHandlerCtx, directClient, certBundle.CertPem)
-		if err != nil {
-			logrus.WithError(err).Fatal("updating conversion webhook certificate failed")
+		certSource := webhooks.NewCertManagerCertSource(clientset, podNamespace, certManagerSecret)
+		if err = certSource.Start(signalHandlerCtx); err != nil {
+			logrus.WithError(err).Fatal("unable to load webhook certificate issued by cert-manager")
+		}
+	} else if selfSignedCert {
+		logrus.Infoln("Creating self signing certs")
+		certRotator := webhooks.NewCertRotator(
+			directClient,
+			"intents-operator-webhook-service",
+			podNamespace,
+			viper.GetString(operatorconfig.WebhookCASecretNameKey),
+			viper.GetDuration(operatorconfig.WebhookCAValidityKey),
+			viper.GetDuration(operatorconfig.WebhookCertValidityKey),
+		)
+		if err = certRotator.Rotate(signalHandlerCtx); err != nil {
+			logrus.WithError(err).Fatal("unable to create certs for webhook")
+		}
+		if err = mgr.Add(certRotator); err != nil {
+			logrus.WithError(err).Fatal("unable to register webhook cert rotator")
 		}
 	}
 
 	if !disableWebhookServer {
+		if viper.GetBool(operatorconfig.EnablePodLabelsWebhookKey) {
+			if err = webhooks.NewPodLabelsMutator(intentsClient, enforcementConfig.AccessLabels, watchedNamespaces, podNamespace).SetupWebhookWithManager(mgr); err != nil {
+				logrus.WithError(err).Fatal("unable to create webhook", "webhook", "Pod")
+			}
+		}
+
 		intentsValidator := webhooks.NewIntentsValidatorV1alpha2(mgr.GetClient())
 		if err = (&otterizev1alpha2.ClientIntents{}).SetupWebhookWithManager(mgr, intentsValidator); err != nil {
 			logrus.WithError(err).Fatal(err, "unable to create webhook for v1alpha2", "webhook", "ClientIntents")
@@ -305,9 +384,10 @@ func main() {
 	}
 
 	intentsReconciler := controllers.NewIntentsReconciler(
-		mgr.GetClient(),
+		intentsClient,
 		mgr.GetScheme(),
 		kafkaServersStore,
+		kafkaAdminPool.NewKafkaIntentsAdmin,
 		networkPolicyHandler,
 		svcNetworkPolicyHandler,
 		egressNetworkPolicyHandler,
@@ -323,6 +403,9 @@ func main() {
 	if err = ingressReconciler.InitNetworkPoliciesByIngressNameIndex(mgr); err != nil {
 		logrus.WithError(err).Fatal("unable to init index for ingress")
 	}
+	if err = external_traffic.InitNetworkPoliciesByGatewayRouteIndex(mgr); err != nil {
+		logrus.WithError(err).Fatal("unable to init index for gateway routes")
+	}
 	if err = intentsReconciler.InitIntentsServerIndices(mgr); err != nil {
 		logrus.WithError(err).Fatal("unable to init indices")
 	}
@@ -350,14 +433,22 @@ func main() {
 		logrus.WithError(err).Fatal("unable to create controller", "controller", "Ingress")
 	}
 
+	for _, gatewayRouteReconciler := range gatewayRouteReconcilers {
+		if err = gatewayRouteReconciler.SetupWithManager(mgr); err != nil {
+			logrus.WithError(err).Fatal("unable to create controller", "controller", "GatewayRoute")
+		}
+	}
+
 	kafkaServerConfigReconciler := controllers.NewKafkaServerConfigReconciler(
-		mgr.GetClient(),
+		intentsClient,
 		mgr.GetScheme(),
 		kafkaServersStore,
+		kafkaStatusTracker,
 		podName,
 		podNamespace,
 		otterizeCloudClient,
 		serviceidresolver.NewResolver(mgr.GetClient()),
+		enforcementConfig.EnforcementDefaultState,
 	)
 
 	if err = kafkaServerConfigReconciler.SetupWithManager(mgr); err != nil {
@@ -383,7 +474,26 @@ func main() {
 		logrus.WithError(err).Fatal("unable to create controller", "controller", "ProtectedServices")
 	}
 
-	podWatcher := pod_reconcilers.NewPodWatcher(mgr.GetClient(), mgr.GetEventRecorderFor("intents-operator"), watchedNamespaces, enforcementConfig.EnforcementDefaultState, enforcementConfig.EnableIstioPolicy)
+	clusterClientIntentsReconciler := controllers.NewClusterClientIntentsReconciler(mgr.GetClient(), mgr.GetScheme())
+	err = clusterClientIntentsReconciler.SetupWithManager(mgr)
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to create controller", "controller", "ClusterClientIntents")
+	}
+
+	defaultClientIntentsReconciler := controllers.NewDefaultClientIntentsReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		extNetpolHandler,
+		watchedNamespaces,
+		enforcementConfig.EnableNetworkPolicy,
+		enforcementConfig.EnforcementDefaultState,
+	)
+	err = defaultClientIntentsReconciler.SetupWithManager(mgr)
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to create controller", "controller", "DefaultClientIntents")
+	}
+
+	podWatcher := pod_reconcilers.NewPodWatcher(intentsClient, mgr.GetEventRecorderFor("intents-operator"), watchedNamespaces, enforcementConfig.EnforcementDefaultState, enforcementConfig.EnableIstioPolicy, enforcementConfig.AccessLabels)
 	nsWatcher := pod_reconcilers.NewNamespaceWatcher(mgr.GetClient())
 	svcReconcilers := []reconcile.Reconciler{svcNetworkPolicyHandler}
 	if enforcementConfig.EnableEgressNetworkPolicyReconcilers {
@@ -401,6 +511,11 @@ func main() {
 		logrus.WithError(err).Panic()
 	}
 
+	err = serviceidresolver.InitOwnerCache(signalHandlerCtx, mgr)
+	if err != nil {
+		logrus.WithError(err).Panic()
+	}
+
 	err = podWatcher.Register(mgr)
 	if err != nil {
 		logrus.WithError(err).Panic()
@@ -428,6 +543,43 @@ func main() {
 	}
 }
 
+func initAuditLog(mgr manager.Manager) {
+	sinks := make([]auditlog.Sink, 0)
+	if viper.GetBool(operatorconfig.AuditLogStdoutKey) {
+		sinks = append(sinks, auditlog.NewStdoutSink())
+	}
+	if path := viper.GetString(operatorconfig.AuditLogFileKey); path != "" {
+		fileSink, err := auditlog.NewFileSink(path)
+		if err != nil {
+			logrus.WithError(err).Fatal("unable to initialize audit log file")
+		}
+		sinks = append(sinks, fileSink)
+	}
+	if url := viper.GetString(operatorconfig.AuditLogWebhookURLKey); url != "" {
+		webhookSink := auditlog.NewWebhookSink(url, viper.GetDuration(operatorconfig.AuditLogWebhookTimeoutKey), viper.GetInt(operatorconfig.AuditLogWebhookQueueSizeKey))
+		if err := mgr.Add(webhookSink); err != nil {
+			logrus.WithError(err).Fatal("unable to register audit log webhook")
+		}
+		sinks = append(sinks, webhookSink)
+	}
+	if len(sinks) != 0 {
+		logrus.Infof("Writing enforcement audit records to %d sinks", len(sinks))
+	}
+	auditlog.SetGlobalSinks(sinks...)
+}
+
+func startCloudReportOutbox(ctx context.Context, cloudClient operator_cloud_client.CloudClient, k8sClient client.Client, podNamespace string) operator_cloud_client.CloudClient {
+	var outbox *operator_cloud_client.OutboxCloudClient
+	if viper.GetBool(otterizecloudclient.CloudReportOutboxPersistKey) {
+		configMapKey := types.NamespacedName{Name: viper.GetString(otterizecloudclient.CloudReportOutboxConfigMapKey), Namespace: podNamespace}
+		outbox = operator_cloud_client.NewOutboxCloudClient(cloudClient, k8sClient, &configMapKey)
+	} else {
+		outbox = operator_cloud_client.NewOutboxCloudClient(cloudClient, nil, nil)
+	}
+	outbox.Start(ctx)
+	return outbox
+}
+
 func uploadConfiguration(ctx context.Context, otterizeCloudClient operator_cloud_client.CloudClient, config controllers.EnforcementConfig) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, viper.GetDuration(otterizecloudclient.CloudClientTimeoutKey))
 	defer cancel()
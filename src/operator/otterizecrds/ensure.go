@@ -14,6 +14,12 @@ import (
 //go:embed clientintents-customresourcedefinition.yaml
 var clientIntentsCRDContents []byte
 
+//go:embed clusterclientintents-customresourcedefinition.yaml
+var clusterClientIntentsCRDContents []byte
+
+//go:embed defaultclientintents-customresourcedefinition.yaml
+var defaultClientIntentsCRDContents []byte
+
 //go:embed protectedservices-customresourcedefinition.yaml
 var protectedServiceCRDContents []byte
 
@@ -25,6 +31,14 @@ func Ensure(ctx context.Context, k8sClient client.Client, operatorNamespace stri
 	if err != nil {
 		return fmt.Errorf("failed to ensure CLientIntents CRD: %w", err)
 	}
+	err = ensureCRD(ctx, k8sClient, operatorNamespace, clusterClientIntentsCRDContents)
+	if err != nil {
+		return fmt.Errorf("failed to ensure ClusterClientIntents CRD: %w", err)
+	}
+	err = ensureCRD(ctx, k8sClient, operatorNamespace, defaultClientIntentsCRDContents)
+	if err != nil {
+		return fmt.Errorf("failed to ensure DefaultClientIntents CRD: %w", err)
+	}
 	err = ensureCRD(ctx, k8sClient, operatorNamespace, protectedServiceCRDContents)
 	if err != nil {
 		return fmt.Errorf("failed to ensure ProtectedService CRD: %w", err)
@@ -58,6 +72,10 @@ func ensureCRD(ctx context.Context, k8sClient client.Client, operatorNamespace s
 
 	updatedCRD := crd.DeepCopy()
 	updatedCRD.Spec = crdToCreate.Spec
+	// The caBundle is filled in by the cert rotator or the cert-manager CA injector, keep it rather than reset it.
+	if existingCABundle := conversionCABundle(&crd); len(existingCABundle) != 0 {
+		updatedCRD.Spec.Conversion.Webhook.ClientConfig.CABundle = existingCABundle
+	}
 	err = k8sClient.Patch(ctx, updatedCRD, client.MergeFrom(&crd))
 	if err != nil {
 		return fmt.Errorf("could not Patch ClientIntents CRD: %w", err)
@@ -65,3 +83,10 @@ func ensureCRD(ctx context.Context, k8sClient client.Client, operatorNamespace s
 
 	return nil
 }
+
+func conversionCABundle(crd *apiextensionsv1.CustomResourceDefinition) []byte {
+	if crd.Spec.Conversion == nil || crd.Spec.Conversion.Webhook == nil || crd.Spec.Conversion.Webhook.ClientConfig == nil {
+		return nil
+	}
+	return crd.Spec.Conversion.Webhook.ClientConfig.CABundle
+}
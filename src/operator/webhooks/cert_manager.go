@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+	"time"
+)
+
+// CertManagerCertSource serves the webhooks with a certificate issued by cert-manager into a Secret. The Secret is
+// watched, and renewed certificates are written to CertDirPath, where the webhook server picks them up without a
+// restart. The caBundles of the webhook configurations are injected by the cert-manager CA injector, so the operator
+// doesn't patch them.
+type CertManagerCertSource struct {
+	clientset  kubernetes.Interface
+	secretName types.NamespacedName
+	writeCert  func(bundle CertificateBundle) error
+
+	lock        sync.Mutex
+	writtenCert []byte
+	ready       chan struct{}
+	readyOnce   sync.Once
+}
+
+//+kubebuilder:rbac:groups="",namespace=otterize-system,resources=secrets,verbs=list;watch
+
+func NewCertManagerCertSource(clientset kubernetes.Interface, namespace string, secretName string) *CertManagerCertSource {
+	return &CertManagerCertSource{
+		clientset:  clientset,
+		secretName: types.NamespacedName{Name: secretName, Namespace: namespace},
+		writeCert:  WriteCertToFiles,
+		ready:      make(chan struct{}),
+	}
+}
+
+// Start watches the Secret until ctx is done, and blocks until cert-manager has issued the first certificate, since
+// the webhook server can't start without one.
+func (s *CertManagerCertSource) Start(ctx context.Context) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", s.secretName.Name).String()
+	secrets := s.clientset.CoreV1().Secrets(s.secretName.Namespace)
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return secrets.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return secrets.Watch(ctx, options)
+		},
+	}
+	informer := cache.NewSharedIndexInformer(listWatch, &corev1.Secret{}, 10*time.Minute, cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    s.handleSecret,
+		UpdateFunc: func(_, newObj interface{}) { s.handleSecret(newObj) },
+	})
+	if err != nil {
+		return err
+	}
+	go informer.Run(ctx.Done())
+
+	logrus.WithField("secret", s.secretName).Info("Waiting for cert-manager to issue the webhook certificate")
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.ready:
+		return nil
+	}
+}
+
+func (s *CertManagerCertSource) handleSecret(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.Name != s.secretName.Name || secret.Namespace != s.secretName.Namespace {
+		return
+	}
+	logger := logrus.WithField("secret", s.secretName)
+
+	certPem, privateKeyPem := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPem) == 0 || len(privateKeyPem) == 0 {
+		logger.Info("Webhook certificate secret has not been issued yet")
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if bytes.Equal(certPem, s.writtenCert) {
+		return
+	}
+	if err := s.writeCert(CertificateBundle{CertPem: certPem, PrivateKeyPem: privateKeyPem}); err != nil {
+		logger.WithError(err).Error("Failed writing webhook certificate")
+		return
+	}
+	s.writtenCert = certPem
+	logger.Info("Loaded webhook certificate issued by cert-manager")
+	s.readyOnce.Do(func() { close(s.ready) })
+}
@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+const certManagerSecretName = "intents-operator-webhook-cert"
+
+type CertManagerCertSourceTestSuite struct {
+	suite.Suite
+	clientset *fake.Clientset
+	source    *CertManagerCertSource
+	lock      sync.Mutex
+	written   []CertificateBundle
+}
+
+func (s *CertManagerCertSourceTestSuite) SetupTest() {
+	s.clientset = fake.NewSimpleClientset()
+	s.written = nil
+	s.source = NewCertManagerCertSource(s.clientset, testNamespace, certManagerSecretName)
+	s.source.writeCert = func(bundle CertificateBundle) error {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.written = append(s.written, bundle)
+		return nil
+	}
+}
+
+func (s *CertManagerCertSourceTestSuite) writtenCerts() []CertificateBundle {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]CertificateBundle{}, s.written...)
+}
+
+func (s *CertManagerCertSourceTestSuite) secret(name string, cert string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte(cert), corev1.TLSPrivateKeyKey: []byte("key-" + cert)},
+	}
+}
+
+func (s *CertManagerCertSourceTestSuite) TestWaitsForCertificateAndReloadsRenewals() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan error)
+	go func() { started <- s.source.Start(ctx) }()
+
+	// A secret that was not issued yet doesn't complete the wait.
+	secrets := s.clientset.CoreV1().Secrets(testNamespace)
+	pending := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: certManagerSecretName, Namespace: testNamespace}}
+	_, err := secrets.Create(ctx, pending, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	select {
+	case <-started:
+		s.Fail("started before the certificate was issued")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	_, err = secrets.Update(ctx, s.secret(certManagerSecretName, "first"), metav1.UpdateOptions{})
+	s.Require().NoError(err)
+	select {
+	case err := <-started:
+		s.Require().NoError(err)
+	case <-time.After(5 * time.Second):
+		s.Fail("timed out waiting for the certificate")
+	}
+	s.Require().Equal([]CertificateBundle{{CertPem: []byte("first"), PrivateKeyPem: []byte("key-first")}}, s.writtenCerts())
+
+	_, err = secrets.Update(ctx, s.secret(certManagerSecretName, "renewed"), metav1.UpdateOptions{})
+	s.Require().NoError(err)
+	s.Require().Eventually(func() bool { return len(s.writtenCerts()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	s.Require().Equal([]byte("renewed"), s.writtenCerts()[1].CertPem)
+}
+
+func (s *CertManagerCertSourceTestSuite) TestIgnoresOtherSecrets() {
+	s.source.handleSecret(s.secret("other", "cert"))
+	s.Require().Empty(s.writtenCerts())
+
+	s.source.handleSecret(s.secret(certManagerSecretName, "cert"))
+	s.source.handleSecret(s.secret(certManagerSecretName, "cert"))
+	s.Require().Len(s.writtenCerts(), 1)
+}
+
+func TestCertManagerCertSourceTestSuite(t *testing.T) {
+	suite.Run(t, new(CertManagerCertSourceTestSuite))
+}
@@ -0,0 +1,307 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"math/big"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	ValidatingWebhookConfigurationName = "otterize-validating-webhook-configuration"
+
+	caCertKey         = "ca.crt"
+	caPrivateKeyKey   = "ca.key"
+	previousCACertKey = "previous-ca.crt"
+	rsaKeySize        = 2048
+	// Certificates are renewed once less than a third of their lifetime remains.
+	renewalFraction           = 3
+	certRotationCheckInterval = 5 * time.Minute
+)
+
+type certificateAuthority struct {
+	cert       *x509.Certificate
+	certPem    []byte
+	privateKey *rsa.PrivateKey
+}
+
+// CertRotator issues short-lived serving certificates for the webhook server from a CA persisted in a Secret, and
+// renews both before they expire. Serving certificates are written to CertDirPath, where the webhook server picks them
+// up without a restart, and the webhook configurations are patched whenever the trusted CA bundle changes. While a
+// renewed CA is rolled out, the previous one stays in the bundle until it expires.
+type CertRotator struct {
+	client       client.Client
+	secretName   types.NamespacedName
+	hostname     string
+	caValidity   time.Duration
+	certValidity time.Duration
+	now          func() time.Time
+	writeCert    func(bundle CertificateBundle) error
+	publishCA    func(ctx context.Context, caBundle []byte) error
+
+	servingCert       *x509.Certificate
+	publishedCABundle []byte
+}
+
+//+kubebuilder:rbac:groups="",namespace=otterize-system,resources=secrets,verbs=get;create;update
+
+func NewCertRotator(k8sClient client.Client, hostname string, namespace string, secretName string, caValidity time.Duration, certValidity time.Duration) *CertRotator {
+	r := &CertRotator{
+		client:       k8sClient,
+		secretName:   types.NamespacedName{Name: secretName, Namespace: namespace},
+		hostname:     hostname,
+		caValidity:   caValidity,
+		certValidity: certValidity,
+		now:          time.Now,
+		writeCert:    WriteCertToFiles,
+	}
+	r.publishCA = r.updateWebhookCAs
+	return r
+}
+
+// NeedLeaderElection is false since every replica serves webhooks with its own serving certificate.
+func (r *CertRotator) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *CertRotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(certRotationCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Rotate(ctx); err != nil {
+				logrus.WithError(err).Error("Failed rotating webhook certificates")
+			}
+		}
+	}
+}
+
+// Rotate renews the CA and the serving certificate if they are close to expiry, and makes sure the webhook
+// configurations trust the CA before a certificate signed by it is served.
+func (r *CertRotator) Rotate(ctx context.Context) error {
+	ca, caBundle, err := r.ensureCA(ctx)
+	if err != nil {
+		return fmt.Errorf("failed ensuring webhook CA: %w", err)
+	}
+
+	if !bytes.Equal(caBundle, r.publishedCABundle) {
+		if err := r.publishCA(ctx, caBundle); err != nil {
+			return fmt.Errorf("failed updating webhook CA bundle: %w", err)
+		}
+		r.publishedCABundle = caBundle
+	}
+
+	if r.servingCert != nil && !r.needsRenewal(r.servingCert) && r.servingCert.CheckSignatureFrom(ca.cert) == nil {
+		return nil
+	}
+
+	bundle, servingCert, err := r.issueServingCert(ca)
+	if err != nil {
+		return fmt.Errorf("failed issuing webhook serving certificate: %w", err)
+	}
+	if err := r.writeCert(bundle); err != nil {
+		return fmt.Errorf("failed writing webhook serving certificate: %w", err)
+	}
+	r.servingCert = servingCert
+	logrus.WithField("expiry", servingCert.NotAfter).Info("Issued webhook serving certificate")
+	return nil
+}
+
+// ensureCA loads the CA from its Secret, creating or renewing it when needed, and returns it along with the bundle of
+// CAs that should be trusted.
+func (r *CertRotator) ensureCA(ctx context.Context) (*certificateAuthority, []byte, error) {
+	var ca *certificateAuthority
+	var caBundle []byte
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret := &corev1.Secret{}
+		err := r.client.Get(ctx, r.secretName, secret)
+		if k8serrors.IsNotFound(err) {
+			ca, err = r.generateCA()
+			if err != nil {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: r.secretName.Name, Namespace: r.secretName.Namespace},
+				Data:       map[string][]byte{caCertKey: ca.certPem, caPrivateKeyKey: encodePrivateKey(ca.privateKey)},
+			}
+			logrus.WithField("secret", r.secretName).Info("Creating webhook CA")
+			if err := r.client.Create(ctx, secret); err != nil {
+				if k8serrors.IsAlreadyExists(err) {
+					// Created by another replica, read it on the next attempt.
+					return k8serrors.NewConflict(corev1.Resource("secrets"), r.secretName.Name, err)
+				}
+				return fmt.Errorf("failed creating secret %s: %w", r.secretName, err)
+			}
+			caBundle = r.caBundle(secret)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed getting secret %s: %w", r.secretName, err)
+		}
+
+		current, err := parseCA(secret.Data[caCertKey], secret.Data[caPrivateKeyKey])
+		if err == nil && !r.needsRenewal(current.cert) {
+			ca = current
+			caBundle = r.caBundle(secret)
+			return nil
+		}
+		previousCACert := secret.Data[caCertKey]
+		if err != nil {
+			logrus.WithError(err).WithField("secret", r.secretName).Warning("Webhook CA is invalid, replacing it")
+			previousCACert = nil
+		}
+
+		ca, err = r.generateCA()
+		if err != nil {
+			return err
+		}
+		secret.Data = map[string][]byte{caCertKey: ca.certPem, caPrivateKeyKey: encodePrivateKey(ca.privateKey)}
+		if previousCACert != nil {
+			secret.Data[previousCACertKey] = previousCACert
+		}
+		logrus.WithField("secret", r.secretName).Info("Renewing webhook CA")
+		if err := r.client.Update(ctx, secret); err != nil {
+			return err
+		}
+		caBundle = r.caBundle(secret)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca, caBundle, nil
+}
+
+// caBundle returns the current CA, followed by the previous one as long as certificates it signed may still be served.
+func (r *CertRotator) caBundle(secret *corev1.Secret) []byte {
+	caBundle := bytes.Clone(secret.Data[caCertKey])
+	previousCACert, err := parseCertificate(secret.Data[previousCACertKey])
+	if err == nil && r.now().Before(previousCACert.NotAfter) {
+		caBundle = append(caBundle, secret.Data[previousCACertKey]...)
+	}
+	return caBundle
+}
+
+func (r *CertRotator) needsRenewal(cert *x509.Certificate) bool {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return r.now().After(cert.NotAfter.Add(-lifetime / renewalFraction))
+}
+
+func (r *CertRotator) generateCA() (*certificateAuthority, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", r.hostname)},
+		NotBefore:             now.Add(-10 * time.Minute),
+		NotAfter:              now.Add(r.caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	derCert, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(derCert)
+	if err != nil {
+		return nil, err
+	}
+	return &certificateAuthority{
+		cert:       cert,
+		certPem:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derCert}),
+		privateKey: privateKey,
+	}, nil
+}
+
+func (r *CertRotator) issueServingCert(ca *certificateAuthority) (CertificateBundle, *x509.Certificate, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return CertificateBundle{}, nil, err
+	}
+	now := r.now()
+	namespace := r.secretName.Namespace
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject:      pkix.Name{CommonName: r.hostname},
+		NotBefore:    now.Add(-10 * time.Minute),
+		NotAfter:     now.Add(r.certValidity),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{r.hostname, fmt.Sprintf("%s.%s.svc", r.hostname, namespace), fmt.Sprintf("%s.%s.svc.cluster.local", r.hostname, namespace)},
+	}
+	derCert, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &privateKey.PublicKey, ca.privateKey)
+	if err != nil {
+		return CertificateBundle{}, nil, err
+	}
+	cert, err := x509.ParseCertificate(derCert)
+	if err != nil {
+		return CertificateBundle{}, nil, err
+	}
+	return CertificateBundle{
+		CertPem:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derCert}),
+		PrivateKeyPem: encodePrivateKey(privateKey),
+	}, cert, nil
+}
+
+func (r *CertRotator) updateWebhookCAs(ctx context.Context, caBundle []byte) error {
+	if err := UpdateValidationWebHookCA(ctx, ValidatingWebhookConfigurationName, caBundle); err != nil {
+		return fmt.Errorf("failed updating the CA of the validating webhook: %w", err)
+	}
+	// The mutating webhook is only deployed when pods are labeled on admission.
+	if err := UpdateMutationWebHookCA(ctx, MutatingWebhookConfigurationName, caBundle); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed updating the CA of the mutating webhook: %w", err)
+	}
+	return UpdateConversionWebhookCAs(ctx, r.client, caBundle)
+}
+
+func parseCA(certPem []byte, privateKeyPem []byte) (*certificateAuthority, error) {
+	cert, err := parseCertificate(certPem)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(privateKeyPem)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &certificateAuthority{cert: cert, certPem: certPem, privateKey: privateKey}, nil
+}
+
+func parseCertificate(certPem []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPem)
+	if block == nil {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func encodePrivateKey(privateKey *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+}
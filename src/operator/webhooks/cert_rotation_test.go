@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/x509"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+const (
+	testHostname   = "intents-operator-webhook-service"
+	testNamespace  = "otterize-system"
+	testSecretName = "intents-operator-webhook-ca"
+)
+
+type CertRotatorTestSuite struct {
+	suite.Suite
+	client          *mocks.MockClient
+	rotator         *CertRotator
+	now             time.Time
+	secret          *corev1.Secret
+	writtenCerts    []CertificateBundle
+	publishedBundle []byte
+	publishCount    int
+}
+
+func (s *CertRotatorTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.client = mocks.NewMockClient(controller)
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.secret = nil
+	s.writtenCerts = nil
+	s.publishedBundle = nil
+	s.publishCount = 0
+
+	s.rotator = NewCertRotator(s.client, testHostname, testNamespace, testSecretName, Year, 24*time.Hour)
+	s.rotator.now = func() time.Time { return s.now }
+	s.rotator.writeCert = func(bundle CertificateBundle) error {
+		s.writtenCerts = append(s.writtenCerts, bundle)
+		return nil
+	}
+	s.rotator.publishCA = func(ctx context.Context, caBundle []byte) error {
+		s.publishedBundle = caBundle
+		s.publishCount++
+		return nil
+	}
+
+	// The mock client acts as a store for the CA secret.
+	secretKey := types.NamespacedName{Name: testSecretName, Namespace: testNamespace}
+	s.client.EXPECT().Get(gomock.Any(), secretKey, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name types.NamespacedName, secret *corev1.Secret, _ ...client.GetOption) error {
+			if s.secret == nil {
+				return k8serrors.NewNotFound(corev1.Resource("secrets"), name.Name)
+			}
+			s.secret.DeepCopyInto(secret)
+			return nil
+		}).AnyTimes()
+	storeSecret := func(ctx context.Context, secret *corev1.Secret, _ ...client.CreateOption) error {
+		s.secret = secret.DeepCopy()
+		return nil
+	}
+	s.client.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(storeSecret).AnyTimes()
+	s.client.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, secret *corev1.Secret, _ ...client.UpdateOption) error {
+			return storeSecret(ctx, secret)
+		}).AnyTimes()
+}
+
+func (s *CertRotatorTestSuite) lastServingCert() *x509.Certificate {
+	s.Require().NotEmpty(s.writtenCerts)
+	cert, err := parseCertificate(s.writtenCerts[len(s.writtenCerts)-1].CertPem)
+	s.Require().NoError(err)
+	return cert
+}
+
+func (s *CertRotatorTestSuite) verifyServingCert(caBundle []byte) {
+	roots := x509.NewCertPool()
+	s.Require().True(roots.AppendCertsFromPEM(caBundle))
+	_, err := s.lastServingCert().Verify(x509.VerifyOptions{
+		DNSName:     testHostname + "." + testNamespace + ".svc",
+		Roots:       roots,
+		CurrentTime: s.now,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *CertRotatorTestSuite) TestCreatesCAAndServingCert() {
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+
+	s.Require().NotNil(s.secret)
+	s.Require().Equal(s.secret.Data[caCertKey], s.publishedBundle)
+	s.Require().Len(s.writtenCerts, 1)
+	s.verifyServingCert(s.publishedBundle)
+
+	servingCert := s.lastServingCert()
+	s.Require().False(servingCert.IsCA)
+	s.Require().Equal(s.now.Add(24*time.Hour), servingCert.NotAfter)
+
+	// Nothing is renewed while the certificates are fresh.
+	s.now = s.now.Add(time.Hour)
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+	s.Require().Len(s.writtenCerts, 1)
+	s.Require().Equal(1, s.publishCount)
+}
+
+func (s *CertRotatorTestSuite) TestRenewsServingCertBeforeExpiry() {
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+	caCert := s.secret.Data[caCertKey]
+
+	s.now = s.now.Add(17 * time.Hour)
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+
+	s.Require().Len(s.writtenCerts, 2)
+	s.Require().Equal(s.now.Add(24*time.Hour), s.lastServingCert().NotAfter)
+	s.Require().Equal(caCert, s.secret.Data[caCertKey])
+	s.Require().Equal(1, s.publishCount)
+}
+
+func (s *CertRotatorTestSuite) TestRenewsCAAndKeepsPreviousInBundle() {
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+	previousCACert := s.secret.Data[caCertKey]
+
+	s.now = s.now.Add(250 * 24 * time.Hour)
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+
+	s.Require().NotEqual(previousCACert, s.secret.Data[caCertKey])
+	s.Require().Equal(previousCACert, s.secret.Data[previousCACertKey])
+	s.Require().Equal(append(append([]byte{}, s.secret.Data[caCertKey]...), previousCACert...), s.publishedBundle)
+	s.Require().Equal(2, s.publishCount)
+
+	// The serving certificate is reissued by the new CA.
+	roots := x509.NewCertPool()
+	s.Require().True(roots.AppendCertsFromPEM(s.secret.Data[caCertKey]))
+	_, err := s.lastServingCert().Verify(x509.VerifyOptions{Roots: roots, CurrentTime: s.now})
+	s.Require().NoError(err)
+
+	// Once the previous CA expires it is dropped from the bundle.
+	s.now = s.now.Add(120 * 24 * time.Hour)
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+	s.Require().Equal(s.secret.Data[caCertKey], s.publishedBundle)
+}
+
+func (s *CertRotatorTestSuite) TestReplacesInvalidCA() {
+	s.secret = &corev1.Secret{Data: map[string][]byte{caCertKey: []byte("not a certificate")}}
+
+	s.Require().NoError(s.rotator.Rotate(context.Background()))
+
+	s.Require().NotContains(s.secret.Data, previousCACertKey)
+	s.verifyServingCert(s.publishedBundle)
+}
+
+func TestCertRotatorTestSuite(t *testing.T) {
+	suite.Run(t, new(CertRotatorTestSuite))
+}
@@ -1,15 +1,9 @@
 package webhooks
 
 import (
-	"bytes"
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -17,7 +11,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"math/big"
 	"os"
 	"path/filepath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,46 +29,6 @@ type CertificateBundle struct {
 	PrivateKeyPem []byte
 }
 
-func GenerateSelfSignedCertificate(hostname string, namespace string) (CertificateBundle, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
-	if err != nil {
-		return CertificateBundle{}, err
-	}
-	certificate := x509.Certificate{
-		SerialNumber: big.NewInt(time.Now().Unix()),
-		Subject: pkix.Name{
-			CommonName: hostname,
-		},
-		NotBefore: time.Now().Add(-10 * time.Minute),
-		NotAfter:  time.Now().Add(10 * Year),
-
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		BasicConstraintsValid: true,
-		IsCA:                  true,
-		DNSNames:              []string{hostname, fmt.Sprintf("%s.%s.svc", hostname, namespace), fmt.Sprintf("%s.%s.svc.cluster.local", hostname, namespace)},
-	}
-	derCert, err := x509.CreateCertificate(rand.Reader, &certificate, &certificate, &privateKey.PublicKey, privateKey)
-	if err != nil {
-		return CertificateBundle{}, err
-	}
-
-	signedCert := &bytes.Buffer{}
-	err = pem.Encode(signedCert, &pem.Block{Type: "CERTIFICATE", Bytes: derCert})
-	if err != nil {
-		return CertificateBundle{}, err
-	}
-
-	privateKeyPem := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	})
-	return CertificateBundle{
-		CertPem:       signedCert.Bytes(),
-		PrivateKeyPem: privateKeyPem,
-	}, nil
-}
-
 func UpdateMutationWebHookCA(ctx context.Context, webHookName string, ca []byte) error {
 	kubeClient, err := getKubeClient()
 	if err != nil {
@@ -174,6 +127,12 @@ func UpdateConversionWebhookCAs(ctx context.Context, k8sClient client.Client, ca
 	if err := updateConversionWebHookCA(ctx, "clientintents.k8s.otterize.com", k8sClient, ca); err != nil {
 		return fmt.Errorf("failed updating the CA of clientIntents conversion webhhok: %w", err)
 	}
+	if err := updateConversionWebHookCA(ctx, "clusterclientintents.k8s.otterize.com", k8sClient, ca); err != nil {
+		return fmt.Errorf("failed updating the CA of clusterClientIntents conversion webhhok: %w", err)
+	}
+	if err := updateConversionWebHookCA(ctx, "defaultclientintents.k8s.otterize.com", k8sClient, ca); err != nil {
+		return fmt.Errorf("failed updating the CA of defaultClientIntents conversion webhhok: %w", err)
+	}
 	if err := updateConversionWebHookCA(ctx, "protectedservices.k8s.otterize.com", k8sClient, ca); err != nil {
 		return fmt.Errorf("failed updating the CA of protectedServices conversion webhhok: %w", err)
 	}
@@ -0,0 +1,100 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"github.com/otterize/intents-operator/src/operator/controllers/pod_reconcilers"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
+	"github.com/otterize/intents-operator/src/shared/serviceidresolver"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const MutatingWebhookConfigurationName = "otterize-mutating-webhook-configuration"
+
+// PodLabelsMutator sets the labels the PodWatcher would set on a pod when it is created, so that the pod is selected
+// by its network policies from the moment it starts. The PodWatcher still repairs the labels of pods that were
+// created while the webhook was unavailable, or whose intents changed since.
+// Pods in the operator namespace, or outside the watched namespaces, are left alone. The webhook configuration also
+// excludes the operator namespace, so that the operator's own pods are not admitted through its webhook.
+type PodLabelsMutator struct {
+	client            client.Client
+	serviceIdResolver *serviceidresolver.Resolver
+	accessLabels      accesslabels.Enum
+	watchedNamespaces sets.Set[string]
+	operatorNamespace string
+}
+
+func NewPodLabelsMutator(c client.Client, accessLabels accesslabels.Enum, watchedNamespaces []string, operatorNamespace string) *PodLabelsMutator {
+	return &PodLabelsMutator{
+		client:            c,
+		serviceIdResolver: serviceidresolver.NewResolver(c),
+		accessLabels:      accessLabels,
+		watchedNamespaces: sets.New(watchedNamespaces...),
+		operatorNamespace: operatorNamespace,
+	}
+}
+
+func (m *PodLabelsMutator) shouldLabel(namespace string) bool {
+	if namespace == m.operatorNamespace {
+		return false
+	}
+	return m.watchedNamespaces.Len() == 0 || m.watchedNamespaces.Has(namespace)
+}
+
+func (m *PodLabelsMutator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(m).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=podlabels.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &PodLabelsMutator{}
+
+// Default implements webhook.CustomDefaulter. Failures are logged rather than returned, so that pods are never
+// rejected because they could not be labeled.
+func (m *PodLabelsMutator) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got a %T", obj)
+	}
+	// The namespace of a pod being created is only set on the request, not on the object.
+	if pod.Namespace == "" {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			pod.Namespace = req.Namespace
+		}
+	}
+	if !m.shouldLabel(pod.Namespace) {
+		return nil
+	}
+
+	logger := logrus.WithFields(logrus.Fields{"pod": pod.Name, "generateName": pod.GenerateName, "namespace": pod.Namespace})
+	serviceID, err := m.serviceIdResolver.ResolvePodToServiceIdentity(ctx, pod)
+	if err != nil {
+		logger.WithError(err).Warning("failed resolving the service identity of a new pod, it will be labeled once it starts")
+		return nil
+	}
+	if serviceID.Name == "" {
+		// Pods without owners are identified by their name, which isn't known yet if it is generated.
+		return nil
+	}
+
+	labeledPod, hasUpdates, err := pod_reconcilers.LabelPod(ctx, m.client, serviceID, *pod, m.accessLabels)
+	if err != nil {
+		logger.WithError(err).Warning("failed labeling a new pod, it will be labeled once it starts")
+		return nil
+	}
+	if hasUpdates {
+		pod.Labels = labeledPod.Labels
+		pod.Annotations = labeledPod.Annotations
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	otterizev1alpha3 "github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"testing"
+)
+
+const podLabelsOperatorNamespace = "intents-operator"
+
+type PodLabelsMutatorTestSuite struct {
+	suite.Suite
+	client  *mocks.MockClient
+	mutator *PodLabelsMutator
+}
+
+func (s *PodLabelsMutatorTestSuite) SetupTest() {
+	controller := gomock.NewController(s.T())
+	s.client = mocks.NewMockClient(controller)
+	s.mutator = NewPodLabelsMutator(s.client, accesslabels.PerServer, nil, podLabelsOperatorNamespace)
+}
+
+func (s *PodLabelsMutatorTestSuite) admissionContext() context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Namespace: testNamespace},
+	})
+}
+
+func (s *PodLabelsMutatorTestSuite) TestLabelsServerAndAccess() {
+	clientIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "client"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "server"}},
+		},
+	}
+	s.client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntentsList{}), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.ClientIntents{clientIntents}
+			return nil
+		})
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		GenerateName: "client-",
+		Labels:       map[string]string{"app": "client"},
+		Annotations:  map[string]string{"intents.otterize.com/service-name": "client"},
+	}}
+
+	s.Require().NoError(s.mutator.Default(s.admissionContext(), pod))
+
+	s.Require().Equal(testNamespace, pod.Namespace)
+	s.Require().Equal("client", pod.Labels["app"])
+	s.Require().Equal(otterizev1alpha3.GetFormattedOtterizeIdentity("client", testNamespace), pod.Labels[otterizev1alpha3.OtterizeServerLabelKey])
+	s.Require().Equal(otterizev1alpha3.GetFormattedOtterizeIdentity("client", testNamespace), pod.Labels[otterizev1alpha3.OtterizeClientLabelKey])
+	for key, value := range clientIntents.GetIntentsLabelMapping(testNamespace) {
+		s.Require().Equal(value, pod.Labels[key])
+	}
+}
+
+func (s *PodLabelsMutatorTestSuite) TestLabelsOwnerKindAndSkipsIntentsOfOtherKinds() {
+	s.client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "db", Namespace: testNamespace}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...client.GetOption) error {
+			obj.SetName("db")
+			obj.SetNamespace(testNamespace)
+			return nil
+		})
+	statefulSetIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-statefulset-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "db", Kind: "StatefulSet"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "storage"}},
+		},
+	}
+	deploymentIntents := otterizev1alpha3.ClientIntents{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-deployment-intents", Namespace: testNamespace},
+		Spec: &otterizev1alpha3.IntentsSpec{
+			Service: otterizev1alpha3.Service{Name: "db", Kind: "Deployment"},
+			Calls:   []otterizev1alpha3.Intent{{Name: "cache"}},
+		},
+	}
+	s.client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntentsList{}), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.ClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.ClientIntents{statefulSetIntents, deploymentIntents}
+			return nil
+		})
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		GenerateName:    "db-",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db"}},
+	}}
+
+	s.Require().NoError(s.mutator.Default(s.admissionContext(), pod))
+
+	s.Require().Equal("StatefulSet", pod.Labels[otterizev1alpha3.OtterizeOwnerKindLabelKey])
+	for key := range statefulSetIntents.GetIntentsLabelMapping(testNamespace) {
+		s.Require().Contains(pod.Labels, key)
+	}
+	for key := range deploymentIntents.GetIntentsLabelMapping(testNamespace) {
+		s.Require().NotContains(pod.Labels, key)
+	}
+}
+
+func (s *PodLabelsMutatorTestSuite) TestLabelsDefaultAccessOfNamespaceWithoutIntents() {
+	s.client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.ClientIntentsList{}), gomock.Any(), gomock.Any()).Return(nil)
+	defaultCalls := []otterizev1alpha3.Intent{{Name: "kube-dns.kube-system"}, {Name: "otel-collector"}}
+	s.client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&otterizev1alpha3.DefaultClientIntentsList{}), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list *otterizev1alpha3.DefaultClientIntentsList, _ ...client.ListOption) error {
+			list.Items = []otterizev1alpha3.DefaultClientIntents{{
+				ObjectMeta: metav1.ObjectMeta{Name: "baseline", Namespace: testNamespace},
+				Spec:       otterizev1alpha3.DefaultClientIntentsSpec{Calls: defaultCalls},
+			}}
+			return nil
+		})
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		GenerateName: "client-",
+		Annotations:  map[string]string{"intents.otterize.com/service-name": "client"},
+	}}
+
+	s.Require().NoError(s.mutator.Default(s.admissionContext(), pod))
+
+	s.Require().Equal(otterizev1alpha3.GetFormattedOtterizeIdentity("client", testNamespace), pod.Labels[otterizev1alpha3.OtterizeClientLabelKey])
+	defaultIntents := otterizev1alpha3.ClientIntents{Spec: &otterizev1alpha3.IntentsSpec{Calls: defaultCalls}}
+	s.Require().Len(defaultIntents.GetIntentsLabelMapping(testNamespace), 2)
+	for key, value := range defaultIntents.GetIntentsLabelMapping(testNamespace) {
+		s.Require().Equal(value, pod.Labels[key])
+	}
+}
+
+func (s *PodLabelsMutatorTestSuite) TestFailureDoesNotRejectPod() {
+	s.client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("API server unavailable"))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		GenerateName:    "web-7d4b9c-",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-7d4b9c"}},
+	}}
+
+	s.Require().NoError(s.mutator.Default(s.admissionContext(), pod))
+	s.Require().Empty(pod.Labels)
+}
+
+func (s *PodLabelsMutatorTestSuite) TestPodsOutsideWatchedNamespacesAreNotLabeled() {
+	s.mutator = NewPodLabelsMutator(s.client, accesslabels.PerServer, []string{"shop"}, podLabelsOperatorNamespace)
+	for _, namespace := range []string{podLabelsOperatorNamespace, testNamespace} {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "client",
+			Namespace:   namespace,
+			Annotations: map[string]string{"intents.otterize.com/service-name": "client"},
+		}}
+
+		// The mock client fails the test on any call, so the pod is not even resolved to a service.
+		s.Require().NoError(s.mutator.Default(context.Background(), pod))
+		s.Require().Empty(pod.Labels)
+	}
+}
+
+func TestPodLabelsMutatorTestSuite(t *testing.T) {
+	suite.Run(t, new(PodLabelsMutatorTestSuite))
+}
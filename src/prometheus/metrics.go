@@ -30,6 +30,26 @@ var (
 		Name: "protected_services_applied",
 		Help: "The total number of ProtectedService resources applied",
 	})
+	cloudReportQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cloud_report_queue_depth",
+		Help: "The number of reports waiting to be delivered to Otterize Cloud",
+	})
+	cloudReportFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cloud_report_failures",
+		Help: "The total number of failed attempts to deliver a queued report to Otterize Cloud, by report type",
+	}, []string{"type"})
+	circuitBreakersOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breakers_open",
+		Help: "The number of open circuit breakers, by the component they protect",
+	}, []string{"component"})
+	kafkaServerConnected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_server_connected",
+		Help: "Whether the pooled admin connection to a Kafka server is healthy",
+	}, []string{"server"})
+	kafkaACLDrift = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_acl_drift_repaired",
+		Help: "The total number of Kafka ACLs found missing or unexpected on a server and repaired by the periodic resync",
+	}, []string{"server", "kind"})
 )
 
 func IncrementIntentsApplied(count int) {
@@ -54,3 +74,31 @@ func IncrementPodsUnlabeledForNetworkPolicies(count int) {
 func SetProtectedServicesApplied(count int) {
 	protectedServiceApplied.Set(float64(count))
 }
+
+func SetCloudReportQueueDepth(count int) {
+	cloudReportQueueDepth.Set(float64(count))
+}
+
+func IncrementCloudReportFailures(reportType string) {
+	cloudReportFailures.WithLabelValues(reportType).Inc()
+}
+
+func SetCircuitBreakersOpen(component string, count int) {
+	circuitBreakersOpen.WithLabelValues(component).Set(float64(count))
+}
+
+func SetKafkaServerConnected(server string, connected bool) {
+	value := 0.0
+	if connected {
+		value = 1
+	}
+	kafkaServerConnected.WithLabelValues(server).Set(value)
+}
+
+func DeleteKafkaServerConnected(server string) {
+	kafkaServerConnected.DeleteLabelValues(server)
+}
+
+func IncrementKafkaACLDriftRepaired(server string, kind string, count int) {
+	kafkaACLDrift.WithLabelValues(server, kind).Add(float64(count))
+}
@@ -0,0 +1,152 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+const (
+	ResourceKindNetworkPolicy       = "NetworkPolicy"
+	ResourceKindDefaultDenyPolicy   = "DefaultDenyNetworkPolicy"
+	ResourceKindAuthorizationPolicy = "AuthorizationPolicy"
+	ResourceKindKafkaACL            = "KafkaACL"
+	ResourceKindKafkaTopic          = "KafkaTopic"
+	ResourceKindKafkaQuota          = "KafkaQuota"
+	ResourceKindIAMRole             = "IAMRole"
+	ResourceKindIAMPolicy           = "IAMPolicy"
+)
+
+// Trigger identifies the Otterize resource whose reconciliation caused an enforcement change.
+type Trigger struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type Record struct {
+	Timestamp         time.Time `json:"timestamp"`
+	Action            Action    `json:"action"`
+	ResourceKind      string    `json:"resourceKind"`
+	ResourceName      string    `json:"resourceName"`
+	ResourceNamespace string    `json:"resourceNamespace,omitempty"`
+	Trigger           *Trigger  `json:"trigger,omitempty"`
+	Diff              string    `json:"diff,omitempty"`
+}
+
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+type triggerContextKey struct{}
+
+var (
+	sinksLock = sync.RWMutex{}
+	sinks     []Sink
+)
+
+// SetGlobalSinks replaces the sinks every audit record is written to. With no sinks configured, Emit is a no-op.
+func SetGlobalSinks(newSinks ...Sink) {
+	sinksLock.Lock()
+	defer sinksLock.Unlock()
+	sinks = newSinks
+}
+
+func ContextWithTrigger(ctx context.Context, trigger Trigger) context.Context {
+	return context.WithValue(ctx, triggerContextKey{}, trigger)
+}
+
+func TriggerFromContext(ctx context.Context) (Trigger, bool) {
+	trigger, ok := ctx.Value(triggerContextKey{}).(Trigger)
+	return trigger, ok
+}
+
+// Emit writes the record to all configured sinks. Failing to write an audit record never fails enforcement,
+// so errors are only logged.
+func Emit(ctx context.Context, record Record) {
+	sinksLock.RLock()
+	defer sinksLock.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+	if record.Trigger == nil {
+		if trigger, ok := TriggerFromContext(ctx); ok {
+			record.Trigger = &trigger
+		}
+	}
+
+	for _, sink := range sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			logrus.WithError(err).WithField("resource", record.ResourceName).Error("failed writing audit record")
+		}
+	}
+}
+
+func ObjectCreated(ctx context.Context, kind string, obj client.Object) {
+	Emit(ctx, Record{
+		Action:            ActionCreated,
+		ResourceKind:      kind,
+		ResourceName:      obj.GetName(),
+		ResourceNamespace: obj.GetNamespace(),
+		Diff:              Diff(nil, obj),
+	})
+}
+
+func ObjectUpdated(ctx context.Context, kind string, before client.Object, after client.Object) {
+	Emit(ctx, Record{
+		Action:            ActionUpdated,
+		ResourceKind:      kind,
+		ResourceName:      after.GetName(),
+		ResourceNamespace: after.GetNamespace(),
+		Diff:              Diff(before, after),
+	})
+}
+
+func ObjectDeleted(ctx context.Context, kind string, obj client.Object) {
+	Emit(ctx, Record{
+		Action:            ActionDeleted,
+		ResourceKind:      kind,
+		ResourceName:      obj.GetName(),
+		ResourceNamespace: obj.GetNamespace(),
+		Diff:              Diff(obj, nil),
+	})
+}
+
+// Diff returns a human-readable diff between the JSON representations of before and after. Values are normalized
+// through JSON so that types with unexported fields (e.g. protobuf messages) can be compared.
+func Diff(before any, after any) string {
+	return cmp.Diff(normalize(before), normalize(after))
+}
+
+func normalize(value any) any {
+	if value == nil {
+		return nil
+	}
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		logrus.WithError(err).Warning("failed serializing value for audit diff")
+		return nil
+	}
+	var normalized any
+	if err := json.Unmarshal(serialized, &normalized); err != nil {
+		logrus.WithError(err).Warning("failed deserializing value for audit diff")
+		return nil
+	}
+	return normalized
+}
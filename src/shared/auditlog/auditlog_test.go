@@ -0,0 +1,138 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type AuditLogTestSuite struct {
+	suite.Suite
+	buffer *bytes.Buffer
+}
+
+func (s *AuditLogTestSuite) SetupTest() {
+	s.buffer = &bytes.Buffer{}
+	SetGlobalSinks(NewWriterSink(s.buffer))
+}
+
+func (s *AuditLogTestSuite) TearDownTest() {
+	SetGlobalSinks()
+}
+
+func (s *AuditLogTestSuite) readRecords() []Record {
+	records := make([]Record, 0)
+	for _, line := range strings.Split(strings.TrimSpace(s.buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := Record{}
+		s.Require().NoError(json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func (s *AuditLogTestSuite) TestObjectCreatedWritesJSONLineWithTrigger() {
+	ctx := ContextWithTrigger(context.Background(), Trigger{Kind: "ClientIntents", Name: "client-intents", Namespace: "test-namespace"})
+	policy := &v1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "access-to-server", Namespace: "test-namespace"}}
+
+	ObjectCreated(ctx, ResourceKindNetworkPolicy, policy)
+
+	records := s.readRecords()
+	s.Require().Len(records, 1)
+	s.Require().Equal(ActionCreated, records[0].Action)
+	s.Require().Equal(ResourceKindNetworkPolicy, records[0].ResourceKind)
+	s.Require().Equal("access-to-server", records[0].ResourceName)
+	s.Require().Equal("test-namespace", records[0].ResourceNamespace)
+	s.Require().Equal(&Trigger{Kind: "ClientIntents", Name: "client-intents", Namespace: "test-namespace"}, records[0].Trigger)
+	s.Require().False(records[0].Timestamp.IsZero())
+	s.Require().Contains(records[0].Diff, "access-to-server")
+}
+
+func (s *AuditLogTestSuite) TestObjectUpdatedDiffContainsChanges() {
+	before := &v1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "access-to-server", Namespace: "test-namespace"},
+		Spec:       v1.NetworkPolicySpec{PolicyTypes: []v1.PolicyType{v1.PolicyTypeIngress}},
+	}
+	after := before.DeepCopy()
+	after.Spec.PolicyTypes = append(after.Spec.PolicyTypes, v1.PolicyTypeEgress)
+
+	ObjectUpdated(context.Background(), ResourceKindNetworkPolicy, before, after)
+
+	records := s.readRecords()
+	s.Require().Len(records, 1)
+	s.Require().Equal(ActionUpdated, records[0].Action)
+	s.Require().Nil(records[0].Trigger)
+	addedLines := lo.Filter(strings.Split(records[0].Diff, "\n"), func(line string, _ int) bool {
+		return strings.HasPrefix(line, "+")
+	})
+	s.Require().Len(addedLines, 1)
+	s.Require().Contains(addedLines[0], "Egress")
+}
+
+func (s *AuditLogTestSuite) TestExplicitTriggerIsNotOverridden() {
+	ctx := ContextWithTrigger(context.Background(), Trigger{Kind: "ClientIntents"})
+	Emit(ctx, Record{Action: ActionDeleted, ResourceKind: ResourceKindKafkaACL, Trigger: &Trigger{Kind: "KafkaServerConfig"}})
+
+	records := s.readRecords()
+	s.Require().Len(records, 1)
+	s.Require().Equal("KafkaServerConfig", records[0].Trigger.Kind)
+}
+
+func (s *AuditLogTestSuite) TestNoSinksIsNoop() {
+	SetGlobalSinks()
+	Emit(context.Background(), Record{Action: ActionDeleted, ResourceKind: ResourceKindIAMRole})
+	s.Require().Empty(s.buffer.String())
+}
+
+func (s *AuditLogTestSuite) TestWebhookSink() {
+	received := make(chan Record, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := Record{}
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&record))
+		received <- record
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := NewWebhookSink(server.URL, time.Second, 10)
+	go func() { _ = sink.Start(ctx) }()
+
+	err := sink.Write(context.Background(), Record{Action: ActionCreated, ResourceKind: ResourceKindIAMPolicy, ResourceName: "policy"})
+	s.Require().NoError(err)
+	s.Require().Equal("policy", (<-received).ResourceName)
+}
+
+func (s *AuditLogTestSuite) TestWebhookSinkFailureStatus() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, time.Second, 10)
+	err := sink.send(context.Background(), Record{Action: ActionCreated})
+	s.Require().Error(err)
+}
+
+func (s *AuditLogTestSuite) TestWebhookSinkDoesNotBlockWhenQueueIsFull() {
+	// The sink isn't started, so the endpoint is never reached and records pile up in the queue.
+	sink := NewWebhookSink("http://audit.invalid", time.Second, 1)
+	s.Require().NoError(sink.Write(context.Background(), Record{Action: ActionCreated}))
+	s.Require().Error(sink.Write(context.Background(), Record{Action: ActionDeleted}))
+}
+
+func TestAuditLogTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditLogTestSuite))
+}
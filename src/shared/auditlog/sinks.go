@@ -0,0 +1,114 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WriterSink writes each record as a single JSON line to the underlying writer.
+type WriterSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{writer: writer}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// NewFileSink opens (or creates) a JSON-lines file and appends records to it.
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening audit log file: %w", err)
+	}
+	return NewWriterSink(file), nil
+}
+
+func (s *WriterSink) Write(_ context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed serializing audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = s.writer.Write(line)
+	if err != nil {
+		return fmt.Errorf("failed writing audit record: %w", err)
+	}
+	return nil
+}
+
+// WebhookSink POSTs each record as JSON to an HTTP endpoint. Records are queued and sent in the background by Start,
+// so a slow or unreachable endpoint never holds up enforcement. Records that don't fit in the queue are dropped.
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+	queue      chan Record
+}
+
+func NewWebhookSink(url string, timeout time.Duration, queueSize int) *WebhookSink {
+	return &WebhookSink{url: url, httpClient: &http.Client{Timeout: timeout}, queue: make(chan Record, queueSize)}
+}
+
+// Write queues the record for delivery. It fails without blocking if the queue is full.
+func (s *WebhookSink) Write(_ context.Context, record Record) error {
+	select {
+	case s.queue <- record:
+		return nil
+	default:
+		return errors.New("audit webhook queue is full, dropping audit record")
+	}
+}
+
+// Start sends queued records until ctx is done. Failed deliveries are logged and not retried.
+func (s *WebhookSink) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case record := <-s.queue:
+			if err := s.send(ctx, record); err != nil {
+				logrus.WithError(err).WithField("resource", record.ResourceName).Error("failed sending audit record to webhook")
+			}
+		}
+	}
+}
+
+func (s *WebhookSink) send(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed serializing audit record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed creating audit webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed sending audit record to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/otterize/intents-operator/src/operator/api/v1alpha3"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
+	"net/url"
 )
 
 func (a *Agent) AddRolePolicy(ctx context.Context, namespace string, accountName string, intentsServiceName string, statements []StatementEntry) error {
@@ -126,6 +128,7 @@ func (a *Agent) DeleteRolePolicy(ctx context.Context, policyName string) error {
 		return err
 	}
 
+	auditlog.Emit(ctx, auditlog.Record{Action: auditlog.ActionDeleted, ResourceKind: auditlog.ResourceKindIAMPolicy, ResourceName: *policy.Arn})
 	return nil
 }
 
@@ -152,6 +155,12 @@ func (a *Agent) SetRolePolicy(ctx context.Context, namespace, accountName string
 		return err
 	}
 
+	previousPolicyDoc, err := a.getRolePolicyDocument(ctx, role)
+
+	if err != nil {
+		return err
+	}
+
 	_, err = a.iamClient.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
 		PolicyDocument: aws.String(policyDoc),
 		PolicyName:     role.RoleName,
@@ -162,6 +171,14 @@ func (a *Agent) SetRolePolicy(ctx context.Context, namespace, accountName string
 		return err
 	}
 
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            auditlog.ActionUpdated,
+		ResourceKind:      auditlog.ResourceKindIAMPolicy,
+		ResourceName:      *role.RoleName,
+		ResourceNamespace: namespace,
+		Diff:              auditlog.Diff(previousPolicyDoc, json.RawMessage(policyDoc)),
+	})
+
 	return nil
 }
 
@@ -196,6 +213,14 @@ func (a *Agent) createPolicy(ctx context.Context, role *types.Role, namespace st
 		return nil, err
 	}
 
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            auditlog.ActionCreated,
+		ResourceKind:      auditlog.ResourceKindIAMPolicy,
+		ResourceName:      fullPolicyName,
+		ResourceNamespace: namespace,
+		Diff:              auditlog.Diff(nil, json.RawMessage(policyDoc)),
+	})
+
 	err = a.attachPolicy(ctx, role, policy.Policy)
 
 	if err != nil {
@@ -220,6 +245,12 @@ func (a *Agent) updatePolicy(ctx context.Context, policy *types.Policy, statemen
 		return nil
 	}
 
+	previousPolicyDoc, err := a.getPolicyVersionDocument(ctx, policy)
+
+	if err != nil {
+		return err
+	}
+
 	err = a.deleteOldestPolicyVersion(ctx, policy)
 
 	if err != nil {
@@ -236,6 +267,13 @@ func (a *Agent) updatePolicy(ctx context.Context, policy *types.Policy, statemen
 		return err
 	}
 
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:       auditlog.ActionUpdated,
+		ResourceKind: auditlog.ResourceKindIAMPolicy,
+		ResourceName: *policy.Arn,
+		Diff:         auditlog.Diff(previousPolicyDoc, json.RawMessage(policyDoc)),
+	})
+
 	_, err = a.iamClient.TagPolicy(ctx, &iam.TagPolicyInput{
 		PolicyArn: policy.Arn,
 		Tags: []types.Tag{
@@ -292,6 +330,53 @@ func (a *Agent) attachPolicy(ctx context.Context, role *types.Role, policy *type
 	return err
 }
 
+// getRolePolicyDocument returns the inline policy document of the role, or nil if it doesn't have one yet.
+func (a *Agent) getRolePolicyDocument(ctx context.Context, role *types.Role) (json.RawMessage, error) {
+	output, err := a.iamClient.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+		PolicyName: role.RoleName,
+		RoleName:   role.RoleName,
+	})
+
+	if err != nil {
+		if isNoSuchEntityException(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return decodePolicyDocument(output.PolicyDocument)
+}
+
+// getPolicyVersionDocument returns the document of the policy's default version.
+func (a *Agent) getPolicyVersionDocument(ctx context.Context, policy *types.Policy) (json.RawMessage, error) {
+	output, err := a.iamClient.GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{
+		PolicyArn: policy.Arn,
+		VersionId: policy.DefaultVersionId,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodePolicyDocument(output.PolicyVersion.Document)
+}
+
+// decodePolicyDocument decodes a policy document returned by IAM, which is URL-encoded.
+func decodePolicyDocument(document *string) (json.RawMessage, error) {
+	if document == nil {
+		return nil, nil
+	}
+
+	decoded, err := url.PathUnescape(*document)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding policy document: %w", err)
+	}
+
+	return json.RawMessage(decoded), nil
+}
+
 func generatePolicyDocument(statements []StatementEntry) (string, string, error) {
 	policy := PolicyDocument{
 		Version:   iamAPIVersion,
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/smithy-go"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 	"strings"
@@ -90,6 +91,13 @@ func (a *Agent) CreateOtterizeIAMRole(ctx context.Context, namespaceName, accoun
 			return nil, createRoleError
 		}
 
+		auditlog.Emit(ctx, auditlog.Record{
+			Action:            auditlog.ActionCreated,
+			ResourceKind:      auditlog.ResourceKindIAMRole,
+			ResourceName:      *createRoleOutput.Role.RoleName,
+			ResourceNamespace: namespaceName,
+			Diff:              auditlog.Diff(nil, json.RawMessage(trustPolicy)),
+		})
 		logger.Debugf("created new role, arn: %s", *createRoleOutput.Role.Arn)
 		return createRoleOutput.Role, nil
 	}
@@ -137,6 +145,13 @@ func (a *Agent) DeleteOtterizeIAMRole(ctx context.Context, namespaceName, accoun
 		return err
 	}
 
+	auditlog.Emit(ctx, auditlog.Record{
+		Action:            auditlog.ActionDeleted,
+		ResourceKind:      auditlog.ResourceKindIAMRole,
+		ResourceName:      *role.RoleName,
+		ResourceNamespace: namespaceName,
+	})
+
 	return nil
 }
 
@@ -0,0 +1,225 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFailureThreshold = 3
+	DefaultInitialBackoff   = 5 * time.Second
+	DefaultMaxBackoff       = 5 * time.Minute
+)
+
+// Key identifies a single breaker. Component is the kind of backend (e.g. a reconciler, a Kafka server or an AWS
+// account) and is used as the metric label, Target is the specific instance.
+type Key struct {
+	Component string
+	Target    string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s", k.Component, k.Target)
+}
+
+// OpenError is returned for calls made while a breaker is open, and for the failure that opens it. Err is the last
+// failure of the breaker, and RetryAfter the time until the next call may be let through.
+type OpenError struct {
+	Key        Key
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is open, retrying in %s: %v", e.Key, e.RetryAfter, e.Err)
+}
+
+func (e *OpenError) Unwrap() error {
+	return e.Err
+}
+
+type breakerState struct {
+	consecutiveFailures int
+	openUntil           time.Time
+	lastErr             error
+}
+
+// Registry tracks consecutive failures per key. Every failure backs off exponentially, and once a key fails
+// failureThreshold times in a row its breaker opens: Allow rejects calls until the backoff elapses, after which a
+// single trial call is let through. A success closes the breaker and resets the backoff.
+type Registry struct {
+	lock             sync.Mutex
+	breakers         map[Key]*breakerState
+	failureThreshold int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	now              func() time.Time
+}
+
+func NewRegistry(failureThreshold int, initialBackoff time.Duration, maxBackoff time.Duration) *Registry {
+	return &Registry{
+		breakers:         make(map[Key]*breakerState),
+		failureThreshold: failureThreshold,
+		initialBackoff:   initialBackoff,
+		maxBackoff:       maxBackoff,
+		now:              time.Now,
+	}
+}
+
+var globalRegistry = NewRegistry(DefaultFailureThreshold, DefaultInitialBackoff, DefaultMaxBackoff)
+
+func SetGlobalRegistry(registry *Registry) {
+	globalRegistry = registry
+}
+
+func GlobalRegistry() *Registry {
+	return globalRegistry
+}
+
+// Allow returns whether a call for key may proceed, and if not, how long until it may be retried.
+func (r *Registry) Allow(key Key) (bool, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state, ok := r.breakers[key]
+	if !ok || !r.isOpen(state) {
+		return true, 0
+	}
+	remaining := state.openUntil.Sub(r.now())
+	if remaining <= 0 {
+		// Half-open - let a trial call through, and hold off others until it reports back.
+		state.openUntil = r.now().Add(r.backoff(state.consecutiveFailures))
+		return true, 0
+	}
+	return false, remaining
+}
+
+// RecordSuccess closes the breaker for key.
+func (r *Registry) RecordSuccess(key Key) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state, ok := r.breakers[key]
+	if !ok {
+		return
+	}
+	wasOpen := r.isOpen(state)
+	delete(r.breakers, key)
+	if wasOpen {
+		logrus.WithField("breaker", key.String()).Info("Circuit breaker closed")
+		r.updateMetric(key.Component)
+	}
+}
+
+// RecordFailure records a failed call for key, and returns whether the breaker is now open along with the time to
+// wait before retrying.
+func (r *Registry) RecordFailure(key Key, err error) (bool, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state, ok := r.breakers[key]
+	if !ok {
+		state = &breakerState{}
+		r.breakers[key] = state
+	}
+	wasOpen := r.isOpen(state)
+	state.consecutiveFailures++
+	state.lastErr = err
+	retryAfter := r.backoff(state.consecutiveFailures)
+	state.openUntil = r.now().Add(retryAfter)
+
+	open := r.isOpen(state)
+	if open && !wasOpen {
+		logrus.WithField("breaker", key.String()).WithField("retryAfter", retryAfter).Warning("Circuit breaker opened")
+		r.updateMetric(key.Component)
+	}
+	return open, retryAfter
+}
+
+// Call runs f unless the breaker for key is open. Failures below the threshold are returned as is; once the breaker
+// is open, an *OpenError wrapping the last failure is returned, so callers know both why and when to retry.
+func (r *Registry) Call(key Key, f func() error) error {
+	if allowed, retryAfter := r.Allow(key); !allowed {
+		logrus.WithField("breaker", key.String()).Debugf("Circuit breaker is open, retrying in %s", retryAfter)
+		return &OpenError{Key: key, RetryAfter: retryAfter, Err: r.lastError(key)}
+	}
+
+	err := f()
+	if err == nil {
+		r.RecordSuccess(key)
+		return nil
+	}
+
+	open, retryAfter := r.RecordFailure(key, err)
+	if !open {
+		return err
+	}
+	logrus.WithError(err).WithField("breaker", key.String()).Errorf("Keeps failing, retrying in %s", retryAfter)
+	return &OpenError{Key: key, RetryAfter: retryAfter, Err: err}
+}
+
+func (r *Registry) lastError(key Key) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state, ok := r.breakers[key]
+	if !ok {
+		return nil
+	}
+	return state.lastErr
+}
+
+// IsOpen returns whether the breaker for key is open, including while it is waiting for a trial call.
+func (r *Registry) IsOpen(key Key) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state, ok := r.breakers[key]
+	return ok && r.isOpen(state)
+}
+
+// OpenBreakers returns the keys of all open breakers, sorted.
+func (r *Registry) OpenBreakers() []Key {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]Key, 0)
+	for key, state := range r.breakers {
+		if r.isOpen(state) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+func (r *Registry) isOpen(state *breakerState) bool {
+	return state.consecutiveFailures >= r.failureThreshold
+}
+
+func (r *Registry) backoff(failures int) time.Duration {
+	backoff := r.initialBackoff
+	for i := 1; i < failures && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return backoff
+}
+
+func (r *Registry) updateMetric(component string) {
+	count := 0
+	for key, state := range r.breakers {
+		if key.Component == component && r.isOpen(state) {
+			count++
+		}
+	}
+	prometheus.SetCircuitBreakersOpen(component, count)
+}
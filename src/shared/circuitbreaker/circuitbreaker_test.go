@@ -0,0 +1,114 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+var testKey = Key{Component: "kafka-server", Target: "kafka/kafka"}
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+	registry    *Registry
+	currentTime time.Time
+}
+
+func (s *CircuitBreakerTestSuite) SetupTest() {
+	s.registry = NewRegistry(3, time.Second, 10*time.Second)
+	s.currentTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.registry.now = func() time.Time { return s.currentTime }
+}
+
+var errUnavailable = errors.New("unavailable")
+
+// call returns the time to wait before retrying along with the error, if the breaker is open.
+func (s *CircuitBreakerTestSuite) call(f func() error) (time.Duration, error) {
+	err := s.registry.Call(testKey, f)
+	var openErr *OpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter, err
+	}
+	return 0, err
+}
+
+func (s *CircuitBreakerTestSuite) fail() (time.Duration, error) {
+	return s.call(func() error { return errUnavailable })
+}
+
+func (s *CircuitBreakerTestSuite) TestErrorsReturnedBelowThreshold() {
+	for i := 0; i < 2; i++ {
+		retryAfter, err := s.fail()
+		s.Require().Error(err)
+		s.Require().Zero(retryAfter)
+	}
+	s.Require().False(s.registry.IsOpen(testKey))
+}
+
+func (s *CircuitBreakerTestSuite) TestOpensAtThresholdAndSkipsCalls() {
+	_, _ = s.fail()
+	_, _ = s.fail()
+	retryAfter, err := s.fail()
+	var openErr *OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Require().ErrorIs(err, errUnavailable)
+	s.Require().Equal(4*time.Second, retryAfter)
+	s.Require().True(s.registry.IsOpen(testKey))
+	s.Require().Equal([]Key{testKey}, s.registry.OpenBreakers())
+
+	called := false
+	s.currentTime = s.currentTime.Add(time.Second)
+	retryAfter, err = s.call(func() error {
+		called = true
+		return nil
+	})
+	// Skipped calls still report the failure that opened the breaker.
+	s.Require().ErrorIs(err, errUnavailable)
+	s.Require().False(called)
+	s.Require().Equal(3*time.Second, retryAfter)
+}
+
+func (s *CircuitBreakerTestSuite) TestSuccessfulTrialCallCloses() {
+	for i := 0; i < 3; i++ {
+		_, _ = s.fail()
+	}
+
+	s.currentTime = s.currentTime.Add(5 * time.Second)
+	retryAfter, err := s.call(func() error { return nil })
+	s.Require().NoError(err)
+	s.Require().Zero(retryAfter)
+	s.Require().False(s.registry.IsOpen(testKey))
+	s.Require().Empty(s.registry.OpenBreakers())
+}
+
+func (s *CircuitBreakerTestSuite) TestFailedTrialCallBacksOffFurther() {
+	for i := 0; i < 3; i++ {
+		_, _ = s.fail()
+	}
+
+	s.currentTime = s.currentTime.Add(5 * time.Second)
+	retryAfter, err := s.fail()
+	s.Require().ErrorIs(err, errUnavailable)
+	s.Require().Equal(8*time.Second, retryAfter)
+
+	s.currentTime = s.currentTime.Add(10 * time.Second)
+	_, _ = s.fail()
+	s.currentTime = s.currentTime.Add(10 * time.Second)
+	retryAfter, _ = s.fail()
+	s.Require().Equal(10*time.Second, retryAfter, "backoff should be capped")
+}
+
+func (s *CircuitBreakerTestSuite) TestBreakersAreIndependent() {
+	for i := 0; i < 3; i++ {
+		_, _ = s.fail()
+	}
+
+	otherKey := Key{Component: "kafka-server", Target: "kafka/other"}
+	allowed, _ := s.registry.Allow(otherKey)
+	s.Require().True(allowed)
+}
+
+func TestCircuitBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerTestSuite))
+}
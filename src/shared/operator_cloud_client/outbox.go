@@ -0,0 +1,325 @@
+package operator_cloud_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/otterize/intents-operator/src/prometheus"
+	"github.com/otterize/intents-operator/src/shared/otterizecloud/graphqlclient"
+	"github.com/otterize/intents-operator/src/shared/otterizecloud/otterizecloudclient"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	outboxConfigMapDataKey = "outbox.json"
+	outboxFlushInterval    = 1 * time.Second
+	outboxInitialBackoff   = 1 * time.Second
+	outboxMaxBackoff       = 5 * time.Minute
+	// ConfigMaps are limited to 1MiB, leave some headroom for metadata
+	outboxMaxPersistedBytes = 900 * 1024
+)
+
+type outboxKey struct {
+	Type      ReportType `json:"type"`
+	Namespace string     `json:"namespace"`
+}
+
+type outboxEntry struct {
+	Key         outboxKey       `json:"key"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+}
+
+// OutboxCloudClient wraps a CloudClient and queues namespace snapshot reports instead of sending them inline.
+// Reports are coalesced per namespace and report type - since every report is a full snapshot, only the latest
+// one needs to be delivered. Failed reports are retried with exponential backoff, and the queue may optionally be
+// persisted to a ConfigMap so that it survives operator restarts.
+type OutboxCloudClient struct {
+	CloudClient
+	lock         sync.Mutex
+	entries      map[outboxKey]*outboxEntry
+	wakeup       chan struct{}
+	k8sClient    client.Client
+	configMapKey *types.NamespacedName
+	dirty        bool
+	now          func() time.Time
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;patch
+
+// NewOutboxCloudClient creates an outbox in front of cloudClient. If k8sClient is non-nil, the queue is persisted
+// to the ConfigMap referenced by configMapKey.
+func NewOutboxCloudClient(cloudClient CloudClient, k8sClient client.Client, configMapKey *types.NamespacedName) *OutboxCloudClient {
+	return &OutboxCloudClient{
+		CloudClient:  cloudClient,
+		entries:      make(map[outboxKey]*outboxEntry),
+		wakeup:       make(chan struct{}, 1),
+		k8sClient:    k8sClient,
+		configMapKey: configMapKey,
+		now:          time.Now,
+	}
+}
+
+func (o *OutboxCloudClient) ReportKafkaServerConfig(_ context.Context, namespace string, servers []graphqlclient.KafkaServerConfigInput) error {
+	return o.enqueue(outboxKey{Type: ReportTypeKafkaServerConfig, Namespace: namespace}, servers)
+}
+
+func (o *OutboxCloudClient) ReportAppliedIntents(_ context.Context, namespace *string, intents []*graphqlclient.IntentInput) error {
+	return o.enqueue(outboxKey{Type: ReportTypeAppliedIntents, Namespace: *namespace}, intents)
+}
+
+func (o *OutboxCloudClient) ReportNetworkPolicies(_ context.Context, namespace string, policies []graphqlclient.NetworkPolicyInput) error {
+	return o.enqueue(outboxKey{Type: ReportTypeNetworkPolicies, Namespace: namespace}, policies)
+}
+
+func (o *OutboxCloudClient) ReportProtectedServices(_ context.Context, namespace string, protectedServices []graphqlclient.ProtectedServiceInput) error {
+	return o.enqueue(outboxKey{Type: ReportTypeProtectedServices, Namespace: namespace}, protectedServices)
+}
+
+func (o *OutboxCloudClient) enqueue(key outboxKey, payload any) error {
+	serializedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed serializing cloud report: %w", err)
+	}
+
+	o.lock.Lock()
+	// A newer snapshot replaces any pending one for the same key, and is sent as soon as possible.
+	o.entries[key] = &outboxEntry{Key: key, Payload: serializedPayload}
+	o.dirty = true
+	o.updateQueueDepthMetric()
+	o.lock.Unlock()
+
+	select {
+	case o.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start loads any persisted queue and starts delivering reports in the background until ctx is done.
+func (o *OutboxCloudClient) Start(ctx context.Context) {
+	if err := o.load(ctx); err != nil {
+		logrus.WithError(err).Error("Failed loading persisted cloud report outbox, starting with an empty queue")
+	}
+
+	go func() {
+		ticker := time.NewTicker(outboxFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				o.persistIfDirty(context.Background())
+				return
+			case <-ticker.C:
+			case <-o.wakeup:
+			}
+			o.flush(ctx)
+			o.persistIfDirty(ctx)
+		}
+	}()
+}
+
+// QueueDepth returns the number of reports waiting to be delivered.
+func (o *OutboxCloudClient) QueueDepth() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return len(o.entries)
+}
+
+func (o *OutboxCloudClient) flush(ctx context.Context) {
+	for _, entry := range o.dueEntries() {
+		timeoutCtx, cancel := context.WithTimeout(ctx, viper.GetDuration(otterizecloudclient.CloudClientTimeoutKey))
+		err := o.send(timeoutCtx, entry)
+		cancel()
+		o.completeAttempt(entry, err)
+	}
+}
+
+func (o *OutboxCloudClient) dueEntries() []*outboxEntry {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	now := o.now()
+	due := make([]*outboxEntry, 0)
+	for _, entry := range o.entries {
+		if !entry.NextAttempt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	return due
+}
+
+func (o *OutboxCloudClient) completeAttempt(entry *outboxEntry, sendErr error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	current, ok := o.entries[entry.Key]
+	// The entry was replaced by a newer snapshot while it was being sent - keep the newer one untouched.
+	if !ok || current != entry {
+		return
+	}
+
+	if sendErr == nil {
+		delete(o.entries, entry.Key)
+		o.dirty = true
+		o.updateQueueDepthMetric()
+		return
+	}
+
+	entry.Attempts++
+	prometheus.IncrementCloudReportFailures(string(entry.Key.Type))
+	entry.NextAttempt = o.now().Add(outboxBackoff(entry.Attempts))
+	o.dirty = true
+	logrus.WithError(sendErr).
+		WithField("type", entry.Key.Type).
+		WithField("namespace", entry.Key.Namespace).
+		WithField("attempts", entry.Attempts).
+		Warning("Failed reporting to Otterize Cloud, will retry")
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxInitialBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
+
+func (o *OutboxCloudClient) send(ctx context.Context, entry *outboxEntry) error {
+	namespace := entry.Key.Namespace
+	switch entry.Key.Type {
+	case ReportTypeAppliedIntents:
+		var intents []*graphqlclient.IntentInput
+		if err := json.Unmarshal(entry.Payload, &intents); err != nil {
+			return fmt.Errorf("failed deserializing applied intents report: %w", err)
+		}
+		return o.CloudClient.ReportAppliedIntents(ctx, &namespace, intents)
+	case ReportTypeNetworkPolicies:
+		var policies []graphqlclient.NetworkPolicyInput
+		if err := json.Unmarshal(entry.Payload, &policies); err != nil {
+			return fmt.Errorf("failed deserializing network policies report: %w", err)
+		}
+		return o.CloudClient.ReportNetworkPolicies(ctx, namespace, policies)
+	case ReportTypeProtectedServices:
+		var protectedServices []graphqlclient.ProtectedServiceInput
+		if err := json.Unmarshal(entry.Payload, &protectedServices); err != nil {
+			return fmt.Errorf("failed deserializing protected services report: %w", err)
+		}
+		return o.CloudClient.ReportProtectedServices(ctx, namespace, protectedServices)
+	case ReportTypeKafkaServerConfig:
+		var servers []graphqlclient.KafkaServerConfigInput
+		if err := json.Unmarshal(entry.Payload, &servers); err != nil {
+			return fmt.Errorf("failed deserializing kafka server config report: %w", err)
+		}
+		return o.CloudClient.ReportKafkaServerConfig(ctx, namespace, servers)
+	}
+
+	return fmt.Errorf("unknown cloud report type %s", entry.Key.Type)
+}
+
+func (o *OutboxCloudClient) updateQueueDepthMetric() {
+	prometheus.SetCloudReportQueueDepth(len(o.entries))
+}
+
+func (o *OutboxCloudClient) load(ctx context.Context) error {
+	if o.k8sClient == nil {
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := o.k8sClient.Get(ctx, *o.configMapKey, configMap)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed getting outbox ConfigMap: %w", err)
+	}
+
+	data, ok := configMap.Data[outboxConfigMapDataKey]
+	if !ok {
+		return nil
+	}
+
+	var entries []*outboxEntry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return fmt.Errorf("failed deserializing outbox ConfigMap: %w", err)
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for _, entry := range entries {
+		// Reports enqueued since startup are newer than persisted ones.
+		if _, exists := o.entries[entry.Key]; !exists {
+			o.entries[entry.Key] = entry
+		}
+	}
+	o.updateQueueDepthMetric()
+	logrus.Infof("Loaded %d pending cloud reports from ConfigMap %s", len(entries), o.configMapKey)
+	return nil
+}
+
+func (o *OutboxCloudClient) persistIfDirty(ctx context.Context) {
+	if o.k8sClient == nil {
+		return
+	}
+
+	o.lock.Lock()
+	if !o.dirty {
+		o.lock.Unlock()
+		return
+	}
+	entries := make([]*outboxEntry, 0, len(o.entries))
+	for _, entry := range o.entries {
+		entries = append(entries, entry)
+	}
+	serialized, err := json.Marshal(entries)
+	o.dirty = false
+	o.lock.Unlock()
+
+	if err == nil {
+		err = o.persist(ctx, serialized)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed persisting cloud report outbox")
+		o.lock.Lock()
+		o.dirty = true
+		o.lock.Unlock()
+	}
+}
+
+func (o *OutboxCloudClient) persist(ctx context.Context, serialized []byte) error {
+	if len(serialized) > outboxMaxPersistedBytes {
+		return fmt.Errorf("outbox size %d exceeds ConfigMap limit, not persisting", len(serialized))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := o.k8sClient.Get(ctx, *o.configMapKey, configMap)
+	if k8serrors.IsNotFound(err) {
+		configMap.Name = o.configMapKey.Name
+		configMap.Namespace = o.configMapKey.Namespace
+		configMap.Data = map[string]string{outboxConfigMapDataKey: string(serialized)}
+		return o.k8sClient.Create(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+
+	updated := configMap.DeepCopy()
+	updated.Data = map[string]string{outboxConfigMapDataKey: string(serialized)}
+	return o.k8sClient.Patch(ctx, updated, client.MergeFrom(configMap))
+}
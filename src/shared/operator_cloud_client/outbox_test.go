@@ -0,0 +1,105 @@
+package operator_cloud_client
+
+import (
+	"context"
+	"errors"
+	"github.com/otterize/intents-operator/src/shared/otterizecloud/graphqlclient"
+	otterizecloudmocks "github.com/otterize/intents-operator/src/shared/otterizecloud/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const testNamespace = "test-namespace"
+
+type OutboxCloudClientTestSuite struct {
+	suite.Suite
+	controller  *gomock.Controller
+	cloudClient *otterizecloudmocks.MockCloudClient
+	outbox      *OutboxCloudClient
+	currentTime time.Time
+}
+
+func (s *OutboxCloudClientTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.cloudClient = otterizecloudmocks.NewMockCloudClient(s.controller)
+	s.outbox = NewOutboxCloudClient(s.cloudClient, nil, nil)
+	s.currentTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.outbox.now = func() time.Time { return s.currentTime }
+}
+
+func (s *OutboxCloudClientTestSuite) TearDownTest() {
+	s.controller.Finish()
+}
+
+func (s *OutboxCloudClientTestSuite) TestReportsAreCoalescedPerNamespace() {
+	first := []graphqlclient.NetworkPolicyInput{{Namespace: testNamespace, Name: "first"}}
+	second := []graphqlclient.NetworkPolicyInput{{Namespace: testNamespace, Name: "second"}}
+
+	s.Require().NoError(s.outbox.ReportNetworkPolicies(context.Background(), testNamespace, first))
+	s.Require().NoError(s.outbox.ReportNetworkPolicies(context.Background(), testNamespace, second))
+	s.Require().Equal(1, s.outbox.QueueDepth())
+
+	s.cloudClient.EXPECT().ReportNetworkPolicies(gomock.Any(), testNamespace, second).Return(nil).Times(1)
+	s.outbox.flush(context.Background())
+	s.Require().Equal(0, s.outbox.QueueDepth())
+}
+
+func (s *OutboxCloudClientTestSuite) TestDifferentReportTypesAreQueuedSeparately() {
+	intents := []*graphqlclient.IntentInput{{ClientName: lo.ToPtr("client"), ServerName: lo.ToPtr("server")}}
+	services := []graphqlclient.ProtectedServiceInput{{Name: "server"}}
+
+	s.Require().NoError(s.outbox.ReportAppliedIntents(context.Background(), lo.ToPtr(testNamespace), intents))
+	s.Require().NoError(s.outbox.ReportProtectedServices(context.Background(), testNamespace, services))
+	s.Require().Equal(2, s.outbox.QueueDepth())
+
+	s.cloudClient.EXPECT().ReportAppliedIntents(gomock.Any(), lo.ToPtr(testNamespace), intents).Return(nil)
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, services).Return(nil)
+	s.outbox.flush(context.Background())
+	s.Require().Equal(0, s.outbox.QueueDepth())
+}
+
+func (s *OutboxCloudClientTestSuite) TestFailedReportIsRetriedAfterBackoff() {
+	services := []graphqlclient.ProtectedServiceInput{{Name: "server"}}
+	s.Require().NoError(s.outbox.ReportProtectedServices(context.Background(), testNamespace, services))
+
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, services).Return(errors.New("unavailable"))
+	s.outbox.flush(context.Background())
+	s.Require().Equal(1, s.outbox.QueueDepth())
+
+	// Backoff has not elapsed yet - nothing should be sent.
+	s.currentTime = s.currentTime.Add(outboxInitialBackoff / 2)
+	s.outbox.flush(context.Background())
+	s.Require().Equal(1, s.outbox.QueueDepth())
+
+	s.currentTime = s.currentTime.Add(outboxInitialBackoff)
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, services).Return(nil)
+	s.outbox.flush(context.Background())
+	s.Require().Equal(0, s.outbox.QueueDepth())
+}
+
+func (s *OutboxCloudClientTestSuite) TestNewerReportResetsBackoff() {
+	first := []graphqlclient.ProtectedServiceInput{{Name: "first"}}
+	second := []graphqlclient.ProtectedServiceInput{{Name: "second"}}
+	s.Require().NoError(s.outbox.ReportProtectedServices(context.Background(), testNamespace, first))
+
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, first).Return(errors.New("unavailable"))
+	s.outbox.flush(context.Background())
+
+	s.Require().NoError(s.outbox.ReportProtectedServices(context.Background(), testNamespace, second))
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, second).Return(nil)
+	s.outbox.flush(context.Background())
+	s.Require().Equal(0, s.outbox.QueueDepth())
+}
+
+func (s *OutboxCloudClientTestSuite) TestBackoffIsCapped() {
+	s.Require().Equal(outboxInitialBackoff, outboxBackoff(1))
+	s.Require().Equal(4*outboxInitialBackoff, outboxBackoff(3))
+	s.Require().Equal(outboxMaxBackoff, outboxBackoff(100))
+}
+
+func TestOutboxCloudClientTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxCloudClientTestSuite))
+}
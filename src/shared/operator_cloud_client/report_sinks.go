@@ -0,0 +1,346 @@
+package operator_cloud_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig"
+	"github.com/otterize/intents-operator/src/shared/otterizecloud/graphqlclient"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ReportType string
+
+const (
+	ReportTypeAppliedIntents               ReportType = "AppliedIntents"
+	ReportTypeNetworkPolicies              ReportType = "NetworkPolicies"
+	ReportTypeProtectedServices            ReportType = "ProtectedServices"
+	ReportTypeKafkaServerConfig            ReportType = "KafkaServerConfig"
+	ReportTypeIntentsOperatorConfiguration ReportType = "IntentsOperatorConfiguration"
+	ReportTypeComponentStatus              ReportType = "ComponentStatus"
+)
+
+const (
+	ReportingSinkWebhook   = "webhook"
+	ReportingSinkFile      = "file"
+	ReportingSinkConfigMap = "configmap"
+
+	// ConfigMaps are limited to 1MiB, leave some headroom for metadata and other keys
+	reportConfigMapMaxEntryBytes = 512 * 1024
+)
+
+var ErrDatabaseIntentsRequireCloud = errors.New("database intents can only be applied through Otterize Cloud")
+
+// Report is a single snapshot written to a ReportSink. Namespace is empty for cluster-wide reports, such as the
+// operator configuration.
+type Report struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Type      ReportType `json:"type"`
+	Namespace string     `json:"namespace,omitempty"`
+	Payload   any        `json:"payload"`
+}
+
+type ReportSink interface {
+	WriteReport(ctx context.Context, report Report) error
+}
+
+// SinkCloudClient implements CloudClient on top of a ReportSink, so that the data reported to Otterize Cloud can
+// flow into other systems as well.
+type SinkCloudClient struct {
+	sink ReportSink
+	now  func() time.Time
+}
+
+func NewSinkCloudClient(sink ReportSink) *SinkCloudClient {
+	return &SinkCloudClient{sink: sink, now: time.Now}
+}
+
+func (c *SinkCloudClient) write(ctx context.Context, reportType ReportType, namespace string, payload any) error {
+	return c.sink.WriteReport(ctx, Report{Timestamp: c.now(), Type: reportType, Namespace: namespace, Payload: payload})
+}
+
+func (c *SinkCloudClient) ReportKafkaServerConfig(ctx context.Context, namespace string, servers []graphqlclient.KafkaServerConfigInput) error {
+	return c.write(ctx, ReportTypeKafkaServerConfig, namespace, servers)
+}
+
+func (c *SinkCloudClient) ReportAppliedIntents(ctx context.Context, namespace *string, intents []*graphqlclient.IntentInput) error {
+	return c.write(ctx, ReportTypeAppliedIntents, lo.FromPtr(namespace), intents)
+}
+
+func (c *SinkCloudClient) ReportIntentsOperatorConfiguration(ctx context.Context, config graphqlclient.IntentsOperatorConfigurationInput) error {
+	return c.write(ctx, ReportTypeIntentsOperatorConfiguration, "", config)
+}
+
+func (c *SinkCloudClient) ReportComponentStatus(ctx context.Context, component graphqlclient.ComponentType) {
+	if err := c.write(ctx, ReportTypeComponentStatus, "", component); err != nil {
+		logrus.WithError(err).Error("failed to report component status to reporting sink")
+	}
+}
+
+func (c *SinkCloudClient) ReportNetworkPolicies(ctx context.Context, namespace string, policies []graphqlclient.NetworkPolicyInput) error {
+	return c.write(ctx, ReportTypeNetworkPolicies, namespace, policies)
+}
+
+func (c *SinkCloudClient) ReportProtectedServices(ctx context.Context, namespace string, protectedServices []graphqlclient.ProtectedServiceInput) error {
+	return c.write(ctx, ReportTypeProtectedServices, namespace, protectedServices)
+}
+
+func (c *SinkCloudClient) ApplyDatabaseIntent(_ context.Context, _ []graphqlclient.IntentInput, _ graphqlclient.DBPermissionChange) error {
+	return ErrDatabaseIntentsRequireCloud
+}
+
+// MultiCloudClient reports to Otterize Cloud (if connected) and to every configured sink. A failure in one
+// destination does not prevent reporting to the others.
+type MultiCloudClient struct {
+	cloudClient CloudClient
+	sinks       []CloudClient
+}
+
+func NewMultiCloudClient(cloudClient CloudClient, sinks ...CloudClient) *MultiCloudClient {
+	return &MultiCloudClient{cloudClient: cloudClient, sinks: sinks}
+}
+
+func (c *MultiCloudClient) destinations() []CloudClient {
+	if c.cloudClient == nil {
+		return c.sinks
+	}
+	return append([]CloudClient{c.cloudClient}, c.sinks...)
+}
+
+func (c *MultiCloudClient) forEach(report func(CloudClient) error) error {
+	errs := make([]error, 0)
+	for _, destination := range c.destinations() {
+		if err := report(destination); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *MultiCloudClient) ReportKafkaServerConfig(ctx context.Context, namespace string, servers []graphqlclient.KafkaServerConfigInput) error {
+	return c.forEach(func(destination CloudClient) error {
+		return destination.ReportKafkaServerConfig(ctx, namespace, servers)
+	})
+}
+
+func (c *MultiCloudClient) ReportAppliedIntents(ctx context.Context, namespace *string, intents []*graphqlclient.IntentInput) error {
+	return c.forEach(func(destination CloudClient) error {
+		return destination.ReportAppliedIntents(ctx, namespace, intents)
+	})
+}
+
+func (c *MultiCloudClient) ReportIntentsOperatorConfiguration(ctx context.Context, config graphqlclient.IntentsOperatorConfigurationInput) error {
+	return c.forEach(func(destination CloudClient) error {
+		return destination.ReportIntentsOperatorConfiguration(ctx, config)
+	})
+}
+
+func (c *MultiCloudClient) ReportComponentStatus(ctx context.Context, component graphqlclient.ComponentType) {
+	for _, destination := range c.destinations() {
+		destination.ReportComponentStatus(ctx, component)
+	}
+}
+
+func (c *MultiCloudClient) ReportNetworkPolicies(ctx context.Context, namespace string, policies []graphqlclient.NetworkPolicyInput) error {
+	return c.forEach(func(destination CloudClient) error {
+		return destination.ReportNetworkPolicies(ctx, namespace, policies)
+	})
+}
+
+func (c *MultiCloudClient) ReportProtectedServices(ctx context.Context, namespace string, protectedServices []graphqlclient.ProtectedServiceInput) error {
+	return c.forEach(func(destination CloudClient) error {
+		return destination.ReportProtectedServices(ctx, namespace, protectedServices)
+	})
+}
+
+func (c *MultiCloudClient) ApplyDatabaseIntent(ctx context.Context, intents []graphqlclient.IntentInput, action graphqlclient.DBPermissionChange) error {
+	if c.cloudClient == nil {
+		return ErrDatabaseIntentsRequireCloud
+	}
+	return c.cloudClient.ApplyDatabaseIntent(ctx, intents, action)
+}
+
+// WebhookReportSink POSTs each report as JSON to an HTTP endpoint.
+type WebhookReportSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewWebhookReportSink(url string, timeout time.Duration) *WebhookReportSink {
+	return &WebhookReportSink{url: url, httpClient: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookReportSink) WriteReport(ctx context.Context, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed serializing report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed creating reporting webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed sending report to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("reporting webhook returned unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FileReportSink appends each report as a single JSON line to a file.
+type FileReportSink struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+func NewFileReportSink(path string) (*FileReportSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening report file: %w", err)
+	}
+	return &FileReportSink{file: file}, nil
+}
+
+func (s *FileReportSink) WriteReport(_ context.Context, report Report) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed serializing report: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed writing report: %w", err)
+	}
+	return nil
+}
+
+// ConfigMapReportSink keeps the latest report of each type and namespace in a single ConfigMap, keyed by
+// "<namespace>.<type>.json" (or "<type>.json" for cluster-wide reports).
+type ConfigMapReportSink struct {
+	lock         sync.Mutex
+	k8sClient    client.Client
+	configMapKey types.NamespacedName
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;patch
+
+func NewConfigMapReportSink(k8sClient client.Client, configMapKey types.NamespacedName) *ConfigMapReportSink {
+	return &ConfigMapReportSink{k8sClient: k8sClient, configMapKey: configMapKey}
+}
+
+func configMapReportKey(report Report) string {
+	if report.Namespace == "" {
+		return fmt.Sprintf("%s.json", report.Type)
+	}
+	return fmt.Sprintf("%s.%s.json", report.Namespace, report.Type)
+}
+
+func (s *ConfigMapReportSink) WriteReport(ctx context.Context, report Report) error {
+	serialized, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed serializing report: %w", err)
+	}
+	if len(serialized) > reportConfigMapMaxEntryBytes {
+		return fmt.Errorf("report %s of size %d exceeds ConfigMap limit", configMapReportKey(report), len(serialized))
+	}
+
+	// Serialize writes from concurrent reconcilers, so that patches don't conflict with each other.
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	configMap := &corev1.ConfigMap{}
+	err = s.k8sClient.Get(ctx, s.configMapKey, configMap)
+	if k8serrors.IsNotFound(err) {
+		configMap.Name = s.configMapKey.Name
+		configMap.Namespace = s.configMapKey.Namespace
+		configMap.Data = map[string]string{configMapReportKey(report): string(serialized)}
+		if err := s.k8sClient.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("failed creating reports ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed getting reports ConfigMap: %w", err)
+	}
+
+	updated := configMap.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = make(map[string]string)
+	}
+	updated.Data[configMapReportKey(report)] = string(serialized)
+	if err := s.k8sClient.Patch(ctx, updated, client.MergeFrom(configMap)); err != nil {
+		return fmt.Errorf("failed patching reports ConfigMap: %w", err)
+	}
+	return nil
+}
+
+func newReportSink(name string, k8sClient client.Client, podNamespace string) (ReportSink, error) {
+	switch name {
+	case ReportingSinkWebhook:
+		url := viper.GetString(operatorconfig.ReportingWebhookURLKey)
+		if url == "" {
+			return nil, fmt.Errorf("reporting sink %s requires %s to be set", name, operatorconfig.ReportingWebhookURLKey)
+		}
+		return NewWebhookReportSink(url, viper.GetDuration(operatorconfig.ReportingWebhookTimeoutKey)), nil
+	case ReportingSinkFile:
+		path := viper.GetString(operatorconfig.ReportingFilePathKey)
+		if path == "" {
+			return nil, fmt.Errorf("reporting sink %s requires %s to be set", name, operatorconfig.ReportingFilePathKey)
+		}
+		return NewFileReportSink(path)
+	case ReportingSinkConfigMap:
+		configMapKey := types.NamespacedName{Name: viper.GetString(operatorconfig.ReportingConfigMapNameKey), Namespace: podNamespace}
+		return NewConfigMapReportSink(k8sClient, configMapKey), nil
+	}
+	return nil, fmt.Errorf("unknown reporting sink %q", name)
+}
+
+// NewClientWithReportingSinks returns a CloudClient that reports to cloudClient (which may be nil if the operator
+// is not connected to Otterize Cloud) and to every sink listed in operatorconfig.ReportingSinksKey.
+// Returns nil if there is nowhere to report to.
+func NewClientWithReportingSinks(cloudClient CloudClient, k8sClient client.Client, podNamespace string) (CloudClient, error) {
+	sinkNames := lo.Uniq(lo.Filter(
+		lo.Map(strings.Split(viper.GetString(operatorconfig.ReportingSinksKey), ","), func(name string, _ int) string {
+			return strings.ToLower(strings.TrimSpace(name))
+		}),
+		func(name string, _ int) bool { return name != "" },
+	))
+
+	if len(sinkNames) == 0 {
+		return cloudClient, nil
+	}
+
+	sinks := make([]CloudClient, 0, len(sinkNames))
+	for _, name := range sinkNames {
+		sink, err := newReportSink(name, k8sClient, podNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed initializing reporting sink: %w", err)
+		}
+		logrus.WithField("sink", name).Info("Reporting to additional sink")
+		sinks = append(sinks, NewSinkCloudClient(sink))
+	}
+
+	return NewMultiCloudClient(cloudClient, sinks...), nil
+}
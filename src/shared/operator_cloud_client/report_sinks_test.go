@@ -0,0 +1,153 @@
+package operator_cloud_client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig"
+	"github.com/otterize/intents-operator/src/shared/otterizecloud/graphqlclient"
+	otterizecloudmocks "github.com/otterize/intents-operator/src/shared/otterizecloud/mocks"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ReportSinksTestSuite struct {
+	suite.Suite
+	controller  *gomock.Controller
+	cloudClient *otterizecloudmocks.MockCloudClient
+}
+
+func (s *ReportSinksTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.cloudClient = otterizecloudmocks.NewMockCloudClient(s.controller)
+}
+
+func (s *ReportSinksTestSuite) TearDownTest() {
+	viper.Set(operatorconfig.ReportingSinksKey, "")
+	viper.Set(operatorconfig.ReportingFilePathKey, "")
+	viper.Set(operatorconfig.ReportingWebhookURLKey, "")
+	s.controller.Finish()
+}
+
+func (s *ReportSinksTestSuite) readFileReports(path string) []Report {
+	file, err := os.Open(path)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	reports := make([]Report, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		report := Report{}
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &report))
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func (s *ReportSinksTestSuite) TestFileSinkWritesReports() {
+	path := filepath.Join(s.T().TempDir(), "reports.jsonl")
+	sink, err := NewFileReportSink(path)
+	s.Require().NoError(err)
+	client := NewSinkCloudClient(sink)
+
+	err = client.ReportProtectedServices(context.Background(), testNamespace, []graphqlclient.ProtectedServiceInput{{Name: "server"}})
+	s.Require().NoError(err)
+	err = client.ReportIntentsOperatorConfiguration(context.Background(), graphqlclient.IntentsOperatorConfigurationInput{GlobalEnforcementEnabled: true})
+	s.Require().NoError(err)
+
+	reports := s.readFileReports(path)
+	s.Require().Len(reports, 2)
+	s.Require().Equal(ReportTypeProtectedServices, reports[0].Type)
+	s.Require().Equal(testNamespace, reports[0].Namespace)
+	s.Require().Equal(ReportTypeIntentsOperatorConfiguration, reports[1].Type)
+	s.Require().Empty(reports[1].Namespace)
+}
+
+func (s *ReportSinksTestSuite) TestWebhookSink() {
+	received := make(chan Report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Report{}
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&report))
+		received <- report
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	viper.Set(operatorconfig.ReportingSinksKey, ReportingSinkWebhook)
+	viper.Set(operatorconfig.ReportingWebhookURLKey, server.URL)
+	client, err := NewClientWithReportingSinks(nil, nil, testNamespace)
+	s.Require().NoError(err)
+
+	err = client.ReportNetworkPolicies(context.Background(), testNamespace, []graphqlclient.NetworkPolicyInput{{Namespace: testNamespace, Name: "policy"}})
+	s.Require().NoError(err)
+	report := <-received
+	s.Require().Equal(ReportTypeNetworkPolicies, report.Type)
+	s.Require().Equal(testNamespace, report.Namespace)
+}
+
+func (s *ReportSinksTestSuite) TestWebhookSinkFailureStatus() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewSinkCloudClient(NewWebhookReportSink(server.URL, viper.GetDuration(operatorconfig.ReportingWebhookTimeoutKey)))
+	err := client.ReportNetworkPolicies(context.Background(), testNamespace, nil)
+	s.Require().Error(err)
+}
+
+func (s *ReportSinksTestSuite) TestCloudFailureDoesNotBlockSinks() {
+	path := filepath.Join(s.T().TempDir(), "reports.jsonl")
+	viper.Set(operatorconfig.ReportingSinksKey, " file ")
+	viper.Set(operatorconfig.ReportingFilePathKey, path)
+	client, err := NewClientWithReportingSinks(s.cloudClient, nil, testNamespace)
+	s.Require().NoError(err)
+
+	services := []graphqlclient.ProtectedServiceInput{{Name: "server"}}
+	s.cloudClient.EXPECT().ReportProtectedServices(gomock.Any(), testNamespace, services).Return(errors.New("unavailable"))
+
+	err = client.ReportProtectedServices(context.Background(), testNamespace, services)
+	s.Require().Error(err)
+	s.Require().Len(s.readFileReports(path), 1)
+}
+
+func (s *ReportSinksTestSuite) TestDatabaseIntentsGoToCloudOnly() {
+	viper.Set(operatorconfig.ReportingSinksKey, ReportingSinkFile)
+	viper.Set(operatorconfig.ReportingFilePathKey, filepath.Join(s.T().TempDir(), "reports.jsonl"))
+
+	client, err := NewClientWithReportingSinks(s.cloudClient, nil, testNamespace)
+	s.Require().NoError(err)
+	s.cloudClient.EXPECT().ApplyDatabaseIntent(gomock.Any(), gomock.Any(), graphqlclient.DBPermissionChangeApply).Return(nil)
+	s.Require().NoError(client.ApplyDatabaseIntent(context.Background(), nil, graphqlclient.DBPermissionChangeApply))
+
+	client, err = NewClientWithReportingSinks(nil, nil, testNamespace)
+	s.Require().NoError(err)
+	s.Require().ErrorIs(client.ApplyDatabaseIntent(context.Background(), nil, graphqlclient.DBPermissionChangeApply), ErrDatabaseIntentsRequireCloud)
+}
+
+func (s *ReportSinksTestSuite) TestNoSinksConfigured() {
+	client, err := NewClientWithReportingSinks(nil, nil, testNamespace)
+	s.Require().NoError(err)
+	s.Require().Nil(client)
+
+	client, err = NewClientWithReportingSinks(s.cloudClient, nil, testNamespace)
+	s.Require().NoError(err)
+	s.Require().Equal(s.cloudClient, client)
+}
+
+func (s *ReportSinksTestSuite) TestUnknownSink() {
+	viper.Set(operatorconfig.ReportingSinksKey, "carrier-pigeon")
+	_, err := NewClientWithReportingSinks(nil, nil, testNamespace)
+	s.Require().Error(err)
+}
+
+func TestReportSinksTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportSinksTestSuite))
+}
@@ -0,0 +1,62 @@
+package accesslabels
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+)
+
+// Enum is the scheme by which access network policies select the clients allowed to reach a server.
+type Enum string
+
+const (
+	// PerServer labels client pods with an access label for every server they have intents to, and policies select
+	// clients by it.
+	PerServer Enum = "per-server"
+	// Migrating keeps labeling client pods as PerServer does, while policies select clients both by their access
+	// labels and by their identity. It allows switching between the other schemes without dropping traffic.
+	Migrating Enum = "migrating"
+	// ClientIdentity makes policies select clients by the server identity label all pods already carry, so the
+	// labels of client pods don't grow with the number of servers they call. Access labels are removed from pods.
+	ClientIdentity Enum = "client-identity"
+)
+
+// Compile time validation for PFlag compatability
+var _ pflag.Value = (*Enum)(nil)
+
+// Set is a pointer receiver, since it shouldn't change the value of a copy
+func (e *Enum) Set(value string) error {
+	switch value {
+	case string(PerServer):
+		fallthrough
+	case string(Migrating):
+		fallthrough
+	case string(ClientIdentity):
+		*e = Enum(value)
+		return nil
+	}
+
+	return fmt.Errorf("invalid value %s for accessLabels", value)
+}
+
+func (e *Enum) Type() string {
+	return "accessLabels.Enum"
+}
+
+func (e *Enum) String() string {
+	return string(*e)
+}
+
+// LabelsPods returns whether client pods should carry access labels.
+func (e Enum) LabelsPods() bool {
+	return e != ClientIdentity
+}
+
+// SelectsByClientIdentity returns whether policies should select clients by their identity.
+func (e Enum) SelectsByClientIdentity() bool {
+	return e == Migrating || e == ClientIdentity
+}
+
+// SelectsByAccessLabel returns whether policies should select clients by their access labels.
+func (e Enum) SelectsByAccessLabel() bool {
+	return e != ClientIdentity
+}
@@ -1,6 +1,7 @@
 package operatorconfig
 
 import (
+	"github.com/otterize/intents-operator/src/shared/operatorconfig/accesslabels"
 	"github.com/otterize/intents-operator/src/shared/operatorconfig/allowexternaltraffic"
 	"github.com/otterize/intents-operator/src/shared/telemetries/telemetrysender"
 	"github.com/spf13/pflag"
@@ -24,6 +25,13 @@ const (
 	KafkaServerTLSCAKey                         = "kafka-server-tls-ca"   // name of tls ca file
 	SelfSignedCertKey                           = "self-signed-cert"      // Whether to generate and use a self signed cert as the CA for webhooks
 	SelfSignedCertDefault                       = true
+	WebhookCertManagerSecretKey                 = "webhook-cert-manager-secret" // Secret cert-manager issues the webhook certificate into. When set, it is used instead of a self-signed cert, and caBundles are left to the cert-manager CA injector
+	WebhookCASecretNameKey                      = "webhook-ca-secret-name"      // Secret that holds the CA used to issue webhook certificates when self-signed certs are used
+	WebhookCASecretNameDefault                  = "intents-operator-webhook-ca"
+	WebhookCAValidityKey                        = "webhook-ca-validity" // Validity of the webhook CA, which is renewed once a third of it remains
+	WebhookCAValidityDefault                    = 365 * 24 * time.Hour
+	WebhookCertValidityKey                      = "webhook-cert-validity" // Validity of webhook serving certificates, which are renewed once a third of it remains
+	WebhookCertValidityDefault                  = 24 * time.Hour
 	DisableWebhookServerKey                     = "disable-webhook-server" // Disable webhook validator server
 	DisableWebhookServerDefault                 = false
 	EnforcementDefaultStateKey                  = "enforcement-default-state" // Sets the default state of the enforcement. If true, always enforces. If false, can be overridden using ProtectedService.
@@ -52,6 +60,42 @@ const (
 	EKSClusterNameOverrideKey                   = "eks-cluster-name-override"
 	PrometheusMetricsPortKey                    = "metrics-port"
 	PrometheusMetricsPortDefault                = 2112
+	AuditLogFileKey                             = "audit-log-file"   // Path of a JSON-lines file to append enforcement audit records to
+	AuditLogStdoutKey                           = "audit-log-stdout" // Whether to write enforcement audit records to stdout
+	AuditLogStdoutDefault                       = false
+	AuditLogWebhookURLKey                       = "audit-log-webhook-url" // URL that enforcement audit records are POSTed to
+	AuditLogWebhookTimeoutKey                   = "audit-log-webhook-timeout"
+	AuditLogWebhookTimeoutDefault               = 5 * time.Second
+	AuditLogWebhookQueueSizeKey                 = "audit-log-webhook-queue-size" // Number of audit records waiting to be sent to the webhook before new ones are dropped
+	AuditLogWebhookQueueSizeDefault             = 1000
+	ReportingSinksKey                           = "reporting-sinks" // Comma separated list of additional sinks to report to besides Otterize Cloud: webhook, file, configmap
+	ReportingWebhookURLKey                      = "reporting-webhook-url"
+	ReportingWebhookTimeoutKey                  = "reporting-webhook-timeout"
+	ReportingWebhookTimeoutDefault              = 10 * time.Second
+	ReportingFilePathKey                        = "reporting-file-path"
+	ReportingConfigMapNameKey                   = "reporting-configmap-name"
+	ReportingConfigMapNameDefault               = "intents-operator-reports"
+	ReconcilerGroupParallelKey                  = "reconciler-group-parallel" // Whether reconcilers that don't depend on each other run concurrently
+	ReconcilerGroupParallelDefault              = true
+	ReconcilerTimeoutKey                        = "reconciler-timeout" // Time after which a single reconciler in a group is considered failed. Zero disables the timeout
+	ReconcilerTimeoutDefault                    = 2 * time.Minute
+	CircuitBreakerFailureThresholdKey           = "circuit-breaker-failure-threshold" // Consecutive failures after which a reconciler or external backend is only retried with backoff
+	CircuitBreakerFailureThresholdDefault       = 3
+	CircuitBreakerInitialBackoffKey             = "circuit-breaker-initial-backoff"
+	CircuitBreakerInitialBackoffDefault         = 5 * time.Second
+	CircuitBreakerMaxBackoffKey                 = "circuit-breaker-max-backoff"
+	CircuitBreakerMaxBackoffDefault             = 5 * time.Minute
+	KafkaACLResyncIntervalKey                   = "kafka-acl-resync-interval" // Interval at which the ACLs on each Kafka server are compared with ClientIntents and repaired. Zero disables the resync
+	KafkaACLResyncIntervalDefault               = 5 * time.Minute
+	ExternalTrafficIngressNamespaceSelectorKey  = "external-traffic-ingress-namespace-selector" // Label selector of the namespaces of ingress controllers. When it or the pod selector is set, traffic to services referenced by an Ingress is only allowed from the ingress controllers
+	ExternalTrafficIngressPodSelectorKey        = "external-traffic-ingress-pod-selector"       // Label selector of ingress controller pods
+	ExternalTrafficGatewayNamespaceKey          = "external-traffic-gateway-namespace"          // Namespace of the data-plane pods of Gateways. Empty means the namespace of each Gateway
+	ExternalTrafficGatewayNameLabelKey          = "external-traffic-gateway-name-label"         // Key of the label holding the Gateway name on its data-plane pods
+	ExternalTrafficGatewayNameLabelDefault      = "gateway.networking.k8s.io/gateway-name"
+	EnablePodLabelsWebhookKey                   = "enable-pod-labels-webhook" // Whether pods are labeled by a mutating webhook when they are created, rather than only after they start
+	EnablePodLabelsWebhookDefault               = false
+	AccessLabelsKey                             = "access-labels" // How access network policies select clients: per-server, migrating or client-identity
+	AccessLabelsDefault                         = accesslabels.PerServer
 )
 
 func init() {
@@ -64,10 +108,27 @@ func init() {
 	viper.SetDefault(EnableNetworkPolicyKey, EnableNetworkPolicyDefault)
 	viper.SetDefault(EnableKafkaACLKey, EnableKafkaACLDefault)
 	viper.SetDefault(EnableIstioPolicyKey, EnableIstioPolicyDefault)
+	viper.SetDefault(WebhookCASecretNameKey, WebhookCASecretNameDefault)
+	viper.SetDefault(WebhookCAValidityKey, WebhookCAValidityDefault)
+	viper.SetDefault(WebhookCertValidityKey, WebhookCertValidityDefault)
 	viper.SetDefault(DisableWebhookServerKey, DisableWebhookServerDefault)
 	viper.SetDefault(EnableEgressNetworkPolicyReconcilersKey, EnableEgressNetworkPolicyReconcilersDefault)
 	viper.SetDefault(EnableAWSPolicyKey, EnableAWSPolicyDefault)
 	viper.SetDefault(PrometheusMetricsPortKey, PrometheusMetricsPortDefault)
+	viper.SetDefault(AuditLogStdoutKey, AuditLogStdoutDefault)
+	viper.SetDefault(AuditLogWebhookTimeoutKey, AuditLogWebhookTimeoutDefault)
+	viper.SetDefault(AuditLogWebhookQueueSizeKey, AuditLogWebhookQueueSizeDefault)
+	viper.SetDefault(ReportingWebhookTimeoutKey, ReportingWebhookTimeoutDefault)
+	viper.SetDefault(ReportingConfigMapNameKey, ReportingConfigMapNameDefault)
+	viper.SetDefault(ReconcilerGroupParallelKey, ReconcilerGroupParallelDefault)
+	viper.SetDefault(ReconcilerTimeoutKey, ReconcilerTimeoutDefault)
+	viper.SetDefault(CircuitBreakerFailureThresholdKey, CircuitBreakerFailureThresholdDefault)
+	viper.SetDefault(CircuitBreakerInitialBackoffKey, CircuitBreakerInitialBackoffDefault)
+	viper.SetDefault(CircuitBreakerMaxBackoffKey, CircuitBreakerMaxBackoffDefault)
+	viper.SetDefault(KafkaACLResyncIntervalKey, KafkaACLResyncIntervalDefault)
+	viper.SetDefault(ExternalTrafficGatewayNameLabelKey, ExternalTrafficGatewayNameLabelDefault)
+	viper.SetDefault(EnablePodLabelsWebhookKey, EnablePodLabelsWebhookDefault)
+	viper.SetDefault(AccessLabelsKey, AccessLabelsDefault)
 	viper.SetEnvPrefix(EnvPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
@@ -6,16 +6,22 @@ import (
 )
 
 const (
-	ApiClientIdKey                 = "client-id"
-	ApiClientSecretKey             = "client-secret"
-	OtterizeAPIAddressKey          = "api-address"
-	CloudClientTimeoutKey          = "cloud-client-timeout"
-	OtterizeAPIExtraCAPEMPathsKey  = "api-extra-ca-pem"
-	CloudClientTimeoutDefault      = "30s"
-	OtterizeAPIAddressDefault      = "https://app.otterize.com/api"
-	ComponentReportIntervalKey     = "component-report-interval"
-	ComponentReportIntervalDefault = 60
-	EnvPrefix                      = "OTTERIZE"
+	ApiClientIdKey                    = "client-id"
+	ApiClientSecretKey                = "client-secret"
+	OtterizeAPIAddressKey             = "api-address"
+	CloudClientTimeoutKey             = "cloud-client-timeout"
+	OtterizeAPIExtraCAPEMPathsKey     = "api-extra-ca-pem"
+	CloudClientTimeoutDefault         = "30s"
+	OtterizeAPIAddressDefault         = "https://app.otterize.com/api"
+	ComponentReportIntervalKey        = "component-report-interval"
+	ComponentReportIntervalDefault    = 60
+	CloudReportOutboxEnabledKey       = "cloud-report-outbox-enabled" // Queue cloud reports and retry them with backoff instead of sending them inline. Failures are then only visible through the cloud_report_failures and cloud_report_queue_depth metrics
+	CloudReportOutboxEnabledDefault   = false
+	CloudReportOutboxPersistKey       = "cloud-report-outbox-persist" // Persist queued cloud reports to a ConfigMap so they survive restarts
+	CloudReportOutboxPersistDefault   = false
+	CloudReportOutboxConfigMapKey     = "cloud-report-outbox-configmap"
+	CloudReportOutboxConfigMapDefault = "intents-operator-cloud-report-outbox"
+	EnvPrefix                         = "OTTERIZE"
 )
 
 func init() {
@@ -23,6 +29,9 @@ func init() {
 	viper.SetDefault(ComponentReportIntervalKey, ComponentReportIntervalDefault)
 	viper.SetDefault(CloudClientTimeoutKey, CloudClientTimeoutDefault)
 	viper.SetDefault(OtterizeAPIExtraCAPEMPathsKey, []string{})
+	viper.SetDefault(CloudReportOutboxEnabledKey, CloudReportOutboxEnabledDefault)
+	viper.SetDefault(CloudReportOutboxPersistKey, CloudReportOutboxPersistDefault)
+	viper.SetDefault(CloudReportOutboxConfigMapKey, CloudReportOutboxConfigMapDefault)
 	viper.SetEnvPrefix(EnvPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
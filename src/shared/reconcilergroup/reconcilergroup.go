@@ -2,15 +2,26 @@ package reconcilergroup
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
+	"github.com/otterize/intents-operator/src/shared/auditlog"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
+	"github.com/otterize/intents-operator/src/shared/operatorconfig"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sync"
+	"time"
 )
 
 type ReconcilerWithEvents interface {
@@ -18,15 +29,34 @@ type ReconcilerWithEvents interface {
 	InjectRecorder(recorder record.EventRecorder)
 }
 
+// ReconcilerWithDependencies is implemented by reconcilers that must only run after other reconcilers in the group
+// have completed. Dependencies are matched by type, so a typed nil pointer is enough, e.g.
+// (*intents_reconcilers.PodLabelReconciler)(nil). Dependencies that are not part of the group are ignored.
+type ReconcilerWithDependencies interface {
+	DependsOn() []ReconcilerWithEvents
+}
+
+// ReconcilerWithTimeout is implemented by reconcilers that need a timeout other than the group default.
+// A non-positive timeout disables the timeout for the reconciler.
+type ReconcilerWithTimeout interface {
+	ReconcileTimeout() time.Duration
+}
+
 type Group struct {
-	reconcilers      []ReconcilerWithEvents
-	name             string
-	client           client.Client
-	scheme           *runtime.Scheme
-	recorder         record.EventRecorder
-	baseObject       client.Object
-	finalizer        string
-	legacyFinalizers []string
+	reconcilers       []ReconcilerWithEvents
+	name              string
+	client            client.Client
+	scheme            *runtime.Scheme
+	recorder          record.EventRecorder
+	baseObject        client.Object
+	finalizer         string
+	legacyFinalizers  []string
+	parallel          bool
+	reconcilerTimeout time.Duration
+	breakers          *circuitbreaker.Registry
+	retryLock         sync.Mutex
+	scheduledRetries  map[circuitbreaker.Key]*time.Timer
+	recovered         chan event.GenericEvent
 }
 
 func NewGroup(
@@ -39,13 +69,17 @@ func NewGroup(
 	reconcilers ...ReconcilerWithEvents,
 ) *Group {
 	return &Group{
-		reconcilers:      reconcilers,
-		name:             name,
-		client:           client,
-		scheme:           scheme,
-		baseObject:       resourceObject,
-		finalizer:        finalizer,
-		legacyFinalizers: legacyFinalizers,
+		reconcilers:       reconcilers,
+		name:              name,
+		client:            client,
+		scheme:            scheme,
+		baseObject:        resourceObject,
+		finalizer:         finalizer,
+		legacyFinalizers:  legacyFinalizers,
+		parallel:          viper.GetBool(operatorconfig.ReconcilerGroupParallelKey),
+		reconcilerTimeout: viper.GetDuration(operatorconfig.ReconcilerTimeoutKey),
+		breakers:          circuitbreaker.GlobalRegistry(),
+		scheduledRetries:  make(map[circuitbreaker.Key]*time.Timer),
 	}
 }
 
@@ -54,8 +88,6 @@ func (g *Group) AddToGroup(reconciler ReconcilerWithEvents) {
 }
 
 func (g *Group) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	var finalErr error
-	var finalRes ctrl.Result
 	logrus.Infof("## Starting reconciliation group cycle for %s", g.name)
 
 	resourceObject := g.baseObject.DeepCopyObject().(client.Object)
@@ -84,7 +116,12 @@ func (g *Group) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, e
 		return ctrl.Result{}, err
 	}
 
-	finalRes, finalErr = g.runGroup(ctx, req, finalErr, finalRes)
+	ctx = auditlog.ContextWithTrigger(ctx, auditlog.Trigger{
+		Kind:      reflect.TypeOf(g.baseObject).Elem().Name(),
+		Name:      req.Name,
+		Namespace: req.Namespace,
+	})
+	finalRes, finalErr := g.runGroup(ctx, req)
 
 	objectBeingDeleted := resourceObject.GetDeletionTimestamp() != nil
 	if objectBeingDeleted && finalErr == nil && finalRes.IsZero() {
@@ -141,23 +178,258 @@ func (g *Group) removeFinalizer(ctx context.Context, resource client.Object) err
 	return nil
 }
 
-func (g *Group) runGroup(ctx context.Context, req ctrl.Request, finalErr error, finalRes ctrl.Result) (ctrl.Result, error) {
-	for _, reconciler := range g.reconcilers {
-		logrus.Infof("Starting cycle for %T", reconciler)
-		res, err := reconciler.Reconcile(ctx, req)
-		if err != nil {
-			if finalErr == nil {
-				finalErr = err
+// runGroup runs every reconciler once its dependencies have completed, so that independent reconcilers run
+// concurrently. Results are aggregated in the order the reconcilers were added to the group: the shortest requeue
+// wins, and all errors are returned. When every error comes from an open circuit breaker, the error is terminal.
+func (g *Group) runGroup(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	dependencies := g.dependencies()
+	done := make([]chan struct{}, len(g.reconcilers))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	results := make([]ctrl.Result, len(g.reconcilers))
+	errs := make([]error, len(g.reconcilers))
+
+	wg := sync.WaitGroup{}
+	for i, reconciler := range g.reconcilers {
+		wg.Add(1)
+		go func(i int, reconciler ReconcilerWithEvents) {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dependency := range dependencies[i] {
+				<-done[dependency]
 			}
-			logrus.Errorf("Error in reconciler %T: %s", reconciler, err)
+			logrus.Infof("Starting cycle for %T", reconciler)
+			results[i], errs[i] = g.runReconcilerWithBreaker(ctx, req, reconciler)
+		}(i, reconciler)
+	}
+	wg.Wait()
+
+	var finalRes ctrl.Result
+	finalErrs := make([]error, 0)
+	onlyOpenBreakers := true
+	for i, reconciler := range g.reconcilers {
+		if errs[i] != nil {
+			finalErrs = append(finalErrs, errs[i])
+			logrus.Errorf("Error in reconciler %T: %s", reconciler, errs[i])
+			onlyOpenBreakers = onlyOpenBreakers && isOpenBreaker(errs[i])
 		}
-		if !res.IsZero() {
-			finalRes = shortestRequeue(res, finalRes)
+		if !results[i].IsZero() {
+			finalRes = shortestRequeue(results[i], finalRes)
 		}
 	}
+
+	if len(finalErrs) == 0 {
+		return finalRes, nil
+	}
+	finalErr := finalErrs[0]
+	if len(finalErrs) > 1 {
+		finalErr = goerrors.Join(finalErrs...)
+	}
+	if onlyOpenBreakers {
+		// The failed reconcilers are retried on their own once their breakers allow it, so the resource must not be
+		// requeued - that would run every other reconciler in the group again.
+		return finalRes, reconcile.TerminalError(finalErr)
+	}
 	return finalRes, finalErr
 }
 
+func isOpenBreaker(err error) bool {
+	var openErr *circuitbreaker.OpenError
+	return goerrors.As(err, &openErr)
+}
+
+// runReconcilerWithBreaker tracks failures per reconciler and resource. Once the reconciler keeps failing for a
+// resource its breaker opens, and it is skipped until it is retried on its own - see scheduleRetry. The same goes for
+// reconcilers that fail because a breaker of their own, such as one per Kafka server, is open.
+func (g *Group) runReconcilerWithBreaker(ctx context.Context, req ctrl.Request, reconciler ReconcilerWithEvents) (ctrl.Result, error) {
+	var res ctrl.Result
+	err := g.breakers.Call(g.breakerKey(reconciler, req), func() error {
+		var err error
+		res, err = g.runReconciler(ctx, req, reconciler)
+		return err
+	})
+	var openErr *circuitbreaker.OpenError
+	if goerrors.As(err, &openErr) {
+		g.scheduleRetry(ctx, req, reconciler, openErr.RetryAfter)
+	}
+	return res, err
+}
+
+// scheduleRetry runs only the given reconciler again once retryAfter elapses, rather than requeueing the resource.
+// ctx is the context of the controller's reconcile loop, which lives as long as the controller does. Once the
+// reconciler succeeds, the resource is reconciled as a whole through RecoveredSource, so that its status is updated
+// and its finalizer may be removed.
+func (g *Group) scheduleRetry(ctx context.Context, req ctrl.Request, reconciler ReconcilerWithEvents, retryAfter time.Duration) {
+	key := g.breakerKey(reconciler, req)
+	g.retryLock.Lock()
+	defer g.retryLock.Unlock()
+	if _, scheduled := g.scheduledRetries[key]; scheduled {
+		return
+	}
+
+	g.scheduledRetries[key] = time.AfterFunc(retryAfter, func() {
+		g.retryLock.Lock()
+		delete(g.scheduledRetries, key)
+		g.retryLock.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+
+		logrus.Infof("Retrying %T for %s", reconciler, req.NamespacedName)
+		if _, err := g.runReconcilerWithBreaker(ctx, req, reconciler); err != nil {
+			logrus.WithError(err).Errorf("Retry of %T for %s failed", reconciler, req.NamespacedName)
+			return
+		}
+		g.notifyRecovered(ctx, req)
+	})
+}
+
+// RecoveredSource returns a source of the resources whose failed reconcilers succeeded when retried on their own.
+// Controllers running the group should watch it, so that these resources are reconciled once more as a whole.
+func (g *Group) RecoveredSource() source.Source {
+	g.retryLock.Lock()
+	defer g.retryLock.Unlock()
+	if g.recovered == nil {
+		g.recovered = make(chan event.GenericEvent)
+	}
+	return &source.Channel{Source: g.recovered}
+}
+
+func (g *Group) notifyRecovered(ctx context.Context, req ctrl.Request) {
+	g.retryLock.Lock()
+	recovered := g.recovered
+	g.retryLock.Unlock()
+	if recovered == nil {
+		return
+	}
+
+	resourceObject := g.baseObject.DeepCopyObject().(client.Object)
+	resourceObject.SetName(req.Name)
+	resourceObject.SetNamespace(req.Namespace)
+	select {
+	case recovered <- event.GenericEvent{Object: resourceObject}:
+	case <-ctx.Done():
+	}
+}
+
+func (g *Group) breakerKey(reconciler ReconcilerWithEvents, req ctrl.Request) circuitbreaker.Key {
+	return circuitbreaker.Key{Component: fmt.Sprintf("%s/%s", g.name, reconcilerName(reconciler)), Target: req.NamespacedName.String()}
+}
+
+func reconcilerName(reconciler ReconcilerWithEvents) string {
+	reconcilerType := reflect.TypeOf(reconciler)
+	if reconcilerType.Kind() == reflect.Ptr {
+		reconcilerType = reconcilerType.Elem()
+	}
+	return reconcilerType.Name()
+}
+
+// OpenCircuitBreakers returns the names of the reconcilers in the group that are currently being skipped for req
+// because they kept failing.
+func (g *Group) OpenCircuitBreakers(req ctrl.Request) []string {
+	names := make([]string, 0)
+	for _, reconciler := range g.reconcilers {
+		if g.breakers.IsOpen(g.breakerKey(reconciler, req)) {
+			names = append(names, reconcilerName(reconciler))
+		}
+	}
+	return names
+}
+
+// runReconciler cancels the context of the reconciler once its timeout expires. Reconcilers are expected to respect
+// context cancellation, but some call APIs without a context, so the reconciler is still waited for: it must not keep
+// running in the background while the resource is reconciled again.
+func (g *Group) runReconciler(ctx context.Context, req ctrl.Request, reconciler ReconcilerWithEvents) (ctrl.Result, error) {
+	timeout := g.reconcilerTimeout
+	if reconcilerWithTimeout, ok := reconciler.(ReconcilerWithTimeout); ok {
+		timeout = reconcilerWithTimeout.ReconcileTimeout()
+	}
+	if timeout <= 0 {
+		return reconciler.Reconcile(ctx, req)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := reconciler.Reconcile(timeoutCtx, req)
+	if timeoutCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return ctrl.Result{}, goerrors.Join(errors.Wrapf(timeoutCtx.Err(), "reconciler %T did not complete within %s", reconciler, timeout), err)
+	}
+	return res, err
+}
+
+// dependencies returns, for each reconciler, the indices of the reconcilers it has to wait for. When parallel
+// execution is disabled, or the declared dependencies contain a cycle, reconcilers run one after the other in the
+// order they were added.
+func (g *Group) dependencies() [][]int {
+	sequential := func() [][]int {
+		dependencies := make([][]int, len(g.reconcilers))
+		for i := 1; i < len(g.reconcilers); i++ {
+			dependencies[i] = []int{i - 1}
+		}
+		return dependencies
+	}
+
+	if !g.parallel {
+		return sequential()
+	}
+
+	dependencies := make([][]int, len(g.reconcilers))
+	for i, reconciler := range g.reconcilers {
+		reconcilerWithDependencies, ok := reconciler.(ReconcilerWithDependencies)
+		if !ok {
+			continue
+		}
+		for _, dependency := range reconcilerWithDependencies.DependsOn() {
+			for j, candidate := range g.reconcilers {
+				if i != j && reflect.TypeOf(candidate) == reflect.TypeOf(dependency) {
+					dependencies[i] = append(dependencies[i], j)
+				}
+			}
+		}
+	}
+
+	if hasCycle(dependencies) {
+		logrus.Errorf("Reconciler dependencies in group %s contain a cycle, running reconcilers sequentially", g.name)
+		return sequential()
+	}
+	return dependencies
+}
+
+func hasCycle(dependencies [][]int) bool {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(dependencies))
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch state[i] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[i] = visiting
+		for _, dependency := range dependencies[i] {
+			if visit(dependency) {
+				return true
+			}
+		}
+		state[i] = visited
+		return false
+	}
+
+	for i := range dependencies {
+		if visit(i) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Group) InjectRecorder(recorder record.EventRecorder) {
 	g.recorder = recorder
 	for _, reconciler := range g.reconcilers {
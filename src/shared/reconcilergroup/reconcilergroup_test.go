@@ -6,6 +6,7 @@ import (
 	"fmt"
 	otterizev1alpha2 "github.com/otterize/intents-operator/src/operator/api/v1alpha2"
 	mocks "github.com/otterize/intents-operator/src/operator/controllers/intents_reconcilers/mocks"
+	"github.com/otterize/intents-operator/src/shared/circuitbreaker"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sync"
 	"testing"
 	"time"
 )
@@ -41,6 +43,7 @@ func (s *ReconcilerGroupTestSuite) SetupTest() {
 		testFinalizer,
 		nil,
 	)
+	s.group.breakers = circuitbreaker.NewRegistry(circuitbreaker.DefaultFailureThreshold, time.Minute, time.Hour)
 }
 
 type TestReconciler struct {
@@ -381,6 +384,248 @@ func (s *ReconcilerGroupTestSuite) TestFinalizerNotDeletedIfReconcilerRequeue()
 	s.Require().True(reconciler.Reconciled)
 }
 
+type blockingReconciler struct {
+	started chan struct{}
+	other   *blockingReconciler
+}
+
+// Reconcile only completes once the other reconciler has started, which can only happen if both run concurrently.
+func (b *blockingReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	close(b.started)
+	select {
+	case <-b.other.started:
+		return reconcile.Result{}, nil
+	case <-ctx.Done():
+		return reconcile.Result{}, ctx.Err()
+	}
+}
+
+func (b *blockingReconciler) InjectRecorder(_ record.EventRecorder) {}
+
+type dependencyReconciler struct {
+	finished chan struct{}
+}
+
+func (d *dependencyReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	time.Sleep(10 * time.Millisecond)
+	close(d.finished)
+	return reconcile.Result{}, nil
+}
+
+func (d *dependencyReconciler) InjectRecorder(_ record.EventRecorder) {}
+
+type dependentReconciler struct {
+	dependency        *dependencyReconciler
+	dependencyWasDone bool
+}
+
+func (d *dependentReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	select {
+	case <-d.dependency.finished:
+		d.dependencyWasDone = true
+	default:
+	}
+	return reconcile.Result{}, nil
+}
+
+func (d *dependentReconciler) InjectRecorder(_ record.EventRecorder) {}
+
+func (d *dependentReconciler) DependsOn() []ReconcilerWithEvents {
+	return []ReconcilerWithEvents{(*dependencyReconciler)(nil)}
+}
+
+func (s *ReconcilerGroupTestSuite) TestIndependentReconcilersRunConcurrently() {
+	first := &blockingReconciler{started: make(chan struct{})}
+	second := &blockingReconciler{started: make(chan struct{}), other: first}
+	first.other = second
+	s.group.AddToGroup(first)
+	s.group.AddToGroup(second)
+	s.group.reconcilerTimeout = time.Second
+
+	s.ExpectIntentWithFinalizer()
+
+	res, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().NoError(err)
+	s.Require().True(res.IsZero())
+}
+
+func (s *ReconcilerGroupTestSuite) TestDependentRunsAfterDependency() {
+	dependency := &dependencyReconciler{finished: make(chan struct{})}
+	dependent := &dependentReconciler{dependency: dependency}
+	// Added before its dependency on purpose - order of addition should not matter.
+	s.group.AddToGroup(dependent)
+	s.group.AddToGroup(dependency)
+
+	s.ExpectIntentWithFinalizer()
+
+	_, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().NoError(err)
+	s.Require().True(dependent.dependencyWasDone)
+}
+
+func (s *ReconcilerGroupTestSuite) TestSequentialWhenParallelDisabled() {
+	s.group.parallel = false
+	s.group.AddToGroup(&TestReconciler{})
+	s.group.AddToGroup(&TestReconciler{})
+	s.group.AddToGroup(&TestReconciler{})
+
+	s.Require().Equal([][]int{nil, {0}, {1}}, s.group.dependencies())
+}
+
+func (s *ReconcilerGroupTestSuite) TestReconcilerTimeout() {
+	first := &blockingReconciler{started: make(chan struct{})}
+	// The other reconciler never starts, so the first one blocks until it times out.
+	first.other = &blockingReconciler{started: make(chan struct{})}
+	happyReconciler := &TestReconciler{}
+	s.group.AddToGroup(first)
+	s.group.AddToGroup(happyReconciler)
+	s.group.reconcilerTimeout = 10 * time.Millisecond
+
+	s.ExpectIntentWithFinalizer()
+
+	_, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().ErrorIs(err, context.DeadlineExceeded)
+	s.Require().True(happyReconciler.Reconciled)
+}
+
+type contextIgnoringReconciler struct {
+	finished bool
+}
+
+func (c *contextIgnoringReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	time.Sleep(50 * time.Millisecond)
+	c.finished = true
+	return reconcile.Result{}, nil
+}
+
+func (c *contextIgnoringReconciler) InjectRecorder(_ record.EventRecorder) {}
+
+func (s *ReconcilerGroupTestSuite) TestTimedOutReconcilerDoesNotKeepRunning() {
+	slowReconciler := &contextIgnoringReconciler{}
+	s.group.AddToGroup(slowReconciler)
+	s.group.reconcilerTimeout = 10 * time.Millisecond
+
+	s.ExpectIntentWithFinalizer()
+
+	_, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().ErrorIs(err, context.DeadlineExceeded)
+	s.Require().True(slowReconciler.finished)
+}
+
+func (s *ReconcilerGroupTestSuite) TestAllErrorsAreReturned() {
+	firstErr := errors.New("first error")
+	secondErr := errors.New("second error")
+	s.group.AddToGroup(&TestReconciler{Err: firstErr})
+	s.group.AddToGroup(&TestReconciler{Err: secondErr, Result: reconcile.Result{RequeueAfter: 5}})
+
+	s.ExpectIntentWithFinalizer()
+
+	res, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().ErrorIs(err, firstErr)
+	s.Require().ErrorIs(err, secondErr)
+	s.Require().Equal(reconcile.Result{RequeueAfter: 5}, res)
+}
+
+func (s *ReconcilerGroupTestSuite) TestOpenCircuitBreakerSkipsOnlyFailingReconciler() {
+	failingReconciler := &TestReconciler{Err: errors.New("kafka unavailable")}
+	happyReconciler := &dependencyReconciler{finished: make(chan struct{})}
+	s.group.AddToGroup(failingReconciler)
+	s.group.AddToGroup(happyReconciler)
+
+	for i := 0; i < circuitbreaker.DefaultFailureThreshold-1; i++ {
+		happyReconciler.finished = make(chan struct{})
+		s.ExpectIntentWithFinalizer()
+		_, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+		s.Require().Error(err)
+	}
+
+	// The breaker opens - the error is terminal, since the failing reconciler is retried on its own.
+	happyReconciler.finished = make(chan struct{})
+	s.ExpectIntentWithFinalizer()
+	res, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	var openErr *circuitbreaker.OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Require().ErrorIs(err, reconcile.TerminalError(nil))
+	s.Require().ErrorContains(err, "kafka unavailable")
+	s.Require().True(res.IsZero())
+	s.Require().Equal([]string{"TestReconciler"}, s.group.OpenCircuitBreakers(reconcile.Request{}))
+
+	// While open, the failing reconciler is skipped but the others still run.
+	failingReconciler.Reconciled = false
+	happyReconciler.finished = make(chan struct{})
+	s.ExpectIntentWithFinalizer()
+	_, err = s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().ErrorIs(err, reconcile.TerminalError(nil))
+	s.Require().False(failingReconciler.Reconciled)
+	s.Require().Eventually(func() bool {
+		select {
+		case <-happyReconciler.finished:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *ReconcilerGroupTestSuite) TestOnlyFailingReconcilerIsRetried() {
+	s.group.breakers = circuitbreaker.NewRegistry(1, 10*time.Millisecond, 10*time.Millisecond)
+	failingReconciler := &countingReconciler{err: errors.New("kafka unavailable")}
+	happyReconciler := &TestReconciler{}
+	s.group.AddToGroup(failingReconciler)
+	s.group.AddToGroup(happyReconciler)
+	recovered := s.group.RecoveredSource()
+	s.Require().NotNil(recovered)
+
+	s.ExpectIntentWithFinalizer()
+	_, err := s.group.Reconcile(context.Background(), reconcile.Request{})
+	s.Require().ErrorIs(err, reconcile.TerminalError(nil))
+	s.Require().True(happyReconciler.Reconciled)
+
+	happyReconciler.Reconciled = false
+	failingReconciler.setErr(nil)
+	select {
+	case recoveredEvent := <-s.group.recovered:
+		s.Require().IsType(&otterizev1alpha2.ClientIntents{}, recoveredEvent.Object)
+	case <-time.After(time.Second):
+		s.Fail("failing reconciler was not retried")
+	}
+	s.Require().GreaterOrEqual(failingReconciler.count(), 2)
+	s.Require().False(happyReconciler.Reconciled)
+	s.Require().Empty(s.group.OpenCircuitBreakers(reconcile.Request{}))
+}
+
+type countingReconciler struct {
+	lock       sync.Mutex
+	reconciles int
+	err        error
+}
+
+func (c *countingReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.reconciles++
+	return reconcile.Result{}, c.err
+}
+
+func (c *countingReconciler) setErr(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.err = err
+}
+
+func (c *countingReconciler) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.reconciles
+}
+
+func (c *countingReconciler) InjectRecorder(_ record.EventRecorder) {}
+
+func (s *ReconcilerGroupTestSuite) TestCycleDetection() {
+	s.Require().False(hasCycle([][]int{nil, {0}, {0, 1}}))
+	s.Require().True(hasCycle([][]int{{2}, {0}, {1}}))
+}
+
 func TestReconcilerGroup(t *testing.T) {
 	suite.Run(t, new(ReconcilerGroupTestSuite))
 }
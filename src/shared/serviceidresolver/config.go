@@ -8,11 +8,23 @@ import (
 const (
 	serviceNameOverrideAnnotationKey        = "service-name-override-annotation"
 	serviceNameOverrideAnnotationKeyDefault = "intents.otterize.com/service-name"
+	serviceIdentityStrategiesKey            = "service-identity-strategies" // Ordered strategies used to resolve pods to service identities: label, annotation, owner and service-account
+	serviceIdentityLabelKey                 = "service-identity-label"      // Pod label used as the service name by the label strategy
+	serviceIdentityLabelDefault             = "app.kubernetes.io/name"
+	serviceIdentityOwnerKindsKey            = "service-identity-owner-kinds" // Owner kinds at which the owner strategy stops walking up owner references, rather than at the root owner
+	serviceIdentityCacheSizeKey             = "service-identity-cache-size"  // Number of resolved pod owners kept in the owner cache
+	serviceIdentityCacheSizeDefault         = 10000
 	EnvPrefix                               = "OTTERIZE"
 )
 
+var serviceIdentityStrategiesDefault = []string{string(StrategyAnnotation), string(StrategyOwner)}
+
 func init() {
 	viper.SetDefault(serviceNameOverrideAnnotationKey, serviceNameOverrideAnnotationKeyDefault)
+	viper.SetDefault(serviceIdentityStrategiesKey, serviceIdentityStrategiesDefault)
+	viper.SetDefault(serviceIdentityLabelKey, serviceIdentityLabelDefault)
+	viper.SetDefault(serviceIdentityOwnerKindsKey, []string{})
+	viper.SetDefault(serviceIdentityCacheSizeKey, serviceIdentityCacheSizeDefault)
 	viper.SetEnvPrefix(EnvPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
@@ -0,0 +1,191 @@
+package serviceidresolver
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/lru"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sync"
+)
+
+// metadataOwnerKinds are the owner kinds that are read as metadata only. The manager's client serves them from
+// metadata-only informers, rather than querying the API server for every owner hop of every pod.
+var metadataOwnerKinds = sets.New(
+	schema.GroupKind{Group: "apps", Kind: "ReplicaSet"},
+	schema.GroupKind{Group: "apps", Kind: "Deployment"},
+	schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+	schema.GroupKind{Group: "apps", Kind: "DaemonSet"},
+)
+
+// ownerCache maps the UID of the direct owner of pods to the owner object they resolve to, so that the pods of a
+// ReplicaSet are resolved once rather than on every pod event. Entries remember the owners their chain went through, so
+// that a change to one of them only invalidates the entries that depend on it.
+type ownerCache struct {
+	lock   sync.Mutex
+	owners *lru.Cache
+	// dependents maps the UID of every owner in a cached chain to the direct owners whose entries go through it.
+	dependents map[types.UID]sets.Set[types.UID]
+}
+
+type cachedOwner struct {
+	owner client.Object
+	chain []types.UID
+}
+
+// cachedOwners is nil until InitOwnerCache is called, since entries are only invalidated by the informers of the
+// manager.
+var cachedOwners *ownerCache
+
+// InitOwnerCache enables caching of resolved owners, and invalidates cached owners when the metadata-only informers of
+// the manager report that an owner changed.
+func InitOwnerCache(ctx context.Context, mgr manager.Manager) error {
+	handler := ownerCacheEventHandler()
+	for _, groupKind := range metadataOwnerKinds.UnsortedList() {
+		informer, err := mgr.GetCache().GetInformer(ctx, newMetadataObject(groupKind.WithVersion("v1")))
+		if err != nil {
+			return err
+		}
+		_, err = informer.AddEventHandler(handler)
+		if err != nil {
+			return err
+		}
+	}
+	enableOwnerCache(viper.GetInt(serviceIdentityCacheSizeKey))
+	return nil
+}
+
+func enableOwnerCache(size int) {
+	cachedOwners = newOwnerCache(size)
+}
+
+func newOwnerCache(size int) *ownerCache {
+	c := &ownerCache{dependents: make(map[types.UID]sets.Set[types.UID])}
+	// Evictions happen within calls to the LRU, which are made while holding the lock of the cache.
+	c.owners = lru.NewWithEvictionFunc(size, func(key lru.Key, value interface{}) {
+		c.unindexLocked(key.(types.UID), value.(cachedOwner).chain)
+	})
+	return c
+}
+
+func ownerCacheEventHandler() toolscache.ResourceEventHandlerFuncs {
+	return toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldOwner, err := meta.Accessor(oldObj)
+			if err != nil {
+				return
+			}
+			newOwner, err := meta.Accessor(newObj)
+			if err != nil {
+				return
+			}
+			// The name of an owner can't change, only the owners it is chained to.
+			if !reflect.DeepEqual(oldOwner.GetOwnerReferences(), newOwner.GetOwnerReferences()) {
+				logrus.WithFields(logrus.Fields{"owner": newOwner.GetName(), "namespace": newOwner.GetNamespace()}).Debug("owner references changed, invalidating cached owners")
+				invalidateCachedOwner(newOwner.GetUID())
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			owner, err := meta.Accessor(obj)
+			if err != nil {
+				return
+			}
+			invalidateCachedOwner(owner.GetUID())
+		},
+	}
+}
+
+func getCachedOwner(uid types.UID) (client.Object, bool) {
+	if cachedOwners == nil || uid == "" {
+		return nil, false
+	}
+	return cachedOwners.get(uid)
+}
+
+// cacheOwner caches the owner that pods of the direct owner resolve to. chain holds the UIDs of the owners they were
+// resolved through, starting with the direct owner.
+func cacheOwner(uid types.UID, chain []types.UID, owner client.Object) {
+	if cachedOwners == nil || uid == "" {
+		return
+	}
+	cachedOwners.add(uid, chain, owner)
+}
+
+// invalidateCachedOwner drops the cached owners whose chain goes through the owner.
+func invalidateCachedOwner(uid types.UID) {
+	if cachedOwners != nil {
+		cachedOwners.invalidate(uid)
+	}
+}
+
+func (c *ownerCache) get(uid types.UID) (client.Object, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached, ok := c.owners.Get(uid)
+	if !ok {
+		return nil, false
+	}
+	return cached.(cachedOwner).owner.DeepCopyObject().(client.Object), true
+}
+
+func (c *ownerCache) add(uid types.UID, chain []types.UID, owner client.Object) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// Replacing an entry doesn't evict it, so the chain it was indexed by is dropped first.
+	c.owners.Remove(uid)
+	for _, ownerUID := range chain {
+		if c.dependents[ownerUID] == nil {
+			c.dependents[ownerUID] = sets.New[types.UID]()
+		}
+		c.dependents[ownerUID].Insert(uid)
+	}
+	c.owners.Add(uid, cachedOwner{owner: owner.DeepCopyObject().(client.Object), chain: chain})
+}
+
+func (c *ownerCache) invalidate(uid types.UID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, dependent := range c.dependents[uid].UnsortedList() {
+		c.owners.Remove(dependent)
+	}
+}
+
+func (c *ownerCache) unindexLocked(uid types.UID, chain []types.UID) {
+	for _, ownerUID := range chain {
+		c.dependents[ownerUID].Delete(uid)
+		if c.dependents[ownerUID].Len() == 0 {
+			delete(c.dependents, ownerUID)
+		}
+	}
+}
+
+func newMetadataObject(gvk schema.GroupVersionKind) *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// newOwnerObject returns an empty object to read an owner into. Owners of common kinds are read as metadata only,
+// and other owners are read as unstructured objects. Only changes to owners read as metadata invalidate the cache.
+func newOwnerObject(owner metav1.OwnerReference) (client.Object, bool) {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err == nil && metadataOwnerKinds.Has(schema.GroupKind{Group: gv.Group, Kind: owner.Kind}) {
+		return newMetadataObject(gv.WithKind(owner.Kind)), true
+	}
+	ownerObj := &unstructured.Unstructured{}
+	ownerObj.SetAPIVersion(owner.APIVersion)
+	ownerObj.SetKind(owner.Kind)
+	return ownerObj, false
+}
@@ -0,0 +1,103 @@
+package serviceidresolver
+
+import (
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"testing"
+)
+
+type OwnerCacheTestSuite struct {
+	suite.Suite
+}
+
+func (s *OwnerCacheTestSuite) SetupTest() {
+	enableOwnerCache(2)
+}
+
+func (s *OwnerCacheTestSuite) TearDownTest() {
+	cachedOwners = nil
+}
+
+func (s *OwnerCacheTestSuite) deployment(name string) *metav1.PartialObjectMetadata {
+	deployment := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	deployment.SetName(name)
+	deployment.SetUID(types.UID(name + "-uid"))
+	return deployment
+}
+
+// cacheReplicaSetOfDeployment caches the deployment as the owner pods of the ReplicaSet resolve to.
+func (s *OwnerCacheTestSuite) cacheReplicaSetOfDeployment(replicaSetUID types.UID, deployment *metav1.PartialObjectMetadata) {
+	cacheOwner(replicaSetUID, []types.UID{replicaSetUID, deployment.GetUID()}, deployment)
+}
+
+func (s *OwnerCacheTestSuite) requireCached(replicaSetUID types.UID, deploymentName string) {
+	owner, ok := getCachedOwner(replicaSetUID)
+	s.Require().True(ok)
+	s.Require().Equal(deploymentName, owner.GetName())
+}
+
+func (s *OwnerCacheTestSuite) requireNotCached(replicaSetUID types.UID) {
+	_, ok := getCachedOwner(replicaSetUID)
+	s.Require().False(ok)
+}
+
+func (s *OwnerCacheTestSuite) TestHitReturnsCopy() {
+	s.cacheReplicaSetOfDeployment("replicaset-uid", s.deployment("cool-deployment"))
+
+	owner, ok := getCachedOwner("replicaset-uid")
+	s.Require().True(ok)
+	s.Require().Equal("cool-deployment", owner.GetName())
+
+	// Callers may modify the owner they got without affecting the cache.
+	owner.SetName("modified")
+	s.requireCached("replicaset-uid", "cool-deployment")
+	s.requireNotCached("other-uid")
+}
+
+func (s *OwnerCacheTestSuite) TestLeastRecentlyUsedIsEvicted() {
+	s.cacheReplicaSetOfDeployment("first-replicaset-uid", s.deployment("first"))
+	s.cacheReplicaSetOfDeployment("second-replicaset-uid", s.deployment("second"))
+	s.requireCached("first-replicaset-uid", "first")
+
+	s.cacheReplicaSetOfDeployment("third-replicaset-uid", s.deployment("third"))
+
+	s.requireNotCached("second-replicaset-uid")
+	s.requireCached("first-replicaset-uid", "first")
+	s.requireCached("third-replicaset-uid", "third")
+	// The chain of the evicted entry is no longer tracked.
+	s.Require().NotContains(cachedOwners.dependents, types.UID("second-replicaset-uid"))
+	s.Require().NotContains(cachedOwners.dependents, types.UID("second-uid"))
+}
+
+func (s *OwnerCacheTestSuite) TestChangedOwnerOnlyInvalidatesEntriesThroughIt() {
+	s.cacheReplicaSetOfDeployment("cool-replicaset-uid", s.deployment("cool"))
+	s.cacheReplicaSetOfDeployment("other-replicaset-uid", s.deployment("other"))
+
+	handler := ownerCacheEventHandler()
+	deployment := s.deployment("cool")
+	updatedDeployment := deployment.DeepCopy()
+	updatedDeployment.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Rollout", Name: "cool", APIVersion: "argoproj.io/v1alpha1", UID: "rollout-uid"}})
+	handler.OnUpdate(deployment, updatedDeployment)
+
+	s.requireNotCached("cool-replicaset-uid")
+	s.requireCached("other-replicaset-uid", "other")
+}
+
+func (s *OwnerCacheTestSuite) TestDeletedOwnerInvalidatesItsEntry() {
+	s.cacheReplicaSetOfDeployment("cool-replicaset-uid", s.deployment("cool"))
+	s.cacheReplicaSetOfDeployment("other-replicaset-uid", s.deployment("other"))
+
+	replicaSet := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"})
+	replicaSet.SetUID("cool-replicaset-uid")
+	ownerCacheEventHandler().OnDelete(toolscache.DeletedFinalStateUnknown{Obj: replicaSet})
+
+	s.requireNotCached("cool-replicaset-uid")
+	s.requireCached("other-replicaset-uid", "other")
+}
+
+func TestOwnerCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(OwnerCacheTestSuite))
+}
@@ -6,4 +6,6 @@ type ServiceIdentity struct {
 	Name string
 	// OwnerObject used to resolve the service name. May be nil if service name was resolved using annotation.
 	OwnerObject client.Object
+	// Kind of the owner object used to resolve the service name. Empty if the service name was not resolved by owner.
+	Kind string
 }
@@ -10,10 +10,10 @@ import (
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"strings"
 )
 
 var ErrPodNotFound = errors.New("pod not found")
@@ -29,11 +29,17 @@ type ServiceResolver interface {
 }
 
 type Resolver struct {
-	client client.Client
+	client     client.Client
+	strategies []IdentityStrategy
+	ownerKinds sets.Set[string]
 }
 
+// NewResolver returns a resolver that uses the configured chain of strategies, so that every component resolves a pod
+// to the same service identity.
 func NewResolver(c client.Client) *Resolver {
-	return &Resolver{client: c}
+	r := &Resolver{client: c, ownerKinds: ownerKindsFromConfig()}
+	r.strategies = newStrategiesFromConfig(r)
+	return r
 }
 
 func ResolvePodToServiceIdentityUsingAnnotationOnly(pod *corev1.Pod) (string, bool) {
@@ -53,40 +59,46 @@ func (r *Resolver) GetPodAnnotatedName(ctx context.Context, podName string, podN
 }
 
 // ResolvePodToServiceIdentity resolves a pod object to its otterize service ID, referenced in intents objects.
-// It calls GetOwnerObject to recursively iterates over the pod's owner reference hierarchy until reaching a root owner reference.
-// In case the pod is annotated with an "intents.otterize.com/service-name" annotation, that annotation's value will override
-// any owner reference name as the service name.
+// The strategies of the chain are tried in order, until one of them applies to the pod. By default, a pod annotated
+// with an "intents.otterize.com/service-name" annotation is named by it, and other pods are named after their root
+// owner, found by GetOwnerObject.
 func (r *Resolver) ResolvePodToServiceIdentity(ctx context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, error) {
-	annotatedServiceName, ok := ResolvePodToServiceIdentityUsingAnnotationOnly(pod)
-	if ok {
-		return serviceidentity.ServiceIdentity{Name: annotatedServiceName}, nil
-	}
-	ownerObj, err := r.GetOwnerObject(ctx, pod)
-	if err != nil {
-		return serviceidentity.ServiceIdentity{}, err
+	for _, strategy := range r.strategies {
+		serviceID, ok, err := strategy.Resolve(ctx, pod)
+		if err != nil {
+			return serviceidentity.ServiceIdentity{}, err
+		}
+		if ok {
+			return serviceID, nil
+		}
 	}
-
-	resourceName := ownerObj.GetName()
-	// Deployments and other resources with pod templates have a dot in their name since they follow RFC 1123 subdomain
-	// naming convention. We use the dot as a separator between the service and the namespace. We replace the dot with
-	// an underscore, which isn't a valid character in a DNS name.
-	// So, for example, a deployment named "my-deployment.5.2.0" will be seen by Otterize as "my-deployment_5_2_0"
-	otterizeServiceName := strings.ReplaceAll(resourceName, ".", "_")
-
-	return serviceidentity.ServiceIdentity{Name: otterizeServiceName, OwnerObject: ownerObj}, nil
+	// The owner strategy always applies, so this is only reached by resolvers without it.
+	return serviceidentity.ServiceIdentity{}, fmt.Errorf("no service identity strategy applies to pod %s/%s", pod.Namespace, pod.Name)
 }
 
-// GetOwnerObject recursively iterates over the pod's owner reference hierarchy until reaching a root owner reference
-// and returns it.
+// GetOwnerObject recursively iterates over the pod's owner reference hierarchy until reaching a root owner reference,
+// or an owner of one of the configured owner kinds, and returns it. Once InitOwnerCache is called, the resolved owner
+// is cached by the UID of the pod's direct owner.
 func (r *Resolver) GetOwnerObject(ctx context.Context, pod *corev1.Pod) (client.Object, error) {
 	log := logrus.WithFields(logrus.Fields{"pod": pod.Name, "namespace": pod.Namespace})
+	var directOwnerUID types.UID
+	if len(pod.GetOwnerReferences()) > 0 {
+		directOwnerUID = pod.GetOwnerReferences()[0].UID
+		if cachedOwner, ok := getCachedOwner(directOwnerUID); ok {
+			return cachedOwner, nil
+		}
+	}
+
+	// Only chains of owners read as metadata are cached, since changes to other owners aren't watched.
+	cacheable := true
+	chain := make([]types.UID, 0)
 	var obj client.Object
 	obj = pod
 	for len(obj.GetOwnerReferences()) > 0 {
 		owner := obj.GetOwnerReferences()[0]
-		ownerObj := &unstructured.Unstructured{}
-		ownerObj.SetAPIVersion(owner.APIVersion)
-		ownerObj.SetKind(owner.Kind)
+		chain = append(chain, owner.UID)
+		ownerObj, isMetadata := newOwnerObject(owner)
+		cacheable = cacheable && isMetadata
 		err := r.client.Get(ctx, types.NamespacedName{Name: owner.Name, Namespace: obj.GetNamespace()}, ownerObj)
 		if err != nil && k8serrors.IsForbidden(err) {
 			// We don't have permissions for further resolving of the owner object,
@@ -100,8 +112,18 @@ func (r *Resolver) GetOwnerObject(ctx context.Context, pod *corev1.Pod) (client.
 			return nil, fmt.Errorf("error querying owner reference: %w", err)
 		}
 
+		// Objects read from the cache may not carry their kind, which the owner strategy reports.
+		ownerObj.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(owner.APIVersion, owner.Kind))
+
 		// recurse parent owner reference
 		obj = ownerObj
+		if r.ownerKinds.Has(owner.Kind) {
+			break
+		}
+	}
+
+	if cacheable {
+		cacheOwner(directOwnerUID, chain, obj)
 	}
 
 	log.WithFields(logrus.Fields{"owner": obj.GetName(), "ownerKind": obj.GetObjectKind().GroupVersionKind()}).Debug("pod resolved to owner name")
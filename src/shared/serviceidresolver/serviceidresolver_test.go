@@ -11,9 +11,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
@@ -155,17 +155,13 @@ func (s *ServiceIdResolverTestSuite) TestDeploymentNameWithDotsReplacedByUndersc
 		},
 	}
 
-	deploymentAsObject := unstructured.Unstructured{}
+	deploymentAsObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
 	deploymentAsObject.SetName(deploymentName)
 	deploymentAsObject.SetNamespace(podNamespace)
-	deploymentAsObject.SetKind("Deployment")
-	deploymentAsObject.SetAPIVersion("apps/v1")
 
-	emptyObject := &unstructured.Unstructured{}
-	emptyObject.SetKind("Deployment")
-	emptyObject.SetAPIVersion("apps/v1")
+	emptyObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
 	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: deploymentName, Namespace: podNamespace}, emptyObject).Do(
-		func(_ context.Context, _ types.NamespacedName, obj *unstructured.Unstructured, _ ...any) error {
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...any) error {
 			deploymentAsObject.DeepCopyInto(obj)
 			return nil
 		})
@@ -195,9 +191,7 @@ func (s *ServiceIdResolverTestSuite) TestDeploymentReadForbidden() {
 		},
 	}
 
-	emptyObject := &unstructured.Unstructured{}
-	emptyObject.SetKind("Deployment")
-	emptyObject.SetAPIVersion("apps/v1")
+	emptyObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
 
 	forbiddenError := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "Deployment"}, deploymentName, errors.New("forbidden"))
 	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: deploymentName, Namespace: podNamespace}, emptyObject).Return(forbiddenError)
@@ -227,17 +221,13 @@ func (s *ServiceIdResolverTestSuite) TestDeploymentRead() {
 		},
 	}
 
-	deploymentAsObject := unstructured.Unstructured{}
+	deploymentAsObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
 	deploymentAsObject.SetName(deploymentName)
 	deploymentAsObject.SetNamespace(podNamespace)
-	deploymentAsObject.SetKind("Deployment")
-	deploymentAsObject.SetAPIVersion("apps/v1")
 
-	emptyObject := &unstructured.Unstructured{}
-	emptyObject.SetKind("Deployment")
-	emptyObject.SetAPIVersion("apps/v1")
+	emptyObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
 	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: deploymentName, Namespace: podNamespace}, emptyObject).Do(
-		func(_ context.Context, _ types.NamespacedName, obj *unstructured.Unstructured, _ ...any) error {
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...any) error {
 			deploymentAsObject.DeepCopyInto(obj)
 			return nil
 		})
@@ -245,6 +235,7 @@ func (s *ServiceIdResolverTestSuite) TestDeploymentRead() {
 	service, err := s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
 	s.Require().NoError(err)
 	s.Require().Equal(deploymentName, service.Name)
+	s.Require().Equal("Deployment", service.Kind)
 }
 
 func (s *ServiceIdResolverTestSuite) TestUserSpecifiedAnnotationForServiceName() {
@@ -256,6 +247,143 @@ func (s *ServiceIdResolverTestSuite) TestUserSpecifiedAnnotationForServiceName()
 	s.Require().Equal(serviceNameOverrideAnnotationKeyDefault, viper.GetString(serviceNameOverrideAnnotationKey))
 }
 
+func (s *ServiceIdResolverTestSuite) TestStrategiesResolveInConfiguredOrder() {
+	viper.Set(serviceIdentityStrategiesKey, "label,service-account")
+	defer viper.Set(serviceIdentityStrategiesKey, serviceIdentityStrategiesDefault)
+	s.Resolver = NewResolver(s.Client)
+
+	labeledPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cool-pod",
+			Namespace:   "cool-namespace",
+			Labels:      map[string]string{"app.kubernetes.io/name": "cool.service"},
+			Annotations: map[string]string{viper.GetString(serviceNameOverrideAnnotationKey): "annotated-service"},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "cool-service-account"},
+	}
+	service, err := s.Resolver.ResolvePodToServiceIdentity(context.Background(), &labeledPod)
+	s.Require().NoError(err)
+	s.Require().Equal("cool_service", service.Name)
+
+	labeledPod.Labels = nil
+	service, err = s.Resolver.ResolvePodToServiceIdentity(context.Background(), &labeledPod)
+	s.Require().NoError(err)
+	s.Require().Equal("cool-service-account", service.Name)
+
+	// The owner strategy ends the chain even when it isn't configured
+	labeledPod.Spec.ServiceAccountName = "default"
+	service, err = s.Resolver.ResolvePodToServiceIdentity(context.Background(), &labeledPod)
+	s.Require().NoError(err)
+	s.Require().Equal("cool-pod", service.Name)
+}
+
+func (s *ServiceIdResolverTestSuite) TestOwnerWalkStopsAtConfiguredKind() {
+	viper.Set(serviceIdentityOwnerKindsKey, "Deployment")
+	defer viper.Set(serviceIdentityOwnerKindsKey, []string{})
+	s.Resolver = NewResolver(s.Client)
+
+	deploymentName := "cool-deployment"
+	podNamespace := "cool-namespace"
+	myPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cool-pod-1234567890-12345",
+			Namespace:       podNamespace,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: deploymentName, APIVersion: "apps/v1"}},
+		},
+	}
+
+	// The deployment is owned by a Knative revision, which isn't resolved
+	emptyObject := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: deploymentName, Namespace: podNamespace}, emptyObject).Do(
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...any) error {
+			obj.SetName(deploymentName)
+			obj.SetNamespace(podNamespace)
+			obj.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Revision", Name: "cool-revision", APIVersion: "serving.knative.dev/v1"}})
+			return nil
+		})
+
+	service, err := s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+	s.Require().NoError(err)
+	s.Require().Equal(deploymentName, service.Name)
+	s.Require().Equal("Deployment", service.OwnerObject.GetObjectKind().GroupVersionKind().Kind)
+}
+
+func (s *ServiceIdResolverTestSuite) expectReplicaSetOfDeployment(replicaSetName string, deploymentName string, podNamespace string) {
+	emptyReplicaSet := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"})
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: replicaSetName, Namespace: podNamespace}, emptyReplicaSet).Do(
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...any) error {
+			obj.SetName(replicaSetName)
+			obj.SetNamespace(podNamespace)
+			obj.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: deploymentName, APIVersion: "apps/v1", UID: "deployment-uid"}})
+			return nil
+		})
+	emptyDeployment := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	s.Client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: deploymentName, Namespace: podNamespace}, emptyDeployment).Do(
+		func(_ context.Context, _ types.NamespacedName, obj *metav1.PartialObjectMetadata, _ ...any) error {
+			obj.SetName(deploymentName)
+			obj.SetNamespace(podNamespace)
+			return nil
+		})
+}
+
+func (s *ServiceIdResolverTestSuite) TestOwnerResolutionCachedByDirectOwner() {
+	enableOwnerCache(10)
+	defer func() { cachedOwners = nil }()
+
+	podNamespace := "cool-namespace"
+	ownerReferences := []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "cool-deployment-1234567890", APIVersion: "apps/v1", UID: "replicaset-uid"}}
+	s.expectReplicaSetOfDeployment("cool-deployment-1234567890", "cool-deployment", podNamespace)
+
+	// Pods of the same ReplicaSet are resolved once
+	for _, podName := range []string{"cool-deployment-1234567890-12345", "cool-deployment-1234567890-67890"} {
+		myPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: podNamespace, OwnerReferences: ownerReferences}}
+		service, err := s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+		s.Require().NoError(err)
+		s.Require().Equal("cool-deployment", service.Name)
+		s.Require().Equal("Deployment", service.Kind)
+	}
+}
+
+func (s *ServiceIdResolverTestSuite) TestOwnerCacheInvalidatedWhenOwnerChanges() {
+	enableOwnerCache(10)
+	defer func() { cachedOwners = nil }()
+
+	podNamespace := "cool-namespace"
+	myPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "cool-deployment-1234567890-12345",
+		Namespace:       podNamespace,
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "cool-deployment-1234567890", APIVersion: "apps/v1", UID: "replicaset-uid"}},
+	}}
+	s.expectReplicaSetOfDeployment("cool-deployment-1234567890", "cool-deployment", podNamespace)
+	_, err := s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+	s.Require().NoError(err)
+
+	// Updates that don't change the owner references keep the cache
+	handler := ownerCacheEventHandler()
+	replicaSet := newMetadataObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"})
+	replicaSet.SetUID("replicaset-uid")
+	replicaSet.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "cool-deployment", APIVersion: "apps/v1", UID: "deployment-uid"}})
+	updatedReplicaSet := replicaSet.DeepCopy()
+	updatedReplicaSet.SetLabels(map[string]string{"cake": "lie"})
+	handler.OnUpdate(replicaSet, updatedReplicaSet)
+	_, err = s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+	s.Require().NoError(err)
+
+	// The ReplicaSet is orphaned, so the pod is resolved again
+	orphanedReplicaSet := replicaSet.DeepCopy()
+	orphanedReplicaSet.SetOwnerReferences(nil)
+	handler.OnUpdate(replicaSet, orphanedReplicaSet)
+	s.expectReplicaSetOfDeployment("cool-deployment-1234567890", "cool-deployment", podNamespace)
+	_, err = s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+	s.Require().NoError(err)
+
+	// The ReplicaSet is deleted, so the pod is resolved again
+	handler.OnDelete(toolscache.DeletedFinalStateUnknown{Obj: replicaSet})
+	s.expectReplicaSetOfDeployment("cool-deployment-1234567890", "cool-deployment", podNamespace)
+	_, err = s.Resolver.ResolvePodToServiceIdentity(context.Background(), &myPod)
+	s.Require().NoError(err)
+}
+
 func TestServiceIdResolverTestSuite(t *testing.T) {
 	suite.Run(t, new(ServiceIdResolverTestSuite))
 }
@@ -0,0 +1,128 @@
+package serviceidresolver
+
+import (
+	"context"
+	"github.com/otterize/intents-operator/src/shared/serviceidresolver/serviceidentity"
+	"github.com/samber/lo"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"strings"
+)
+
+// StrategyName names a way of resolving a pod to its service identity.
+type StrategyName string
+
+const (
+	StrategyLabel          StrategyName = "label"
+	StrategyAnnotation     StrategyName = "annotation"
+	StrategyOwner          StrategyName = "owner"
+	StrategyServiceAccount StrategyName = "service-account"
+)
+
+// IdentityStrategy resolves a pod to its service identity. Strategies that don't apply to a pod return false, so the
+// next strategy in the chain is tried.
+type IdentityStrategy interface {
+	Resolve(ctx context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, bool, error)
+}
+
+type annotationStrategy struct{}
+
+func (annotationStrategy) Resolve(_ context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, bool, error) {
+	annotatedServiceName, ok := ResolvePodToServiceIdentityUsingAnnotationOnly(pod)
+	if !ok {
+		return serviceidentity.ServiceIdentity{}, false, nil
+	}
+	return serviceidentity.ServiceIdentity{Name: annotatedServiceName}, true, nil
+}
+
+type labelStrategy struct {
+	labelKey string
+}
+
+func (s labelStrategy) Resolve(_ context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, bool, error) {
+	value, ok := pod.Labels[s.labelKey]
+	if !ok || value == "" {
+		return serviceidentity.ServiceIdentity{}, false, nil
+	}
+	return serviceidentity.ServiceIdentity{Name: toOtterizeServiceName(value)}, true, nil
+}
+
+type serviceAccountStrategy struct{}
+
+func (serviceAccountStrategy) Resolve(_ context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, bool, error) {
+	// Every pod runs as the default service account unless configured otherwise, which says nothing about its service.
+	if pod.Spec.ServiceAccountName == "" || pod.Spec.ServiceAccountName == "default" {
+		return serviceidentity.ServiceIdentity{}, false, nil
+	}
+	return serviceidentity.ServiceIdentity{Name: toOtterizeServiceName(pod.Spec.ServiceAccountName)}, true, nil
+}
+
+// ownerStrategy walks up the owner references of the pod, and always resolves it.
+type ownerStrategy struct {
+	resolver *Resolver
+}
+
+func (s ownerStrategy) Resolve(ctx context.Context, pod *corev1.Pod) (serviceidentity.ServiceIdentity, bool, error) {
+	ownerObj, err := s.resolver.GetOwnerObject(ctx, pod)
+	if err != nil {
+		return serviceidentity.ServiceIdentity{}, false, err
+	}
+	kind := ownerObj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		// Typed objects don't carry their kind, and the only typed object the owner walk returns is the pod itself.
+		kind = "Pod"
+	}
+	return serviceidentity.ServiceIdentity{Name: toOtterizeServiceName(ownerObj.GetName()), OwnerObject: ownerObj, Kind: kind}, true, nil
+}
+
+// toOtterizeServiceName replaces dots, which Otterize uses as a separator between the service and the namespace.
+// Deployments and other resources with pod templates have a dot in their name since they follow RFC 1123 subdomain
+// naming convention. We replace the dot with an underscore, which isn't a valid character in a DNS name.
+// So, for example, a deployment named "my-deployment.5.2.0" will be seen by Otterize as "my-deployment_5_2_0"
+func toOtterizeServiceName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// newStrategiesFromConfig builds the strategy chain in the configured order. The owner strategy always ends the
+// chain, since every pod can be resolved by it.
+func newStrategiesFromConfig(r *Resolver) []IdentityStrategy {
+	strategies := make([]IdentityStrategy, 0)
+	names := configuredList(serviceIdentityStrategiesKey)
+	for _, name := range names {
+		switch StrategyName(name) {
+		case StrategyLabel:
+			strategies = append(strategies, labelStrategy{labelKey: viper.GetString(serviceIdentityLabelKey)})
+		case StrategyAnnotation:
+			strategies = append(strategies, annotationStrategy{})
+		case StrategyOwner:
+			strategies = append(strategies, ownerStrategy{resolver: r})
+		case StrategyServiceAccount:
+			strategies = append(strategies, serviceAccountStrategy{})
+		default:
+			logrus.WithField("strategy", name).Warning("unknown service identity strategy, ignoring it")
+		}
+	}
+	if !lo.Contains(names, string(StrategyOwner)) {
+		strategies = append(strategies, ownerStrategy{resolver: r})
+	}
+	return strategies
+}
+
+// configuredList reads a list that may be set as a comma separated environment variable.
+func configuredList(key string) []string {
+	values := make([]string, 0)
+	for _, value := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+func ownerKindsFromConfig() sets.Set[string] {
+	return sets.New(configuredList(serviceIdentityOwnerKindsKey)...)
+}